@@ -0,0 +1,81 @@
+package accounts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"gorm.io/gorm"
+)
+
+// DeletedMessageContent replaces the content of messages sent by an anonymized user
+const DeletedMessageContent = "[message deleted]"
+
+// DeletedUserName is the display name of anonymized users
+const DeletedUserName = "Deleted user"
+
+// Anonymize erases the personal data of a user while keeping the row, so that conversations stay consistent.
+// Messages and their edit history are blanked, attachments are detached, founder links and identities are removed, sessions are revoked and the profile is scrubbed
+func Anonymize(ctx context.Context, db *gorm.DB, userID uint64) error {
+	now := time.Now().UTC()
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Message{}).
+			Where("sender_id = ?", userID).
+			Update("content", DeletedMessageContent).Error; err != nil {
+			return fmt.Errorf("anonymize messages: %w", err)
+		}
+		if err := tx.Where("message_id IN (?)", tx.Model(&models.Message{}).Select("id").Where("sender_id = ?", userID)).
+			Delete(&models.MessageEdit{}).Error; err != nil {
+			return fmt.Errorf("delete message edits: %w", err)
+		}
+		// Detached uploads are removed, files included, by the attachment purge job
+		if err := tx.Model(&models.MessageAttachment{}).
+			Where("uploader_id = ?", userID).
+			Update("message_id", nil).Error; err != nil {
+			return fmt.Errorf("detach attachments: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.Founder{}).Error; err != nil {
+			return fmt.Errorf("delete founder links: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserIdentity{}).Error; err != nil {
+			return fmt.Errorf("delete identities: %w", err)
+		}
+		if err := RevokeSessions(tx, &models.User{ID: userID}); err != nil {
+			return err
+		}
+		err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"email":                 fmt.Sprintf("deleted-%d@deleted.invalid", userID),
+			"name":                  DeletedUserName,
+			"password_hash":         "",
+			"founder_id":            nil,
+			"investor_id":           nil,
+			"image_url":             nil,
+			"email_verified":        false,
+			"discoverable":          false,
+			"show_email":            false,
+			"deletion_scheduled_at": nil,
+			"anonymized_at":         now,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("anonymize user: %w", err)
+		}
+		return nil
+	})
+}
+
+// PurgeDue anonymizes every account whose deletion grace period ended before now and returns how many were processed
+func PurgeDue(ctx context.Context, db *gorm.DB, now time.Time) (int, error) {
+	var ids []uint64
+	if err := db.WithContext(ctx).Model(&models.User{}).
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ? AND anonymized_at IS NULL", now).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("list due deletions: %w", err)
+	}
+	for i, id := range ids {
+		if err := Anonymize(ctx, db, id); err != nil {
+			return i, fmt.Errorf("user %d: %w", id, err)
+		}
+	}
+	return len(ids), nil
+}
@@ -0,0 +1,24 @@
+package accounts
+
+import (
+	"fmt"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"gorm.io/gorm"
+)
+
+// RevokeSessions invalidates every refresh token and pending one-time token of u by bumping its token version.
+// u.TokenVersion is updated so that the caller can issue tokens of the new generation
+func RevokeSessions(tx *gorm.DB, u *models.User) error {
+	if err := tx.Model(&models.User{}).Where("id = ?", u.ID).
+		Update("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+		return fmt.Errorf("bump token version: %w", err)
+	}
+	if err := tx.Model(&models.User{}).Where("id = ?", u.ID).Pluck("token_version", &u.TokenVersion).Error; err != nil {
+		return fmt.Errorf("read token version: %w", err)
+	}
+	if err := tx.Where("user_id = ?", u.ID).Delete(&models.AuthToken{}).Error; err != nil {
+		return fmt.Errorf("delete one-time tokens: %w", err)
+	}
+	return nil
+}
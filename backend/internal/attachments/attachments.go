@@ -0,0 +1,190 @@
+// Package attachments validates and stores the files sent in conversations
+package attachments
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/storage/s3"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultMaxSize       = 10 << 20
+	DefaultMaxPerMessage = 5
+	DefaultURLTTL        = 5 * time.Minute
+	DefaultPurgeAfter    = 24 * time.Hour
+)
+
+// DefaultTypes are accepted when attachments.allowed_types is empty
+var DefaultTypes = []string{
+	"application/pdf",
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	ooxmlPresentation,
+	ooxmlDocument,
+	ooxmlSheet,
+}
+
+const (
+	ooxmlPresentation = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+	ooxmlDocument     = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	ooxmlSheet        = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// ErrUnsupportedType is returned for files whose type is not allowed or does not match their content
+var ErrUnsupportedType = errors.New("unsupported file type")
+
+// Policy holds the attachment limits, defaults applied
+type Policy struct {
+	MaxSize       int64
+	Types         []string
+	MaxPerMessage int
+	URLTTL        time.Duration
+	PurgeAfter    time.Duration
+}
+
+func NewPolicy(cfg config.AttachmentsConfig) Policy {
+	p := Policy{
+		MaxSize:       cfg.MaxSize,
+		Types:         cfg.AllowedTypes,
+		MaxPerMessage: cfg.MaxPerMessage,
+		URLTTL:        cfg.URLTTL,
+		PurgeAfter:    cfg.PurgeAfter,
+	}
+	if p.MaxSize <= 0 {
+		p.MaxSize = DefaultMaxSize
+	}
+	if len(p.Types) == 0 {
+		p.Types = DefaultTypes
+	}
+	if p.MaxPerMessage <= 0 {
+		p.MaxPerMessage = DefaultMaxPerMessage
+	}
+	if p.URLTTL <= 0 {
+		p.URLTTL = DefaultURLTTL
+	}
+	if p.PurgeAfter <= 0 {
+		p.PurgeAfter = DefaultPurgeAfter
+	}
+	return p
+}
+
+// ContentType returns the type to store data under. The declared type must be allowed and agree with the sniffed content,
+// so that an HTML page cannot be uploaded as an image
+func (p Policy) ContentType(declared string, data []byte) (string, error) {
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if declared == "" || declared == "application/octet-stream" {
+		declared = sniffed
+	}
+	declared, _, err := mime.ParseMediaType(declared)
+	if err != nil || !slices.Contains(p.Types, declared) {
+		return "", ErrUnsupportedType
+	}
+	switch {
+	case declared == ooxmlPresentation || declared == ooxmlDocument || declared == ooxmlSheet:
+		// Office documents are zip archives
+		if sniffed != "application/zip" {
+			return "", ErrUnsupportedType
+		}
+	case declared == "application/pdf" || strings.HasPrefix(declared, "image/"):
+		if sniffed != declared {
+			return "", ErrUnsupportedType
+		}
+	}
+	return declared, nil
+}
+
+// Key returns a fresh object key under the prefix of the conversation
+func Key(conversationID uint64, fileName string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate key: %w", err)
+	}
+	return fmt.Sprintf("conversations/%d/attachments/%s%s", conversationID, hex.EncodeToString(b), extension(fileName)), nil
+}
+
+// FileName cleans a client-supplied file name for storage and Content-Disposition headers
+func FileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[len(runes)-255:])
+	}
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	return name
+}
+
+// Disposition is the Content-Disposition of a download: images open inline, other files are saved
+func Disposition(a models.MessageAttachment) string {
+	kind := "attachment"
+	if strings.HasPrefix(a.ContentType, "image/") {
+		kind = "inline"
+	}
+	return mime.FormatMediaType(kind, map[string]string{"filename": a.FileName})
+}
+
+func extension(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if len(ext) < 2 || len(ext) > 10 {
+		return ""
+	}
+	for _, r := range ext[1:] {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return ""
+		}
+	}
+	return ext
+}
+
+// PurgeOrphans removes the uploads that were not sent with a message before cutoff, along with their files.
+// It returns how many were removed
+func PurgeOrphans(ctx context.Context, db *gorm.DB, store s3.ObjectStore, cutoff time.Time) (int, error) {
+	var orphans []models.MessageAttachment
+	if err := db.WithContext(ctx).
+		Where("message_id IS NULL AND created_at < ?", cutoff).
+		Order("id ASC").
+		Limit(500).
+		Find(&orphans).Error; err != nil {
+		return 0, fmt.Errorf("find orphan attachments: %w", err)
+	}
+
+	removed := 0
+	for _, a := range orphans {
+		// Drop the row first so that the upload cannot be sent while its file is being deleted
+		res := db.WithContext(ctx).Where("id = ? AND message_id IS NULL", a.ID).Delete(&models.MessageAttachment{})
+		if res.Error != nil {
+			return removed, fmt.Errorf("delete attachment %d: %w", a.ID, res.Error)
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		if err := store.Delete(ctx, a.StorageKey); err != nil {
+			return removed, fmt.Errorf("delete file of attachment %d: %w", a.ID, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,93 @@
+package attachments_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/attachments"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestPolicy_ContentType(t *testing.T) {
+	p := attachments.NewPolicy(config.AttachmentsConfig{})
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	zip := []byte("PK\x03\x04\x14\x00\x06\x00")
+
+	for _, tc := range []struct {
+		declared string
+		data     []byte
+		want     string
+	}{
+		{"image/png", png, "image/png"},
+		{"", png, "image/png"},
+		{"application/pdf", []byte("%PDF-1.7"), "application/pdf"},
+		{"application/vnd.openxmlformats-officedocument.presentationml.presentation", zip, "application/vnd.openxmlformats-officedocument.presentationml.presentation"},
+		{"image/jpeg", png, ""},
+		{"image/png", []byte("<!DOCTYPE html><p>hi</p>"), ""},
+		{"text/html", []byte("<!DOCTYPE html><p>hi</p>"), ""},
+		{"application/zip", zip, ""},
+	} {
+		got, err := p.ContentType(tc.declared, tc.data)
+		if tc.want == "" {
+			assert.ErrorIs(t, err, attachments.ErrUnsupportedType, tc.declared)
+			continue
+		}
+		require.NoError(t, err, tc.declared)
+		assert.Equal(t, tc.want, got)
+	}
+}
+
+func TestFileNameAndKey(t *testing.T) {
+	assert.Equal(t, "deck.pdf", attachments.FileName("C:\\Users\\me\\deck.pdf"))
+	assert.Equal(t, "passwd", attachments.FileName("../../etc/passwd"))
+	assert.Equal(t, "attachment", attachments.FileName(" \x00 "))
+
+	key, err := attachments.Key(7, "Deck.PDF")
+	require.NoError(t, err)
+	assert.Regexp(t, `^conversations/7/attachments/[0-9a-f]{32}\.pdf$`, key)
+	key, err = attachments.Key(7, "notes.<script>")
+	require.NoError(t, err)
+	assert.Regexp(t, `^conversations/7/attachments/[0-9a-f]{32}$`, key)
+}
+
+type deletedKeys []string
+
+func (d *deletedKeys) Upload(context.Context, string, string, []byte) (string, error) { return "", nil }
+func (d *deletedKeys) PresignGet(context.Context, string, time.Duration, string) (string, error) {
+	return "", nil
+}
+func (d *deletedKeys) Delete(_ context.Context, key string) error {
+	*d = append(*d, key)
+	return nil
+}
+
+func TestPurgeOrphans(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.MessageAttachment{}))
+
+	now := time.Now().UTC()
+	sent := uint64(9)
+	for _, a := range []models.MessageAttachment{
+		{ConversationID: 1, UploaderID: 1, StorageKey: "old", FileName: "a", ContentType: "image/png", Size: 1, CreatedAt: now.Add(-48 * time.Hour)},
+		{ConversationID: 1, UploaderID: 1, StorageKey: "recent", FileName: "b", ContentType: "image/png", Size: 1, CreatedAt: now.Add(-time.Hour)},
+		{ConversationID: 1, UploaderID: 1, StorageKey: "sent", FileName: "c", ContentType: "image/png", Size: 1, CreatedAt: now.Add(-48 * time.Hour), MessageID: &sent},
+	} {
+		require.NoError(t, db.Create(&a).Error)
+	}
+
+	var store deletedKeys
+	n, err := attachments.PurgeOrphans(context.Background(), db, &store, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, deletedKeys{"old"}, store)
+	var left int64
+	db.Model(&models.MessageAttachment{}).Count(&left)
+	assert.Equal(t, int64(2), left)
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"gorm.io/gorm"
+)
+
+// redactedFields are never written to the audit trail in clear
+var redactedFields = map[string]struct{}{
+	"password_hash": {},
+	"token_hash":    {},
+}
+
+const redacted = "[redacted]"
+
+// Snapshot loads the current state of a target as a flat field map. It returns nil when the target does not exist
+type Snapshot func(ctx context.Context, db *gorm.DB, id string) (map[string]interface{}, error)
+
+// TableSnapshot returns a Snapshot reading the row with the given id from table
+func TableSnapshot(table string) Snapshot {
+	return func(ctx context.Context, db *gorm.DB, id string) (map[string]interface{}, error) {
+		row := map[string]interface{}{}
+		err := db.WithContext(ctx).Table(table).Where("id = ?", id).Take(&row).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range row {
+			if b, ok := v.([]byte); ok {
+				row[k] = string(b)
+			}
+		}
+		return row, nil
+	}
+}
+
+// RoleSnapshot extends the roles row with the sorted names of its granted permissions
+func RoleSnapshot(ctx context.Context, db *gorm.DB, id string) (map[string]interface{}, error) {
+	row, err := TableSnapshot("roles")(ctx, db, id)
+	if err != nil || row == nil {
+		return row, err
+	}
+	var perms []string
+	err = db.WithContext(ctx).
+		Table("role_permissions").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("role_permissions.role_id = ?", id).
+		Pluck("permissions.name", &perms).Error
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(perms)
+	row["permissions"] = perms
+	return row, nil
+}
+
+// Diff returns the fields whose value differs between before and after.
+// A nil before (creation) or after (deletion) reports every field of the other side
+func Diff(before, after map[string]interface{}) map[string]models.AuditChange {
+	changes := map[string]models.AuditChange{}
+	keys := map[string]struct{}{}
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+	for k := range keys {
+		b, a := before[k], after[k]
+		if sameValue(b, a) {
+			continue
+		}
+		if _, ok := redactedFields[k]; ok {
+			if b != nil {
+				b = redacted
+			}
+			if a != nil {
+				a = redacted
+			}
+		}
+		changes[k] = models.AuditChange{Before: b, After: a}
+	}
+	return changes
+}
+
+// sameValue compares values through their JSON encoding, which ignores driver-specific Go types
+func sameValue(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+// Record appends an event to the audit trail
+func Record(ctx context.Context, db *gorm.DB, ev *models.AuditEvent) error {
+	return db.WithContext(ctx).Create(ev).Error
+}
@@ -18,19 +18,32 @@ type Claims struct {
 	Email  string `json:"email"`
 	Role   string `json:"role"`
 	Type   string `json:"typ"`
+	// Set on impersonation tokens: the admin acting as UserID
+	ImpersonatorID    uint64 `json:"imp,omitempty"`
+	ImpersonatorEmail string `json:"imp_email,omitempty"`
+	// Session generation of the user when the token was issued; refresh tokens of older generations are rejected
+	TokenVersion uint64 `json:"tv,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateTokenPair(cfg *config.Config, userID uint64, email, role string) (*TokenPair, error) {
+// Impersonating reports whether the token was issued to an admin acting as another user
+func (c *Claims) Impersonating() bool {
+	return c != nil && c.ImpersonatorID != 0
+}
+
+// GenerateTokenPair issues the access and refresh tokens of a session. tokenVersion is the user's current
+// models.User.TokenVersion, bumped to revoke every refresh token issued before
+func GenerateTokenPair(cfg *config.Config, userID uint64, email, role string, tokenVersion uint64) (*TokenPair, error) {
 	now := time.Now()
 	atExp := now.Add(cfg.Auth.JWT.AccessTokenTTL)
 	rtExp := now.Add(cfg.Auth.JWT.RefreshTokenTTL)
 
 	access := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
-		Type:   "access",
+		UserID:       userID,
+		Email:        email,
+		Role:         role,
+		Type:         "access",
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(atExp),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -42,10 +55,11 @@ func GenerateTokenPair(cfg *config.Config, userID uint64, email, role string) (*
 	}
 
 	refresh := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
-		Type:   "refresh",
+		UserID:       userID,
+		Email:        email,
+		Role:         role,
+		Type:         "refresh",
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(rtExp),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -63,6 +77,30 @@ func GenerateTokenPair(cfg *config.Config, userID uint64, email, role string) (*
 	}, nil
 }
 
+// GenerateImpersonationToken issues a short-lived access token for userID that also carries the impersonating admin.
+// No refresh token is issued, so the session cannot outlive ttl
+func GenerateImpersonationToken(cfg *config.Config, userID uint64, email, role string, impersonatorID uint64, impersonatorEmail string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	exp := now.Add(ttl)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		UserID:            userID,
+		Email:             email,
+		Role:              role,
+		Type:              "access",
+		ImpersonatorID:    impersonatorID,
+		ImpersonatorEmail: impersonatorEmail,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(exp),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+	str, err := token.SignedString([]byte(cfg.Auth.JWT.Secret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return str, exp, nil
+}
+
 func ParseClaims(cfg *config.Config, tokenStr string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (interface{}, error) {
 		return []byte(cfg.Auth.JWT.Secret), nil
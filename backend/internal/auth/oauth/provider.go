@@ -0,0 +1,353 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+)
+
+const (
+	KindGitHub = "github"
+	KindGoogle = "google"
+	KindOIDC   = "oidc"
+)
+
+// Identity is the normalized profile returned by a provider after a successful login
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// Token holds the relevant parts of a token endpoint response
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+}
+
+type endpoints struct {
+	AuthURL     string `json:"authorization_endpoint"`
+	TokenURL    string `json:"token_endpoint"`
+	UserInfoURL string `json:"userinfo_endpoint"`
+}
+
+// Provider performs the authorization-code + PKCE flow against a single OAuth2/OIDC provider
+type Provider struct {
+	Name   string
+	Kind   string
+	cfg    config.OAuthProviderConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	eps      endpoints
+	resolved bool
+	emailsU  string
+}
+
+// NewProvider builds a provider from configuration. Endpoints are resolved lazily (OIDC discovery)
+func NewProvider(name string, cfg config.OAuthProviderConfig, client *http.Client) (*Provider, error) {
+	kind := strings.ToLower(strings.TrimSpace(cfg.Type))
+	if kind == "" {
+		kind = strings.ToLower(name)
+	}
+	switch kind {
+	case KindGitHub, KindGoogle, KindOIDC:
+	default:
+		return nil, fmt.Errorf("unsupported oauth provider type %q", cfg.Type)
+	}
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("oauth provider %s: missing client_id", name)
+	}
+	if kind == KindOIDC && cfg.Issuer == "" && (cfg.AuthURL == "" || cfg.TokenURL == "" || cfg.UserInfoURL == "") {
+		return nil, fmt.Errorf("oauth provider %s: issuer or explicit endpoints required", name)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Provider{Name: name, Kind: kind, cfg: cfg, client: client}, nil
+}
+
+// RedirectURL returns the configured redirect URL, or the given fallback when unset
+func (p *Provider) RedirectURL(fallback string) string {
+	if p.cfg.RedirectURL != "" {
+		return p.cfg.RedirectURL
+	}
+	return fallback
+}
+
+// AuthCodeURL builds the provider authorization URL for the given state and PKCE challenge
+func (p *Provider) AuthCodeURL(ctx context.Context, state, challenge, redirectURL string) (string, error) {
+	eps, err := p.endpoints(ctx)
+	if err != nil {
+		return "", err
+	}
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", redirectURL)
+	q.Set("scope", strings.Join(p.scopes(), " "))
+	q.Set("state", state)
+	q.Set("code_challenge", challenge)
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(eps.AuthURL, "?") {
+		sep = "&"
+	}
+	return eps.AuthURL + sep + q.Encode(), nil
+}
+
+// Exchange trades an authorization code and PKCE verifier for tokens
+func (p *Provider) Exchange(ctx context.Context, code, verifier, redirectURL string) (*Token, error) {
+	eps, err := p.endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("client_secret", p.cfg.ClientSecret)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, eps.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tok Token
+	if err := p.doJSON(req, &tok); err != nil {
+		return nil, fmt.Errorf("token exchange: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return nil, fmt.Errorf("token exchange: empty access_token")
+	}
+	return &tok, nil
+}
+
+// UserInfo fetches the normalized identity using the access token
+func (p *Provider) UserInfo(ctx context.Context, tok *Token) (*Identity, error) {
+	eps, err := p.endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if p.Kind == KindGitHub {
+		return p.githubUserInfo(ctx, eps.UserInfoURL, tok)
+	}
+
+	req, err := p.authorizedRequest(ctx, eps.UserInfoURL, tok)
+	if err != nil {
+		return nil, err
+	}
+	var raw struct {
+		Sub           string          `json:"sub"`
+		Email         string          `json:"email"`
+		EmailVerified json.RawMessage `json:"email_verified"`
+		Name          string          `json:"name"`
+		Picture       string          `json:"picture"`
+	}
+	if err := p.doJSON(req, &raw); err != nil {
+		return nil, fmt.Errorf("userinfo: %w", err)
+	}
+	if raw.Sub == "" {
+		return nil, fmt.Errorf("userinfo: missing sub")
+	}
+	return &Identity{
+		Subject:       raw.Sub,
+		Email:         strings.TrimSpace(raw.Email),
+		EmailVerified: parseBool(raw.EmailVerified),
+		Name:          raw.Name,
+		Picture:       raw.Picture,
+	}, nil
+}
+
+// githubUserInfo reads the GitHub profile and its primary verified email (GitHub is OAuth2-only, no OIDC userinfo)
+func (p *Provider) githubUserInfo(ctx context.Context, userURL string, tok *Token) (*Identity, error) {
+	req, err := p.authorizedRequest(ctx, userURL, tok)
+	if err != nil {
+		return nil, err
+	}
+	var u struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		Email     string `json:"email"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := p.doJSON(req, &u); err != nil {
+		return nil, fmt.Errorf("github user: %w", err)
+	}
+	if u.ID == 0 {
+		return nil, fmt.Errorf("github user: missing id")
+	}
+	id := &Identity{
+		Subject: strconv.FormatInt(u.ID, 10),
+		Email:   u.Email,
+		Name:    u.Name,
+		Picture: u.AvatarURL,
+	}
+	if id.Name == "" {
+		id.Name = u.Login
+	}
+
+	req, err = p.authorizedRequest(ctx, p.emailsU, tok)
+	if err != nil {
+		return nil, err
+	}
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.doJSON(req, &emails); err != nil {
+		// Email scope may have been denied; keep the public profile email as unverified
+		return id, nil
+	}
+	for _, e := range emails {
+		if e.Primary {
+			id.Email = e.Email
+			id.EmailVerified = e.Verified
+			break
+		}
+	}
+	return id, nil
+}
+
+func (p *Provider) scopes() []string {
+	if len(p.cfg.Scopes) > 0 {
+		return p.cfg.Scopes
+	}
+	if p.Kind == KindGitHub {
+		return []string{"read:user", "user:email"}
+	}
+	return []string{"openid", "email", "profile"}
+}
+
+// endpoints resolves provider endpoints once, using well-known defaults or OIDC discovery.
+// A failed discovery is not cached so that a later request can retry
+func (p *Provider) endpoints(ctx context.Context) (endpoints, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.resolved {
+		return p.eps, nil
+	}
+
+	eps := endpoints{AuthURL: p.cfg.AuthURL, TokenURL: p.cfg.TokenURL, UserInfoURL: p.cfg.UserInfoURL}
+	issuer := p.cfg.Issuer
+	switch p.Kind {
+	case KindGitHub:
+		eps = withDefaults(eps, endpoints{
+			AuthURL:     "https://github.com/login/oauth/authorize",
+			TokenURL:    "https://github.com/login/oauth/access_token",
+			UserInfoURL: "https://api.github.com/user",
+		})
+		p.emailsU = strings.TrimSuffix(eps.UserInfoURL, "/user") + "/user/emails"
+	case KindGoogle:
+		if issuer == "" {
+			issuer = "https://accounts.google.com"
+		}
+	}
+
+	if eps.AuthURL == "" || eps.TokenURL == "" || eps.UserInfoURL == "" {
+		disc, err := p.discover(ctx, issuer)
+		if err != nil {
+			return endpoints{}, err
+		}
+		eps = withDefaults(eps, disc)
+	}
+
+	p.eps = eps
+	p.resolved = true
+	return p.eps, nil
+}
+
+// discover fetches the OpenID Provider configuration document for the issuer
+func (p *Provider) discover(ctx context.Context, issuer string) (endpoints, error) {
+	u := strings.TrimRight(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return endpoints{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	var eps endpoints
+	if err := p.doJSON(req, &eps); err != nil {
+		return endpoints{}, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if eps.AuthURL == "" || eps.TokenURL == "" || eps.UserInfoURL == "" {
+		return endpoints{}, fmt.Errorf("oidc discovery: incomplete provider metadata")
+	}
+	return eps, nil
+}
+
+func (p *Provider) authorizedRequest(ctx context.Context, u string, tok *Token) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+tok.AccessToken)
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+func (p *Provider) doJSON(req *http.Request, out interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+func withDefaults(eps, def endpoints) endpoints {
+	if eps.AuthURL == "" {
+		eps.AuthURL = def.AuthURL
+	}
+	if eps.TokenURL == "" {
+		eps.TokenURL = def.TokenURL
+	}
+	if eps.UserInfoURL == "" {
+		eps.UserInfoURL = def.UserInfoURL
+	}
+	return eps
+}
+
+// parseBool accepts both JSON booleans and the "true"/"false" strings some providers return
+func parseBool(raw json.RawMessage) bool {
+	if len(raw) == 0 {
+		return false
+	}
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strings.EqualFold(s, "true")
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Registry holds the configured and enabled providers, keyed by name
+type Registry struct {
+	providers map[string]*Provider
+}
+
+// NewRegistry builds providers from configuration. Providers without a client_id are skipped
+func NewRegistry(cfg config.OAuthConfig, client *http.Client, log *logrus.Logger) *Registry {
+	r := &Registry{providers: map[string]*Provider{}}
+	for name, pc := range cfg.Providers {
+		if pc.ClientID == "" {
+			continue
+		}
+		p, err := NewProvider(name, pc, client)
+		if err != nil {
+			if log != nil {
+				log.WithError(err).WithField("provider", name).Warn("oauth provider disabled")
+			}
+			continue
+		}
+		r.providers[name] = p
+	}
+	return r
+}
+
+// Get returns the provider registered under name
+func (r *Registry) Get(name string) (*Provider, bool) {
+	if r == nil {
+		return nil, false
+	}
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Names returns the sorted list of enabled provider names
+func (r *Registry) Names() []string {
+	names := make([]string, 0)
+	if r == nil {
+		return names
+	}
+	for n := range r.providers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewPKCE returns a random code verifier and its S256 challenge
+func NewPKCE() (verifier, challenge string, err error) {
+	verifier, err = RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// RandomString returns n random bytes encoded as unpadded base64url
+func RandomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// StateClaims is the signed payload kept in the state cookie between the redirect and the callback
+type StateClaims struct {
+	State      string `json:"st"`
+	Verifier   string `json:"cv"`
+	Provider   string `json:"prv"`
+	LinkUserID uint64 `json:"link_uid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// SignState signs the state payload with the application secret
+func SignState(secret string, ttl time.Duration, st StateClaims) (string, error) {
+	now := time.Now()
+	st.RegisteredClaims = jwt.RegisteredClaims{
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, &st).SignedString([]byte(secret))
+}
+
+// ParseState verifies and decodes a signed state payload
+func ParseState(secret, raw string) (*StateClaims, error) {
+	var st StateClaims
+	token, err := jwt.ParseWithClaims(raw, &st, func(t *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return &st, nil
+}
@@ -0,0 +1,71 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// prefixLength is the length of the SHA-1 prefix used to bucket hashes, as in the Pwned Passwords range API
+const prefixLength = 5
+
+// BreachedList is an offline set of breached password hashes. Hashes are bucketed by their 5 hex digit SHA-1 prefix so
+// that a lookup only compares suffixes within one range, the way the k-anonymity range API is queried
+type BreachedList struct {
+	ranges map[string]map[string]struct{}
+}
+
+// LoadBreachedList reads a dataset of upper or lower case SHA-1 hex digests, one per line, optionally followed by
+// ":count" as in Pwned Passwords exports. Blank lines and lines starting with '#' are ignored
+func LoadBreachedList(path string) (*BreachedList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.Open: %w", err)
+	}
+	defer f.Close()
+
+	l := &BreachedList{ranges: map[string]map[string]struct{}{}}
+	sc := bufio.NewScanner(f)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		digest, _, _ := strings.Cut(text, ":")
+		digest = strings.ToUpper(digest)
+		if len(digest) != sha1.Size*2 {
+			return nil, fmt.Errorf("line %d: expected a SHA-1 hex digest", line)
+		}
+		if _, err := hex.DecodeString(digest); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		l.add(digest)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return l, nil
+}
+
+func (l *BreachedList) add(digest string) {
+	prefix, suffix := digest[:prefixLength], digest[prefixLength:]
+	r, ok := l.ranges[prefix]
+	if !ok {
+		r = map[string]struct{}{}
+		l.ranges[prefix] = r
+	}
+	r[suffix] = struct{}{}
+}
+
+// Contains reports whether password appears in the list
+func (l *BreachedList) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	_, ok := l.ranges[digest[:prefixLength]][digest[prefixLength:]]
+	return ok
+}
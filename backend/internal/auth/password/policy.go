@@ -0,0 +1,165 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Policy defaults, used when the matching auth.password setting is zero
+const (
+	DefaultMinLength = 8
+	// MaxLength is the longest password bcrypt hashes without truncating
+	MaxLength = 72
+	// MaxBcryptCost bounds the configurable cost so that a typo cannot make every login take seconds
+	MaxBcryptCost = 14
+)
+
+// Violation codes returned by Validate
+const (
+	ViolationTooShort      = "too_short"
+	ViolationTooLong       = "too_long"
+	ViolationCharClasses   = "too_few_character_classes"
+	ViolationPersonalInfo  = "contains_personal_info"
+	ViolationBreached      = "breached"
+	minPersonalTokenLength = 4
+)
+
+// Policy validates and hashes passwords according to auth.password
+type Policy struct {
+	minLength  int
+	maxLength  int
+	minClasses int
+	cost       int
+	breached   *BreachedList
+}
+
+// NewPolicy builds a policy from configuration. An unreadable breached-password list is logged and the check is skipped,
+// and out of range settings are clamped
+func NewPolicy(cfg config.PasswordConfig, log *logrus.Logger) *Policy {
+	p := &Policy{
+		minLength:  cfg.MinLength,
+		maxLength:  cfg.MaxLength,
+		minClasses: cfg.MinCharacterClasses,
+		cost:       cfg.BcryptCost,
+	}
+	if p.minLength <= 0 {
+		p.minLength = DefaultMinLength
+	}
+	if p.maxLength <= 0 || p.maxLength > MaxLength {
+		p.maxLength = MaxLength
+	}
+	if p.minClasses > 4 {
+		p.minClasses = 4
+	}
+	switch {
+	case p.cost == 0:
+		p.cost = bcrypt.DefaultCost
+	case p.cost < bcrypt.MinCost:
+		p.cost = bcrypt.MinCost
+	case p.cost > MaxBcryptCost:
+		if log != nil {
+			log.WithField("bcrypt_cost", p.cost).Warnf("auth.password.bcrypt_cost capped to %d", MaxBcryptCost)
+		}
+		p.cost = MaxBcryptCost
+	}
+	if cfg.BreachedList != "" {
+		list, err := LoadBreachedList(cfg.BreachedList)
+		if err != nil {
+			if log != nil {
+				log.WithError(err).WithField("path", cfg.BreachedList).Error("breached password list disabled")
+			}
+		} else {
+			p.breached = list
+		}
+	}
+	return p
+}
+
+// Validate returns the rules the password breaks, or nil when it is acceptable. email and name are the account's own,
+// which the password must not contain
+func (p *Policy) Validate(password, email, name string) []string {
+	var violations []string
+	n := len([]rune(password))
+	if n < p.minLength {
+		violations = append(violations, ViolationTooShort)
+	}
+	if len(password) > p.maxLength {
+		violations = append(violations, ViolationTooLong)
+	}
+	if characterClasses(password) < p.minClasses {
+		violations = append(violations, ViolationCharClasses)
+	}
+	if containsPersonalInfo(password, email, name) {
+		violations = append(violations, ViolationPersonalInfo)
+	}
+	if p.breached != nil && p.breached.Contains(password) {
+		violations = append(violations, ViolationBreached)
+	}
+	return violations
+}
+
+// Hash returns the bcrypt hash of password at the configured cost
+func (p *Policy) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), p.cost)
+	if err != nil {
+		return "", fmt.Errorf("bcrypt.GenerateFromPassword: %w", err)
+	}
+	return string(hash), nil
+}
+
+// Requirements describes the policy for clients rendering a password form
+func (p *Policy) Requirements() map[string]interface{} {
+	return map[string]interface{}{
+		"min_length":            p.minLength,
+		"max_length":            p.maxLength,
+		"min_character_classes": p.minClasses,
+		"breached_check":        p.breached != nil,
+	}
+}
+
+func characterClasses(s string) int {
+	var lower, upper, digit, other bool
+	for _, r := range s {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	n := 0
+	for _, b := range []bool{lower, upper, digit, other} {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
+// containsPersonalInfo reports whether password contains the email, its local part, or the name (or one of its words)
+func containsPersonalInfo(password, email, name string) bool {
+	pw := strings.ToLower(password)
+	email = strings.ToLower(strings.TrimSpace(email))
+	tokens := []string{email}
+	if local, _, ok := strings.Cut(email, "@"); ok {
+		tokens = append(tokens, local)
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	tokens = append(tokens, strings.ReplaceAll(name, " ", ""))
+	tokens = append(tokens, strings.Fields(name)...)
+	for _, t := range tokens {
+		if len([]rune(t)) >= minPersonalTokenLength && strings.Contains(pw, t) {
+			return true
+		}
+	}
+	return false
+}
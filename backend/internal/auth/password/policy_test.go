@@ -0,0 +1,97 @@
+package password_test
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/password"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// writeBreachedList writes passwords as a Pwned Passwords style export and returns its path
+func writeBreachedList(t *testing.T, passwords ...string) string {
+	t.Helper()
+	lines := []string{"# breached passwords", ""}
+	for i, pw := range passwords {
+		digest := fmt.Sprintf("%X", sha1.Sum([]byte(pw)))
+		if i%2 == 1 {
+			digest = strings.ToLower(digest) + ":42"
+		}
+		lines = append(lines, digest)
+	}
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600))
+	return path
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	p := password.NewPolicy(config.PasswordConfig{
+		MinLength:           10,
+		MaxLength:           20,
+		MinCharacterClasses: 3,
+		BreachedList:        writeBreachedList(t, "Correct-Horse1", "Tr0ub4dor&3xyz"),
+	}, nil)
+
+	for _, tc := range []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{"acceptable", "Blue-Pencil42", nil},
+		{"too short", "Ab1-xyz", []string{password.ViolationTooShort}},
+		{"length counts characters", "Éléphant-1é", nil},
+		{"too long", "Blue-Pencil42-Blue-Pen", []string{password.ViolationTooLong}},
+		{"too few classes", "bluepencilcase", []string{password.ViolationCharClasses}},
+		{"symbols count as a class", "blue pencil 42", nil},
+		{"email local part", "Jdupont-2024!", []string{password.ViolationPersonalInfo}},
+		{"whole name", "JeanDupont-24", []string{password.ViolationPersonalInfo}},
+		{"word of the name", "x-Dupont-99x", []string{password.ViolationPersonalInfo}},
+		{"short words are ignored", "Jea-Pencil-42", nil},
+		{"breached", "Correct-Horse1", []string{password.ViolationBreached}},
+		{"breached, lower case digest", "Tr0ub4dor&3xyz", []string{password.ViolationBreached}},
+		{"several rules", "jdupont", []string{password.ViolationTooShort, password.ViolationCharClasses, password.ViolationPersonalInfo}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, p.Validate(tc.password, "JDupont@example.com", "Jean Dupont"))
+		})
+	}
+}
+
+func TestNewPolicy_Defaults(t *testing.T) {
+	p := password.NewPolicy(config.PasswordConfig{MaxLength: 500, MinCharacterClasses: 9, BreachedList: filepath.Join(t.TempDir(), "missing.txt")}, nil)
+	assert.Equal(t, map[string]interface{}{
+		"min_length":            password.DefaultMinLength,
+		"max_length":            password.MaxLength,
+		"min_character_classes": 4,
+		"breached_check":        false,
+	}, p.Requirements())
+	assert.Equal(t, []string{password.ViolationTooShort, password.ViolationCharClasses}, p.Validate("ab1!", "", ""))
+
+	hash, err := password.NewPolicy(config.PasswordConfig{BcryptCost: 1}, nil).Hash("Blue-Pencil42")
+	require.NoError(t, err)
+	cost, err := bcrypt.Cost([]byte(hash))
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.MinCost, cost)
+}
+
+func TestLoadBreachedList(t *testing.T) {
+	list, err := password.LoadBreachedList(writeBreachedList(t, "hunter22", "letmein"))
+	require.NoError(t, err)
+	assert.True(t, list.Contains("hunter22"))
+	assert.True(t, list.Contains("letmein"))
+	assert.False(t, list.Contains("Hunter22"))
+
+	for _, content := range []string{"not-a-digest", strings.Repeat("Z", 40)} {
+		path := filepath.Join(t.TempDir(), "invalid.txt")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		_, err := password.LoadBreachedList(path)
+		assert.Error(t, err, content)
+	}
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Named permissions checked by RequirePermission. Roles are granted permissions through role_permissions;
+// "*" grants everything and "<resource>.*" grants every action on a resource
+const (
+	PermAll = "*"
+
+	PermStartupsCreate = "startups.create"
+	PermStartupsUpdate = "startups.update"
+	PermStartupsDelete = "startups.delete"
+
+	PermInvestorsCreate = "investors.create"
+	PermInvestorsUpdate = "investors.update"
+	PermInvestorsDelete = "investors.delete"
+
+	PermPartnersCreate = "partners.create"
+	PermPartnersUpdate = "partners.update"
+	PermPartnersDelete = "partners.delete"
+
+	PermNewsCreate = "news.create"
+	PermNewsUpdate = "news.update"
+	PermNewsDelete = "news.delete"
+
+	PermEventsCreate = "events.create"
+	PermEventsUpdate = "events.update"
+	PermEventsDelete = "events.delete"
+
+	PermOpportunitiesRead   = "opportunities.read"
+	PermOpportunitiesCreate = "opportunities.create"
+	PermOpportunitiesUpdate = "opportunities.update"
+	PermOpportunitiesDelete = "opportunities.delete"
+
+	PermUsersRead   = "users.read"
+	PermUsersCreate = "users.create"
+	PermUsersUpdate = "users.update"
+	PermUsersDelete = "users.delete"
+
+	PermUsersImpersonate = "users.impersonate"
+	PermUsersInvite      = "users.invite"
+
+	PermStatisticsRead = "statistics.read"
+
+	PermSyncRead    = "sync.read"
+	PermSyncTrigger = "sync.trigger"
+
+	PermRolesManage = "roles.manage"
+
+	PermAuditRead = "audit.read"
+
+	PermMessagesModerate = "messages.moderate"
+
+	PermFeaturesManage = "features.manage"
+)
+
+// PermissionInfo describes a permission of the catalog
+type PermissionInfo struct {
+	Name        string `json:"name" example:"startups.update"`
+	Description string `json:"description" example:"Update any startup"`
+}
+
+// Catalog lists every permission known to the application. It mirrors the rows seeded by the migrations
+var Catalog = []PermissionInfo{
+	{PermAll, "Every permission"},
+	{PermStartupsCreate, "Create startups"},
+	{PermStartupsUpdate, "Update any startup"},
+	{PermStartupsDelete, "Delete any startup"},
+	{PermInvestorsCreate, "Create investors"},
+	{PermInvestorsUpdate, "Update investors"},
+	{PermInvestorsDelete, "Delete investors"},
+	{PermPartnersCreate, "Create partners"},
+	{PermPartnersUpdate, "Update partners"},
+	{PermPartnersDelete, "Delete partners"},
+	{PermNewsCreate, "Create news"},
+	{PermNewsUpdate, "Update news"},
+	{PermNewsDelete, "Delete news"},
+	{PermEventsCreate, "Create events"},
+	{PermEventsUpdate, "Update events"},
+	{PermEventsDelete, "Delete events"},
+	{PermOpportunitiesRead, "Read opportunities in the back office"},
+	{PermOpportunitiesCreate, "Create opportunities"},
+	{PermOpportunitiesUpdate, "Update opportunities"},
+	{PermOpportunitiesDelete, "Delete opportunities"},
+	{PermUsersRead, "Read any user profile"},
+	{PermUsersCreate, "Create users"},
+	{PermUsersUpdate, "Update users and assign roles"},
+	{PermUsersDelete, "Delete users"},
+	{PermUsersImpersonate, "Sign in as another user for support"},
+	{PermUsersInvite, "Invite users with any role"},
+	{PermStatisticsRead, "Read platform statistics"},
+	{PermSyncRead, "Read synchronization status"},
+	{PermSyncTrigger, "Trigger synchronizations"},
+	{PermRolesManage, "Create, update and delete roles"},
+	{PermAuditRead, "Read and export the audit log"},
+	{PermMessagesModerate, "Hide messages and review message reports"},
+	{PermFeaturesManage, "Turn features on and off"},
+	{"startups.*", "Every startup permission"},
+	{"investors.*", "Every investor permission"},
+	{"partners.*", "Every partner permission"},
+	{"news.*", "Every news permission"},
+	{"events.*", "Every event permission"},
+	{"opportunities.*", "Every opportunity permission"},
+	{"users.*", "Every user permission"},
+	{"sync.*", "Every synchronization permission"},
+}
+
+// grantingPermissions returns the permission names that grant perm: itself, its resource wildcard and "*"
+func grantingPermissions(perm string) []string {
+	names := []string{perm, PermAll}
+	if i := strings.Index(perm, "."); i > 0 {
+		names = append(names, perm[:i]+".*")
+	}
+	return names
+}
+
+// RoleHasPermission reports whether the named role is granted perm
+func RoleHasPermission(ctx context.Context, db *gorm.DB, role, perm string) (bool, error) {
+	var count int64
+	err := db.WithContext(ctx).
+		Table("role_permissions").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("roles.name = ? AND permissions.name IN ?", strings.ToLower(role), grantingPermissions(perm)).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// UserHasPermission reports whether the user's current role is granted perm.
+// The role is read from the database so that role changes apply without waiting for token renewal
+func UserHasPermission(ctx context.Context, db *gorm.DB, userID uint64, perm string) (bool, error) {
+	var role string
+	if err := db.WithContext(ctx).Table("users").Select("role").Where("id = ?", userID).Scan(&role).Error; err != nil {
+		return false, err
+	}
+	if role == "" {
+		return false, nil
+	}
+	return RoleHasPermission(ctx, db, role, perm)
+}
+
+// RoleExists reports whether a role with the given name is defined
+func RoleExists(ctx context.Context, db *gorm.DB, role string) (bool, error) {
+	var count int64
+	if err := db.WithContext(ctx).Table("roles").Where("name = ?", strings.ToLower(role)).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
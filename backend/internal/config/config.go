@@ -25,6 +25,8 @@ type Config struct {
 	Notifications NotificationsConfig `yaml:"notifications"`
 	Jobs          JobsConfig          `yaml:"jobs"`
 	Features      FeaturesConfig      `yaml:"features"`
+	Realtime      RealtimeConfig      `yaml:"realtime"`
+	Messaging     MessagingConfig     `yaml:"messaging"`
 }
 
 type AppConfig struct {
@@ -42,9 +44,63 @@ type DatabaseConfig struct {
 }
 
 type AuthConfig struct {
-	JWT                  JWTConfig     `yaml:"jwt"`
-	PasswordResetTTL     time.Duration `yaml:"password_reset_ttl"`
-	EmailVerificationTTL time.Duration `yaml:"email_verification_ttl"`
+	JWT                  JWTConfig             `yaml:"jwt"`
+	PasswordResetTTL     time.Duration         `yaml:"password_reset_ttl"`
+	EmailVerificationTTL time.Duration         `yaml:"email_verification_ttl"`
+	OAuth                OAuthConfig           `yaml:"oauth"`
+	Impersonation        ImpersonationConfig   `yaml:"impersonation"`
+	AccountDeletion      AccountDeletionConfig `yaml:"account_deletion"`
+	Password             PasswordConfig        `yaml:"password"`
+	MagicLink            MagicLinkConfig       `yaml:"magic_link"`
+	Registration         RegistrationConfig    `yaml:"registration"`
+}
+
+type RegistrationConfig struct {
+	// Disable open sign-up (/auth/register and new accounts from social login); accounts are then created from invitations
+	InviteOnly bool `yaml:"invite_only"`
+	// Lifetime of an invitation (defaults to 168h)
+	InvitationTTL time.Duration `yaml:"invitation_ttl"`
+}
+
+type MagicLinkConfig struct {
+	// Lifetime of a login link (defaults to 15m)
+	TTL time.Duration `yaml:"ttl"`
+	// Minimum delay between two links sent to the same email (defaults to 1m)
+	ResendInterval time.Duration `yaml:"resend_interval"`
+	// Only accept the link from the IP address that requested it
+	BindIP bool `yaml:"bind_ip"`
+	// Only accept the link from the browser (User-Agent) that requested it
+	BindDevice bool `yaml:"bind_device"`
+	// Where to send the browser after the link is used; JSON is returned when empty
+	SuccessRedirect string `yaml:"success_redirect"`
+	FailureRedirect string `yaml:"failure_redirect"`
+}
+
+type PasswordConfig struct {
+	// Minimum length in characters (defaults to 8)
+	MinLength int `yaml:"min_length"`
+	// Maximum length in bytes, at most 72 since bcrypt ignores the rest
+	MaxLength int `yaml:"max_length"`
+	// How many of lowercase, uppercase, digits and symbols a password must mix (0-4)
+	MinCharacterClasses int `yaml:"min_character_classes"`
+	// bcrypt cost of new hashes (defaults to 10, capped at 14)
+	BcryptCost int `yaml:"bcrypt_cost"`
+	// Offline list of breached password SHA-1 digests; the check is skipped when empty
+	BreachedList string `yaml:"breached_list"`
+}
+
+type AccountDeletionConfig struct {
+	// Delay before a deleted account is anonymized, during which the deletion can be cancelled (defaults to 720h)
+	GracePeriod time.Duration `yaml:"grace_period"`
+	// Cron spec of the job anonymizing accounts whose grace period is over
+	PurgeCron string `yaml:"purge_cron"`
+}
+
+type ImpersonationConfig struct {
+	// Lifetime of an impersonation session (defaults to 15m)
+	TTL time.Duration `yaml:"ttl"`
+	// Reject write requests made while impersonating
+	ReadOnly bool `yaml:"read_only"`
 }
 
 type JWTConfig struct {
@@ -53,6 +109,25 @@ type JWTConfig struct {
 	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
 }
 
+type OAuthConfig struct {
+	SuccessRedirect string                         `yaml:"success_redirect"`
+	FailureRedirect string                         `yaml:"failure_redirect"`
+	StateTTL        time.Duration                  `yaml:"state_ttl"`
+	Providers       map[string]OAuthProviderConfig `yaml:"providers"`
+}
+
+type OAuthProviderConfig struct {
+	Type         string   `yaml:"type"` // github | google | oidc
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	Issuer       string   `yaml:"issuer"`
+	AuthURL      string   `yaml:"auth_url"`
+	TokenURL     string   `yaml:"token_url"`
+	UserInfoURL  string   `yaml:"userinfo_url"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	Scopes       []string `yaml:"scopes"`
+}
+
 type APIConfig struct {
 	JEB JEBAPIConfig `yaml:"jeb"`
 }
@@ -94,6 +169,12 @@ type SecurityConfig struct {
 	CORS      CORSConfig      `yaml:"cors"`
 	RateLimit RateLimitConfig `yaml:"rate_limit"`
 	AuditLog  bool            `yaml:"audit_log"`
+	CSRF      CSRFConfig      `yaml:"csrf"`
+}
+
+type CSRFConfig struct {
+	// Require the X-CSRF-Token header on cookie-authenticated writes
+	Enabled bool `yaml:"enabled"`
 }
 
 type CORSConfig struct {
@@ -125,8 +206,24 @@ type S3Config struct {
 }
 
 type NotificationsConfig struct {
-	Email EmailConfig `yaml:"email"`
-	InApp bool        `yaml:"in_app"`
+	Email             EmailConfig             `yaml:"email"`
+	InApp             bool                    `yaml:"in_app"`
+	DeadlineReminders DeadlineRemindersConfig `yaml:"deadline_reminders"`
+	MessageDigest     MessageDigestConfig     `yaml:"message_digest"`
+}
+
+type MessageDigestConfig struct {
+	// Cron spec of the job emailing unread messages; disabled when empty
+	Cron string `yaml:"cron"`
+	// How long a message stays unread before it is emailed (defaults to 30m)
+	Delay time.Duration `yaml:"delay"`
+}
+
+type DeadlineRemindersConfig struct {
+	// Cron spec of the job announcing opportunities whose deadline is near; disabled when empty
+	Cron string `yaml:"cron"`
+	// How long before the deadline the reminder is sent (defaults to 72h)
+	Lead time.Duration `yaml:"lead"`
 }
 
 type EmailConfig struct {
@@ -140,6 +237,43 @@ type JobsConfig struct {
 	MaxRetries   int           `yaml:"max_retries"`
 }
 
+type MessagingConfig struct {
+	// How long after sending a message its sender can edit it (defaults to 15m)
+	EditWindow time.Duration `yaml:"edit_window"`
+	// How long after sending a message its sender can delete it (defaults to 1h)
+	DeleteWindow time.Duration     `yaml:"delete_window"`
+	Attachments  AttachmentsConfig `yaml:"attachments"`
+}
+
+type AttachmentsConfig struct {
+	// Largest accepted file in bytes (defaults to 10 MiB)
+	MaxSize int64 `yaml:"max_size"`
+	// Accepted MIME types (defaults to PDF, PNG, JPEG, GIF, WebP and Office documents)
+	AllowedTypes []string `yaml:"allowed_types"`
+	// Attachments allowed on a single message (defaults to 5)
+	MaxPerMessage int `yaml:"max_per_message"`
+	// Lifetime of the presigned download URLs (defaults to 5m)
+	URLTTL time.Duration `yaml:"url_ttl"`
+	// Cron schedule removing uploads that were never sent with a message; empty disables it
+	PurgeCron string `yaml:"purge_cron"`
+	// Age after which an upload that was never sent is removed (defaults to 24h)
+	PurgeAfter time.Duration `yaml:"purge_after"`
+}
+
+type RealtimeConfig struct {
+	// Event fan-out between replicas: memory (single replica) or postgres (LISTEN/NOTIFY)
+	Broker string `yaml:"broker"`
+	// Postgres notification channel (defaults to realtime_events)
+	Channel string `yaml:"channel"`
+	// Interval of the WebSocket keepalive pings (defaults to 30s)
+	PingInterval time.Duration `yaml:"ping_interval"`
+	// Events queued per connection before a slow client is disconnected (defaults to 32)
+	BufferSize int `yaml:"buffer_size"`
+	// How long and how many recent events are kept to resume event streams with Last-Event-ID (defaults to 5m and 1000)
+	Retention   time.Duration `yaml:"retention"`
+	HistorySize int           `yaml:"history_size"`
+}
+
 type FeaturesConfig struct {
 	EnableMessaging     bool `yaml:"enable_messaging"`
 	EnableOpportunities bool `yaml:"enable_opportunities"`
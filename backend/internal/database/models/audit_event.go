@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// AuditChange holds the value of a field before and after a privileged action
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditEvent is an append-only record of a privileged action
+type AuditEvent struct {
+	// Unique event identifier
+	ID uint64 `json:"id" gorm:"primaryKey" example:"1"`
+	// User who performed the action (nil for system actions)
+	ActorID *uint64 `json:"actor_id,omitempty" gorm:"index" example:"1"`
+	// Actor email at the time of the action
+	ActorEmail *string `json:"actor_email,omitempty" gorm:"type:varchar(255)" example:"admin@jeb.tld"`
+	// Admin who acted through an impersonation session
+	ImpersonatorID *uint64 `json:"impersonator_id,omitempty" gorm:"index" example:"1"`
+	// Action performed (<target>.<verb>)
+	Action string `json:"action" gorm:"type:varchar(100);not null;index" example:"startup.update"`
+	// Type of the affected resource
+	TargetType string `json:"target_type" gorm:"type:varchar(50);not null" example:"startup"`
+	// Identifier of the affected resource
+	TargetID *string `json:"target_id,omitempty" gorm:"type:varchar(64)" example:"42"`
+	// Changed fields with their previous and new values
+	Changes map[string]AuditChange `json:"changes,omitempty" gorm:"type:jsonb;serializer:json"`
+	// Response status of the request
+	Status int `json:"status" gorm:"not null" example:"200"`
+	// Client IP address
+	IP *string `json:"ip,omitempty" gorm:"type:varchar(64)" example:"203.0.113.7"`
+	// Request correlation ID (X-Request-ID)
+	RequestID *string `json:"request_id,omitempty" gorm:"type:varchar(64)" example:"6f1c2e4b9a7d4c1e8f3a2b5c6d7e8f90"`
+	// Event timestamp (UTC)
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index" format:"date-time"`
+}
@@ -7,6 +7,7 @@ type AuthToken struct {
 	UserID    uint64    `json:"user_id"`
 	TokenHash string    `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
 	TokenType string    `json:"token_type" gorm:"type:varchar(16);not null"`
+	Payload   *string   `json:"-" gorm:"type:text"`
 	ExpiresAt time.Time `json:"expires_at"`
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 }
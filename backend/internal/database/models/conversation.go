@@ -1,6 +1,15 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+// Subjects a conversation can be about
+const (
+	ConversationContextStartup     = "startup"
+	ConversationContextOpportunity = "opportunity"
+)
 
 type Conversation struct {
 	// Unique conversation identifier
@@ -9,6 +18,12 @@ type Conversation struct {
 	Title *string `json:"title,omitempty" gorm:"type:varchar(255)" example:"Project Discussion"`
 	// Whether this is a group conversation
 	IsGroup bool `json:"is_group" gorm:"type:boolean;not null;default:false" example:"false"`
+	// Identifies a one-to-one conversation so that it is only created once, see DirectKey
+	DirectKey *string `json:"-" gorm:"type:varchar(100);uniqueIndex"`
+	// What the conversation is about, if anything
+	ContextType *string `json:"context_type,omitempty" gorm:"type:varchar(20);index:idx_conversations_context" enums:"startup,opportunity" example:"startup"`
+	// ID of the startup or opportunity the conversation is about
+	ContextID *uint64 `json:"context_id,omitempty" gorm:"index:idx_conversations_context" example:"12"`
 	// ID of the last message (optimization)
 	LastMessageID *uint64 `json:"last_message_id,omitempty" gorm:"type:bigint"`
 	// Creation timestamp (UTC)
@@ -20,3 +35,16 @@ type Conversation struct {
 	Messages     []Message                 `json:"messages,omitempty" gorm:"foreignKey:ConversationID"`
 	LastMessage  *Message                  `json:"last_message,omitempty" gorm:"foreignKey:LastMessageID"`
 }
+
+// DirectKey identifies the one-to-one conversation between two users about an optional context,
+// whatever the order of the users
+func DirectKey(a, b uint64, contextType *string, contextID *uint64) string {
+	if a > b {
+		a, b = b, a
+	}
+	key := fmt.Sprintf("%d:%d", a, b)
+	if contextType != nil && contextID != nil {
+		key += fmt.Sprintf(":%s:%d", *contextType, *contextID)
+	}
+	return key
+}
@@ -2,6 +2,12 @@ package models
 
 import "time"
 
+// Participant roles
+const (
+	ParticipantRoleMember = "member"
+	ParticipantRoleOwner  = "owner"
+)
+
 type ConversationParticipant struct {
 	// Unique participant identifier
 	ID uint64 `json:"id" gorm:"primaryKey" example:"1"`
@@ -15,6 +21,12 @@ type ConversationParticipant struct {
 	LastReadMessageID *uint64 `json:"last_read_message_id,omitempty" gorm:"type:bigint"`
 	// When the user joined the conversation
 	JoinedAt time.Time `json:"joined_at" gorm:"autoCreateTime" format:"date-time"`
+	// When the user left or was removed; they no longer have access to the conversation
+	LeftAt *time.Time `json:"left_at,omitempty" format:"date-time"`
+	// ID of the last message emailed to the user in a digest
+	DigestedMessageID *uint64 `json:"-" gorm:"type:bigint"`
+	// Whether the user muted the conversation: its messages raise neither notifications nor the unread badge
+	Muted bool `json:"muted" gorm:"not null;default:false" example:"false"`
 
 	// Relationships
 	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
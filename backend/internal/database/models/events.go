@@ -27,4 +27,6 @@ type Event struct {
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" format:"date-time"`
 	// Update timestamp (UTC)
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime" format:"date-time"`
+	// Whether the authenticated user saved the event
+	Favorited bool `json:"favorited" gorm:"-" example:"false"`
 }
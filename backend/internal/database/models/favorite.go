@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Favorite target types
+const (
+	FavoriteTargetStartup     = "startup"
+	FavoriteTargetEvent       = "event"
+	FavoriteTargetOpportunity = "opportunity"
+)
+
+// FavoriteTargets lists the entities that can be favorited
+var FavoriteTargets = []string{FavoriteTargetStartup, FavoriteTargetEvent, FavoriteTargetOpportunity}
+
+// Favorite bookmarks a startup, an event or an opportunity for a user
+type Favorite struct {
+	// Unique favorite identifier
+	ID uint64 `json:"id" gorm:"primaryKey" example:"1"`
+	// User who saved the entity
+	UserID uint64 `json:"user_id" gorm:"not null;uniqueIndex:idx_favorites_user_target" example:"1"`
+	// Kind of the saved entity
+	TargetType string `json:"target_type" gorm:"type:varchar(20);not null;uniqueIndex:idx_favorites_user_target;index:idx_favorites_target" enums:"startup,event,opportunity" example:"startup"`
+	// Identifier of the saved entity
+	TargetID uint64 `json:"target_id" gorm:"not null;uniqueIndex:idx_favorites_user_target;index:idx_favorites_target" example:"3"`
+	// When the entity was saved
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" format:"date-time"`
+
+	// The saved startup, event or opportunity, absent when it was deleted
+	Target interface{} `json:"target,omitempty" gorm:"-" swaggertype:"object"`
+}
+
+func (Favorite) TableName() string {
+	return "favorites"
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// FeatureFlag overrides the configured state of a feature flag
+type FeatureFlag struct {
+	// Flag name
+	Name string `json:"name" gorm:"type:varchar(50);primaryKey" example:"messaging"`
+	// Whether the feature is on
+	Enabled bool `json:"enabled" gorm:"not null" example:"false"`
+	// Administrator who set the override
+	UpdatedBy *uint64 `json:"updated_by,omitempty" example:"1"`
+	// When the override was set
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime" format:"date-time"`
+}
+
+func (FeatureFlag) TableName() string {
+	return "feature_flags"
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Invitation statuses, derived from the timestamps of an invitation
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+)
+
+// Invitation lets an admin or a founder onboard someone with a given role, linked to a startup or investor profile
+type Invitation struct {
+	// Unique invitation identifier
+	ID uint64 `json:"id" gorm:"primaryKey" example:"1"`
+	// Invited email address
+	Email string `json:"email" gorm:"type:varchar(255);not null;index" format:"email" example:"jane@startup.tld"`
+	// Role granted on acceptance
+	Role string `json:"role" gorm:"type:varchar(50);not null" example:"founder"`
+	// Startup the invitee becomes a founder of
+	StartupID *uint64 `json:"startup_id,omitempty" gorm:"index" example:"3"`
+	// Investor profile the invitee is linked to
+	InvestorID *uint64 `json:"investor_id,omitempty" example:"2"`
+	TokenHash  string  `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	// User who created the invitation
+	InvitedBy uint64 `json:"invited_by" gorm:"not null;index" example:"1"`
+	// Expiration timestamp (UTC)
+	ExpiresAt time.Time `json:"expires_at" format:"date-time"`
+	// When the invitation was accepted
+	AcceptedAt *time.Time `json:"accepted_at,omitempty" format:"date-time"`
+	// User who accepted the invitation
+	AcceptedBy *uint64 `json:"accepted_by,omitempty" example:"12"`
+	// When the invitation was revoked
+	RevokedAt *time.Time `json:"revoked_at,omitempty" format:"date-time"`
+	// Creation timestamp (UTC)
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" format:"date-time"`
+	// pending, accepted, revoked or expired
+	Status string `json:"status" gorm:"-" enums:"pending,accepted,revoked,expired" example:"pending"`
+}
+
+// CurrentStatus derives the status of the invitation at now
+func (i *Invitation) CurrentStatus(now time.Time) string {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case i.RevokedAt != nil:
+		return InvitationRevoked
+	case !i.ExpiresAt.After(now):
+		return InvitationExpired
+	default:
+		return InvitationPending
+	}
+}
@@ -2,6 +2,21 @@ package models
 
 import "time"
 
+// Message kinds
+const (
+	MessageKindText   = "text"
+	MessageKindSystem = "system"
+)
+
+// Events recorded by system messages
+const (
+	SystemEventParticipantAdded   = "participant_added"
+	SystemEventParticipantRemoved = "participant_removed"
+	SystemEventParticipantLeft    = "participant_left"
+	SystemEventRenamed            = "conversation_renamed"
+	SystemEventOwnerChanged       = "owner_changed"
+)
+
 type Message struct {
 	// Unique message identifier
 	ID uint64 `json:"id" gorm:"primaryKey" example:"1"`
@@ -11,10 +26,28 @@ type Message struct {
 	SenderID uint64 `json:"sender_id" gorm:"not null" example:"1"`
 	// Message content
 	Content string `json:"content" gorm:"type:text;not null" example:"Hello, how are you?"`
+	// text for messages written by users, system for the changes of the conversation, sent on behalf of the user who made them
+	Kind string `json:"kind" gorm:"type:varchar(20);not null;default:'text'" enums:"text,system" example:"text"`
+	// What a system message records
+	Event *string `json:"event,omitempty" gorm:"type:varchar(40)" enums:"participant_added,participant_removed,participant_left,conversation_renamed,owner_changed" example:"participant_added"`
+	// User a system message is about (added, removed, new owner...)
+	SubjectUserID *uint64 `json:"subject_user_id,omitempty" example:"2"`
 	// Creation timestamp (UTC)
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" format:"date-time"`
-	// Soft delete timestamp (for moderation)
+	// Last edit timestamp (UTC)
+	EditedAt *time.Time `json:"edited_at,omitempty" format:"date-time"`
+	// Soft delete timestamp, set when the sender deletes the message or a moderator hides it
 	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	// User who deleted the message: the sender, or the moderator who hid it
+	DeletedBy *uint64 `json:"deleted_by,omitempty" example:"1"`
+	// Reason given by the moderator who hid the message
+	ModerationReason *string `json:"moderation_reason,omitempty" example:"Harassment"`
+
+	Sender      *User               `json:"sender,omitempty" gorm:"foreignKey:SenderID"`
+	Attachments []MessageAttachment `json:"attachments,omitempty" gorm:"foreignKey:MessageID"`
+}
 
-	Sender *User `json:"sender,omitempty" gorm:"foreignKey:SenderID"`
+// Moderated reports whether the message was hidden by a moderator rather than deleted by its sender
+func (m *Message) Moderated() bool {
+	return m.DeletedAt != nil && m.DeletedBy != nil && *m.DeletedBy != m.SenderID
 }
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// MessageAttachment is a file uploaded to a conversation. It stays pending until it is sent with a message
+type MessageAttachment struct {
+	// Unique attachment identifier
+	ID uint64 `json:"id" gorm:"primaryKey" example:"1"`
+	// Conversation the file was uploaded to
+	ConversationID uint64 `json:"conversation_id" gorm:"not null;index" example:"1"`
+	// Message the file was sent with, empty until then
+	MessageID *uint64 `json:"message_id,omitempty" gorm:"index" example:"1"`
+	// User who uploaded the file
+	UploaderID uint64 `json:"uploader_id" gorm:"not null" example:"1"`
+	// Object key in the media bucket, never exposed
+	StorageKey string `json:"-" gorm:"not null;uniqueIndex"`
+	// Original file name
+	FileName string `json:"file_name" gorm:"not null" example:"pitch-deck.pdf"`
+	// MIME type
+	ContentType string `json:"content_type" gorm:"not null" example:"application/pdf"`
+	// Size in bytes
+	Size int64 `json:"size" gorm:"not null" example:"482133"`
+	// Upload timestamp (UTC)
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" format:"date-time"`
+}
+
+func (MessageAttachment) TableName() string {
+	return "message_attachments"
+}
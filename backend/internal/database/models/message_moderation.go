@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// Message report reasons
+const (
+	ReportReasonSpam          = "spam"
+	ReportReasonHarassment    = "harassment"
+	ReportReasonInappropriate = "inappropriate"
+	ReportReasonOther         = "other"
+)
+
+// Message report statuses
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusDismissed = "dismissed"
+	ReportStatusActioned  = "actioned"
+)
+
+// MessageEdit keeps a previous version of an edited message
+type MessageEdit struct {
+	// Unique edit identifier
+	ID uint64 `json:"id" gorm:"primaryKey" example:"1"`
+	// Edited message ID
+	MessageID uint64 `json:"message_id" gorm:"not null;index" example:"1"`
+	// Content before the edit
+	Content string `json:"content" gorm:"type:text;not null" example:"Helo, how are you?"`
+	// User who edited the message
+	EditedBy *uint64 `json:"edited_by,omitempty" example:"1"`
+	// When the message was edited (UTC)
+	EditedAt time.Time `json:"edited_at" gorm:"autoCreateTime" format:"date-time"`
+}
+
+func (MessageEdit) TableName() string {
+	return "message_edits"
+}
+
+// MessageReport is a user's report of a message, reviewed by moderators
+type MessageReport struct {
+	// Unique report identifier
+	ID uint64 `json:"id" gorm:"primaryKey" example:"1"`
+	// Reported message ID
+	MessageID uint64 `json:"message_id" gorm:"not null;index;uniqueIndex:idx_message_reports_reporter" example:"1"`
+	// User who reported the message
+	ReporterID uint64 `json:"reporter_id" gorm:"not null;uniqueIndex:idx_message_reports_reporter" example:"2"`
+	// Report reason
+	Reason string `json:"reason" gorm:"type:varchar(30);not null" enums:"spam,harassment,inappropriate,other" example:"spam"`
+	// Free-form details from the reporter
+	Details *string `json:"details,omitempty" gorm:"type:text" example:"Unsolicited advertising"`
+	// Review status
+	Status string `json:"status" gorm:"type:varchar(20);not null;default:'open';index" enums:"open,dismissed,actioned" example:"open"`
+	// Moderator who reviewed the report
+	ReviewedBy *uint64 `json:"reviewed_by,omitempty" example:"1"`
+	// When the report was reviewed (UTC)
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty" format:"date-time"`
+	// Note left by the moderator
+	ResolutionNote *string `json:"resolution_note,omitempty" gorm:"type:text"`
+	// Creation timestamp (UTC)
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" format:"date-time"`
+
+	Message  *Message `json:"message,omitempty" gorm:"foreignKey:MessageID"`
+	Reporter *User    `json:"reporter,omitempty" gorm:"foreignKey:ReporterID"`
+}
+
+func (MessageReport) TableName() string {
+	return "message_reports"
+}
@@ -19,8 +19,12 @@ type Opportunity struct {
 	ExternalLink *string `json:"external_link,omitempty" gorm:"type:varchar(500)" format:"uri" example:"https://example.com/opportunity"`
 	// Deadline (UTC)
 	Deadline *time.Time `json:"deadline,omitempty" gorm:"index:idx_opportunities_deadline" format:"date-time"`
+	// When the approaching deadline was announced
+	DeadlineRemindedAt *time.Time `json:"-"`
 	// Creation timestamp (UTC)
 	CreatedAt time.Time `json:"created_at" format:"date-time"`
 	// Update timestamp (UTC)
 	UpdatedAt time.Time `json:"updated_at" format:"date-time"`
+	// Whether the authenticated user saved the opportunity
+	Favorited bool `json:"favorited" gorm:"-" example:"false"`
 }
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type Role struct {
+	// Unique role identifier
+	ID uint64 `json:"id" gorm:"primaryKey" example:"1"`
+	// Role name referenced by users.role (lowercase)
+	Name string `json:"name" gorm:"type:varchar(50);uniqueIndex;not null" example:"moderator"`
+	// Human-readable description
+	Description *string `json:"description,omitempty" gorm:"type:text" example:"Moderates news and events"`
+	// Built-in roles cannot be renamed or deleted
+	IsSystem bool `json:"is_system" gorm:"type:boolean;not null;default:false" example:"false"`
+	// Creation timestamp (UTC)
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" format:"date-time"`
+	// Update timestamp (UTC)
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime" format:"date-time"`
+
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions;"`
+}
+
+type Permission struct {
+	// Unique permission identifier
+	ID uint64 `json:"id" gorm:"primaryKey" example:"1"`
+	// Permission name (resource.action)
+	Name string `json:"name" gorm:"type:varchar(100);uniqueIndex;not null" example:"startups.update"`
+	// Human-readable description
+	Description *string `json:"description,omitempty" gorm:"type:text" example:"Update any startup"`
+}
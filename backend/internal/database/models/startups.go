@@ -39,4 +39,6 @@ type Startup struct {
 	Founders datatypes.JSON `gorm:"type:jsonb;default:'[]'" json:"founders" swaggertype:"object"`
 	// Views count
 	ViewsCount int64 `gorm:"not null;default:0" json:"views_count" example:"0"`
+	// Whether the authenticated user saved the startup
+	Favorited bool `gorm:"-" json:"favorited" example:"false"`
 }
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// UserBlock records that a user no longer wants to hear from another one
+type UserBlock struct {
+	// Unique block identifier
+	ID uint64 `json:"id" gorm:"primaryKey" example:"1"`
+	// User who blocked
+	BlockerID uint64 `json:"blocker_id" gorm:"not null;uniqueIndex:idx_user_blocks_pair" example:"1"`
+	// Blocked user
+	BlockedID uint64 `json:"blocked_id" gorm:"not null;uniqueIndex:idx_user_blocks_pair;index" example:"2"`
+	// When the user was blocked
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" format:"date-time"`
+
+	Blocked *User `json:"blocked,omitempty" gorm:"foreignKey:BlockedID"`
+}
+
+func (UserBlock) TableName() string {
+	return "user_blocks"
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type UserIdentity struct {
+	// Unique identity identifier
+	ID uint64 `json:"id" gorm:"primaryKey" example:"1"`
+	// Linked user ID
+	UserID uint64 `json:"user_id" gorm:"not null;index;uniqueIndex:idx_user_identities_user_provider" example:"1"`
+	// Provider name as configured (github, google, oidc, ...)
+	Provider string `json:"provider" gorm:"type:varchar(50);not null;uniqueIndex:idx_user_identities_provider_subject;uniqueIndex:idx_user_identities_user_provider" example:"github"`
+	// Stable subject identifier at the provider
+	Subject string `json:"-" gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject"`
+	// Email reported by the provider
+	Email *string `json:"email,omitempty" gorm:"type:varchar(255)" format:"email" example:"jane@example.com"`
+	// Whether the provider asserted the email as verified
+	EmailVerified bool `json:"email_verified" gorm:"type:boolean;not null;default:false" example:"true"`
+	// Last successful login through this identity (UTC)
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" format:"date-time"`
+	// Creation timestamp (UTC)
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" format:"date-time"`
+}
+
+func (UserIdentity) TableName() string { return "user_identities" }
@@ -2,6 +2,13 @@ package models
 
 import "time"
 
+// How often a user is emailed about the messages they left unread
+const (
+	MessageDigestImmediate = "immediate"
+	MessageDigestDaily     = "daily"
+	MessageDigestNever     = "never"
+)
+
 type User struct {
 	// Unique user identifier
 	ID uint64 `json:"id" gorm:"primaryKey" example:"1"`
@@ -12,6 +19,8 @@ type User struct {
 	// Role name
 	Role         string `json:"role" gorm:"type:varchar(50);not null" enums:"admin,user,investor,founder" example:"user"`
 	PasswordHash string `json:"-" gorm:"type:text;not null"`
+	// Session generation, bumped to revoke every refresh token issued before
+	TokenVersion uint64 `json:"-" gorm:"type:bigint;not null;default:0"`
 	// Related founder profile ID
 	FounderID *uint64 `json:"founder_id,omitempty" gorm:"type:bigint" example:"1"`
 	// Related investor profile ID
@@ -20,8 +29,57 @@ type User struct {
 	ImageURL *string `json:"image_url,omitempty" gorm:"type:text" format:"uri" example:"https://cdn.example.com/avatars/1.png"`
 	// Whether the email has been verified
 	EmailVerified bool `json:"email_verified" gorm:"type:boolean;not null;default:false" example:"false"`
+	// Whether the user appears in the public directory
+	Discoverable bool `json:"discoverable" gorm:"type:boolean;not null;default:true" example:"true"`
+	// Whether other signed-in users can see the email address
+	ShowEmail bool `json:"show_email" gorm:"type:boolean;not null;default:false" example:"false"`
+	// How often the user is emailed a digest of their unread messages
+	MessageDigest string `json:"message_digest" gorm:"type:varchar(20);not null;default:'daily'" enums:"immediate,daily,never" example:"daily"`
+	// When the last digest was emailed
+	MessageDigestSentAt *time.Time `json:"-"`
+	// When a requested account deletion becomes effective
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" gorm:"index" format:"date-time"`
+	// When the account was anonymized after deletion
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty" format:"date-time"`
 	// Creation timestamp (UTC)
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" format:"date-time"`
 	// Update timestamp (UTC)
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime" format:"date-time"`
 }
+
+// UserProfile is the projection of a user shown to other people. Anonymous viewers get the public fields only;
+// signed-in viewers also get the role and profile links, and the email when the user chose to show it
+type UserProfile struct {
+	// Unique user identifier
+	ID uint64 `json:"id" example:"1"`
+	// Display name
+	Name string `json:"name" example:"Jane Doe"`
+	// Avatar URL
+	ImageURL *string `json:"image_url,omitempty" format:"uri" example:"https://cdn.example.com/avatars/1.png"`
+	// Role name (signed-in viewers only)
+	Role string `json:"role,omitempty" example:"founder"`
+	// Email address (signed-in viewers, when the user shows it)
+	Email *string `json:"email,omitempty" format:"email" example:"user@example.com"`
+	// Related founder profile ID (signed-in viewers only)
+	FounderID *uint64 `json:"founder_id,omitempty" example:"1"`
+	// Related investor profile ID (signed-in viewers only)
+	InvestorID *uint64 `json:"investor_id,omitempty" example:"2"`
+}
+
+// PublicProfile returns the fields of u that anyone may see
+func (u User) PublicProfile() UserProfile {
+	return UserProfile{ID: u.ID, Name: u.Name, ImageURL: u.ImageURL}
+}
+
+// MemberProfile returns the fields of u visible to signed-in users
+func (u User) MemberProfile() UserProfile {
+	p := u.PublicProfile()
+	p.Role = u.Role
+	p.FounderID = u.FounderID
+	p.InvestorID = u.InvestorID
+	if u.ShowEmail {
+		email := u.Email
+		p.Email = &email
+	}
+	return p
+}
@@ -0,0 +1,150 @@
+// Package features resolves the feature flags: defaults come from the features config and can be
+// overridden at runtime, the overrides being stored in the database
+package features
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Flags
+const (
+	Messaging     = "messaging"
+	Opportunities = "opportunities"
+	Favorites     = "favorites"
+)
+
+// Names lists every flag
+var Names = []string{Messaging, Opportunities, Favorites}
+
+// ErrUnknownFlag is returned when overriding a flag that does not exist
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// refreshInterval bounds how long an override made on another replica takes to apply
+const refreshInterval = 10 * time.Second
+
+// Flag is the state of a feature flag
+type Flag struct {
+	// Flag name
+	Name string `json:"name" example:"messaging"`
+	// Whether the feature is on
+	Enabled bool `json:"enabled" example:"true"`
+	// Value from the configuration, used when there is no override
+	Default bool `json:"default" example:"true"`
+	// Whether an administrator overrode the configuration
+	Overridden bool `json:"overridden" example:"false"`
+	// Who set the override and when
+	UpdatedBy *uint64    `json:"updated_by,omitempty" example:"1"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" format:"date-time"`
+}
+
+// Store answers whether features are enabled. The overrides are cached for a few seconds
+type Store struct {
+	db       *gorm.DB
+	defaults map[string]bool
+
+	mu        sync.Mutex
+	overrides map[string]models.FeatureFlag
+	loadedAt  time.Time
+}
+
+// New returns a store using cfg as defaults. db may be nil, in which case the configuration alone applies
+func New(cfg config.FeaturesConfig, db *gorm.DB) *Store {
+	return &Store{
+		db: db,
+		defaults: map[string]bool{
+			Messaging:     cfg.EnableMessaging,
+			Opportunities: cfg.EnableOpportunities,
+			Favorites:     cfg.EnableFavorites,
+		},
+	}
+}
+
+// Enabled reports whether the named feature is on. When the overrides cannot be read, the last known
+// ones, or else the configuration, apply
+func (s *Store) Enabled(ctx context.Context, name string) bool {
+	overrides, _ := s.load(ctx)
+	if o, ok := overrides[name]; ok {
+		return o.Enabled
+	}
+	return s.defaults[name]
+}
+
+// All returns the state of every flag
+func (s *Store) All(ctx context.Context) ([]Flag, error) {
+	overrides, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	flags := make([]Flag, 0, len(Names))
+	for _, name := range Names {
+		f := Flag{Name: name, Enabled: s.defaults[name], Default: s.defaults[name]}
+		if o, ok := overrides[name]; ok {
+			updatedAt := o.UpdatedAt
+			f.Enabled, f.Overridden, f.UpdatedBy, f.UpdatedAt = o.Enabled, true, o.UpdatedBy, &updatedAt
+		}
+		flags = append(flags, f)
+	}
+	return flags, nil
+}
+
+// Set overrides the named flag on behalf of userID, or removes the override when enabled is nil
+func (s *Store) Set(ctx context.Context, name string, enabled *bool, userID uint64) (Flag, error) {
+	if !slices.Contains(Names, name) {
+		return Flag{}, ErrUnknownFlag
+	}
+	if s.db == nil {
+		return Flag{}, errors.New("feature flags cannot be overridden without a database")
+	}
+	db := s.db.WithContext(ctx)
+	var err error
+	if enabled == nil {
+		err = db.Where("name = ?", name).Delete(&models.FeatureFlag{}).Error
+	} else {
+		err = db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_by", "updated_at"}),
+		}).Create(&models.FeatureFlag{Name: name, Enabled: *enabled, UpdatedBy: &userID}).Error
+	}
+	if err != nil {
+		return Flag{}, fmt.Errorf("override %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+	flags, err := s.All(ctx)
+	if err != nil {
+		return Flag{}, err
+	}
+	return flags[slices.Index(Names, name)], nil
+}
+
+func (s *Store) load(ctx context.Context) (map[string]models.FeatureFlag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db == nil || time.Since(s.loadedAt) < refreshInterval {
+		return s.overrides, nil
+	}
+	var rows []models.FeatureFlag
+	if err := s.db.WithContext(ctx).Find(&rows).Error; err != nil {
+		// The last known overrides keep applying until the next refresh, rather than querying again on every call
+		s.loadedAt = time.Now()
+		return s.overrides, fmt.Errorf("load feature flags: %w", err)
+	}
+	s.overrides = make(map[string]models.FeatureFlag, len(rows))
+	for _, row := range rows {
+		s.overrides[row.Name] = row
+	}
+	s.loadedAt = time.Now()
+	return s.overrides, nil
+}
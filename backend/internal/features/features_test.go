@@ -0,0 +1,93 @@
+package features_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/features"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestStore(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.FeatureFlag{}))
+	ctx := context.Background()
+	flags := features.New(config.FeaturesConfig{EnableMessaging: true, EnableOpportunities: true}, db)
+
+	assert.True(t, flags.Enabled(ctx, features.Messaging))
+	assert.False(t, flags.Enabled(ctx, features.Favorites))
+	assert.False(t, flags.Enabled(ctx, "unknown"))
+
+	// Overrides win over the configuration until they are removed
+	off, on := false, true
+	flag, err := flags.Set(ctx, features.Messaging, &off, 7)
+	require.NoError(t, err)
+	assert.False(t, flag.Enabled)
+	assert.True(t, flag.Default)
+	assert.True(t, flag.Overridden)
+	require.NotNil(t, flag.UpdatedBy)
+	assert.Equal(t, uint64(7), *flag.UpdatedBy)
+	assert.False(t, flags.Enabled(ctx, features.Messaging))
+
+	_, err = flags.Set(ctx, features.Messaging, &on, 7)
+	require.NoError(t, err)
+	assert.True(t, flags.Enabled(ctx, features.Messaging))
+	var count int64
+	db.Model(&models.FeatureFlag{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	flag, err = flags.Set(ctx, features.Messaging, nil, 7)
+	require.NoError(t, err)
+	assert.False(t, flag.Overridden)
+	assert.True(t, flag.Enabled)
+
+	_, err = flags.Set(ctx, "unknown", &on, 7)
+	assert.ErrorIs(t, err, features.ErrUnknownFlag)
+
+	all, err := flags.All(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, len(features.Names))
+	assert.Equal(t, features.Favorites, all[2].Name)
+
+	// Without a database, the configuration applies
+	flags = features.New(config.FeaturesConfig{EnableFavorites: true}, nil)
+	assert.True(t, flags.Enabled(ctx, features.Favorites))
+	_, err = flags.Set(ctx, features.Favorites, &off, 7)
+	assert.Error(t, err)
+}
+
+func TestStore_LoadFailure(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.FeatureFlag{}))
+	ctx := context.Background()
+	flags := features.New(config.FeaturesConfig{EnableMessaging: true}, db)
+	off := false
+	_, err = flags.Set(ctx, features.Messaging, &off, 7)
+	require.NoError(t, err)
+
+	failing, queries := false, 0
+	require.NoError(t, db.Callback().Query().Before("gorm:query").Register("test:fail", func(tx *gorm.DB) {
+		if failing {
+			queries++
+			_ = tx.AddError(errors.New("database unavailable"))
+		}
+	}))
+	failing = true
+
+	// The override is saved but cannot be read back; the last known flags keep applying without
+	// querying the database on every check
+	_, err = flags.Set(ctx, features.Messaging, &off, 7)
+	assert.Error(t, err)
+	for range 3 {
+		assert.False(t, flags.Enabled(ctx, features.Messaging))
+	}
+	assert.Equal(t, 1, queries)
+}
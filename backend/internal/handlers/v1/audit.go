@@ -0,0 +1,229 @@
+package v1
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/http/pagination"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// maxAuditExportRows bounds a single CSV export
+const maxAuditExportRows = 50000
+
+type AuditHandler struct {
+	log *logrus.Logger
+	db  *gorm.DB
+}
+
+type auditFilterParams struct {
+	ActorID        *uint64    `form:"actor_id" binding:"omitempty,min=1"`
+	ImpersonatorID *uint64    `form:"impersonator_id" binding:"omitempty,min=1"`
+	Action         string     `form:"action" binding:"omitempty,max=100"`
+	TargetType     string     `form:"target_type" binding:"omitempty,max=50"`
+	TargetID       string     `form:"target_id" binding:"omitempty,max=64"`
+	From           *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To             *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+func NewAuditHandler(log *logrus.Logger, db *gorm.DB) *AuditHandler {
+	return &AuditHandler{
+		log: log,
+		db:  db,
+	}
+}
+
+// ListAuditEvents godoc
+// @Summary      List audit events
+// @Description  Returns the audit trail of privileged actions, newest first, with optional filters.
+// @Tags         Audit
+// @Security     CookieAuth
+// @Produce      json
+// @Param        page        query int    false "Page" default(1)
+// @Param        per_page    query int    false "Page size" default(20)
+// @Param        actor_id    query int    false "Filter by actor user ID"
+// @Param        impersonator_id query int false "Filter by impersonating admin ID"
+// @Param        action      query string false "Filter by action (e.g. startup.update)"
+// @Param        target_type query string false "Filter by target type (e.g. startup)"
+// @Param        target_id   query string false "Filter by target ID"
+// @Param        from        query string false "Only events at or after this time (RFC3339)"
+// @Param        to          query string false "Only events before this time (RFC3339)"
+// @Success      200 {object} response.AuditEventListResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/audit [get]
+func (h *AuditHandler) ListAuditEvents(c *gin.Context) {
+	params := pagination.Parse(c)
+	query, ok := h.filteredQuery(c)
+	if !ok {
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		h.log.WithError(err).Error("failed to count audit events")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to count audit events", nil)
+		return
+	}
+
+	var events []models.AuditEvent
+	if err := query.Order("created_at DESC, id DESC").
+		Offset((params.Page - 1) * params.PerPage).
+		Limit(params.PerPage).
+		Find(&events).Error; err != nil {
+		h.log.WithError(err).Error("failed to list audit events")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve audit events", nil)
+		return
+	}
+
+	totalPages := (int(total) + params.PerPage - 1) / params.PerPage
+	response.JSON(c, http.StatusOK, gin.H{
+		"data": events,
+		"pagination": gin.H{
+			"page":     params.Page,
+			"per_page": params.PerPage,
+			"total":    total,
+			"has_next": params.Page < totalPages,
+			"has_prev": params.Page > 1,
+		},
+	})
+}
+
+// ExportAuditEvents godoc
+// @Summary      Export audit events
+// @Description  Streams the filtered audit trail as CSV, oldest first (at most 50000 rows).
+// @Tags         Audit
+// @Security     CookieAuth
+// @Produce      text/csv
+// @Param        actor_id    query int    false "Filter by actor user ID"
+// @Param        impersonator_id query int false "Filter by impersonating admin ID"
+// @Param        action      query string false "Filter by action (e.g. startup.update)"
+// @Param        target_type query string false "Filter by target type (e.g. startup)"
+// @Param        target_id   query string false "Filter by target ID"
+// @Param        from        query string false "Only events at or after this time (RFC3339)"
+// @Param        to          query string false "Only events before this time (RFC3339)"
+// @Success      200 {string} string "CSV file"
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/audit/export [get]
+func (h *AuditHandler) ExportAuditEvents(c *gin.Context) {
+	query, ok := h.filteredQuery(c)
+	if !ok {
+		return
+	}
+
+	rows, err := query.Order("created_at ASC, id ASC").Limit(maxAuditExportRows).Rows()
+	if err != nil {
+		h.log.WithError(err).Error("failed to export audit events")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to export audit events", nil)
+		return
+	}
+	defer func() { _ = rows.Close() }()
+
+	filename := fmt.Sprintf("audit-%s.csv", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"id", "created_at", "actor_id", "actor_email", "impersonator_id", "action", "target_type", "target_id", "status", "ip", "request_id", "changes"})
+	for rows.Next() {
+		var ev models.AuditEvent
+		if err := h.db.ScanRows(rows, &ev); err != nil {
+			h.log.WithError(err).Error("failed to scan audit event")
+			break
+		}
+		changes := ""
+		if len(ev.Changes) > 0 {
+			if b, err := json.Marshal(ev.Changes); err == nil {
+				changes = string(b)
+			}
+		}
+		actorID, impersonatorID := "", ""
+		if ev.ActorID != nil {
+			actorID = strconv.FormatUint(*ev.ActorID, 10)
+		}
+		if ev.ImpersonatorID != nil {
+			impersonatorID = strconv.FormatUint(*ev.ImpersonatorID, 10)
+		}
+		record := []string{
+			strconv.FormatUint(ev.ID, 10),
+			ev.CreatedAt.UTC().Format(time.RFC3339),
+			actorID,
+			derefString(ev.ActorEmail),
+			impersonatorID,
+			ev.Action,
+			ev.TargetType,
+			derefString(ev.TargetID),
+			strconv.Itoa(ev.Status),
+			derefString(ev.IP),
+			derefString(ev.RequestID),
+			changes,
+		}
+		for i := range record {
+			record[i] = csvCell(record[i])
+		}
+		_ = w.Write(record)
+	}
+	w.Flush()
+}
+
+// csvCell prefixes values a spreadsheet would evaluate as a formula with a quote
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func (h *AuditHandler) filteredQuery(c *gin.Context) (*gorm.DB, bool) {
+	var f auditFilterParams
+	if err := c.ShouldBindQuery(&f); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_params", "invalid query parameters", err.Error())
+		return nil, false
+	}
+
+	query := h.db.Model(&models.AuditEvent{})
+	if f.ActorID != nil {
+		query = query.Where("actor_id = ?", *f.ActorID)
+	}
+	if f.ImpersonatorID != nil {
+		query = query.Where("impersonator_id = ?", *f.ImpersonatorID)
+	}
+	if f.Action != "" {
+		query = query.Where("action = ?", f.Action)
+	}
+	if f.TargetType != "" {
+		query = query.Where("target_type = ?", f.TargetType)
+	}
+	if f.TargetID != "" {
+		query = query.Where("target_id = ?", f.TargetID)
+	}
+	if f.From != nil {
+		query = query.Where("created_at >= ?", f.From.UTC())
+	}
+	if f.To != nil {
+		query = query.Where("created_at < ?", f.To.UTC())
+	}
+	return query, true
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
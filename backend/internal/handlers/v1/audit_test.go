@@ -0,0 +1,108 @@
+package v1_test
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/audit"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditHandler_RecordsAdminMutations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupUsersDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Partner{}, &models.AuditEvent{}))
+
+	cfg := &config.Config{
+		Auth:     config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret", AccessTokenTTL: time.Hour, RefreshTokenTTL: time.Hour}},
+		Security: config.SecurityConfig{AuditLog: true},
+	}
+	admin := models.User{Email: "admin@corp.tld", Name: "Admin", Role: "admin"}
+	require.NoError(t, db.Create(&admin).Error)
+	pair, err := auth.GenerateTokenPair(cfg, admin.ID, admin.Email, admin.Role, admin.TokenVersion)
+	require.NoError(t, err)
+
+	log := logrus.New()
+	partners := v1.NewPartnersHandler(log, db)
+	h := v1.NewAuditHandler(log, db)
+	r := gin.New()
+	r.Use(middleware.RequestID())
+	g := r.Group("/admin/partners", middleware.AuthRequired(cfg), middleware.Audit(cfg, db, log, "partner", audit.TableSnapshot("partners")))
+	g.POST("", partners.CreatePartner)
+	g.PATCH("/:id", partners.UpdatePartner)
+	g.DELETE("/:id", partners.DeletePartner)
+	r.GET("/admin/audit", h.ListAuditEvents)
+	r.GET("/admin/audit/export", h.ExportAuditEvents)
+
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: pair.AccessToken})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusCreated, call(http.MethodPost, "/admin/partners", `{"name":"ACME","email":"p@acme.tld"}`).Code)
+	require.Equal(t, http.StatusOK, call(http.MethodPatch, "/admin/partners/1", `{"name":"ACME Corp"}`).Code)
+	require.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/admin/partners", `{}`).Code)
+	require.Equal(t, http.StatusOK, call(http.MethodDelete, "/admin/partners/1", "").Code)
+
+	var events []models.AuditEvent
+	require.NoError(t, db.Order("id").Find(&events).Error)
+	require.Len(t, events, 3, "failed requests are not audited")
+
+	assert.Equal(t, "partner.create", events[0].Action)
+	require.NotNil(t, events[0].TargetID)
+	assert.Equal(t, "1", *events[0].TargetID)
+	assert.Equal(t, "ACME", events[0].Changes["name"].After)
+
+	assert.Equal(t, "partner.update", events[1].Action)
+	assert.Equal(t, models.AuditChange{Before: "ACME", After: "ACME Corp"}, events[1].Changes["name"])
+	assert.NotContains(t, events[1].Changes, "email")
+	require.NotNil(t, events[1].ActorID)
+	assert.Equal(t, admin.ID, *events[1].ActorID)
+	assert.NotNil(t, events[1].RequestID)
+	assert.NotNil(t, events[1].IP)
+
+	assert.Equal(t, "partner.delete", events[2].Action)
+	assert.Nil(t, events[2].Changes["name"].After)
+
+	w := call(http.MethodGet, "/admin/audit?action=partner.update", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total":1`)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodGet, "/admin/audit?from=yesterday", "").Code)
+
+	w = call(http.MethodGet, "/admin/audit/export?target_type=partner", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, "action", records[0][5])
+	assert.Equal(t, "partner.create", records[1][5])
+
+	// Values a spreadsheet would evaluate are neutralised
+	email, target := "=HYPERLINK(\"http://evil.tld\")", "@SUM(1+1)"
+	require.NoError(t, db.Create(&models.AuditEvent{Action: "partner.update", TargetType: "formula", ActorEmail: &email, TargetID: &target, Status: http.StatusOK}).Error)
+	w = call(http.MethodGet, "/admin/audit/export?target_type=formula", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	records, err = csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "'"+email, records[1][3])
+	assert.Equal(t, "'"+target, records[1][7])
+	assert.Equal(t, "partner.update", records[1][5])
+}
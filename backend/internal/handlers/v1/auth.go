@@ -11,8 +11,11 @@ import (
 	"time"
 
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/oauth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/password"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/notifications/email"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
 	"github.com/gin-gonic/gin"
@@ -26,33 +29,48 @@ type AuthHandler struct {
 	db     *gorm.DB
 	log    *logrus.Logger
 	mailer email.Mailer
+	oauth  *oauth.Registry
+
+	passwords *password.Policy
 }
 
-func NewAuthHandler(cfg *config.Config, db *gorm.DB, log *logrus.Logger, mailer email.Mailer) *AuthHandler {
-	return &AuthHandler{cfg: cfg, db: db, log: log, mailer: mailer}
+func NewAuthHandler(cfg *config.Config, db *gorm.DB, log *logrus.Logger, mailer email.Mailer, passwords *password.Policy) *AuthHandler {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return &AuthHandler{
+		cfg:    cfg,
+		db:     db,
+		log:    log,
+		mailer: mailer,
+		oauth:  oauth.NewRegistry(cfg.Auth.OAuth, client, log),
+
+		passwords: passwords,
+	}
 }
 
 // Register godoc
 // @Summary      Sign up
-// @Description  Creates a user, sends a verification email, and returns the profile. No tokens are returned; cookies are set after login.
+// @Description  Creates a user with the basic "user" role, sends a verification email, and returns the profile. No tokens are returned; cookies are set after login.
+// @Description  The founder and investor roles are only granted through an invitation. Disabled when auth.registration.invite_only is set.
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
-// @Param        payload body requests.AuthRegisterRequest true "Registration data" Example({"email":"john@doe.tld","name":"John Doe","role":"founder","password":"secret123"})
+// @Param        payload body requests.AuthRegisterRequest true "Registration data" Example({"email":"john@doe.tld","name":"John Doe","password":"secret123"})
 // @Success      201 {object} response.AuthRegisterResponse
 // @Failure      400 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
 // @Failure      409 {object} response.ErrorBody
 // @Failure      500 {object} response.ErrorBody
 // @Router       /auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req struct {
-		Email      string  `json:"email" binding:"required,email"`
-		Name       string  `json:"name" binding:"required"`
-		Role       string  `json:"role" binding:"required"`
-		Password   string  `json:"password" binding:"required,min=6"`
-		ImageURL   *string `json:"image_url,omitempty"`
-		FounderID  *uint64 `json:"founder_id,omitempty"`
-		InvestorID *uint64 `json:"investor_id,omitempty"`
+		Email    string  `json:"email" binding:"required,email"`
+		Name     string  `json:"name" binding:"required"`
+		Password string  `json:"password" binding:"required"`
+		ImageURL *string `json:"image_url,omitempty"`
+	}
+	if h.cfg.Auth.Registration.InviteOnly {
+		response.JSON(c, http.StatusForbidden, gin.H{"code": "registration_closed", "message": "sign-up is by invitation only"})
+		return
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.JSON(c, http.StatusBadRequest, gin.H{"code": 2100, "message": "invalid request payload", "errors": err.Error()})
@@ -70,28 +88,24 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	roleIn := strings.ToLower(strings.TrimSpace(req.Role))
-	switch roleIn {
-	case "investor":
-		req.Role = "investor"
-	case "founder":
-		req.Role = "founder"
-	default:
-		response.JSON(c, http.StatusBadRequest, gin.H{"code": "invalid_role", "message": "role must be 'investor' or 'founder'"})
+	if !acceptablePassword(c, h.passwords, req.Password, req.Email, req.Name) {
 		return
 	}
 
 	_ = h.alignUserSequence()
 
-	hash, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hash, err := h.passwords.Hash(req.Password)
+	if err != nil {
+		h.log.WithError(err).Error("failed to hash password")
+		response.JSON(c, http.StatusInternalServerError, gin.H{"code": "internal_error", "message": "failed to process password"})
+		return
+	}
 	u := models.User{
 		Email:        req.Email,
 		Name:         req.Name,
-		Role:         req.Role,
-		PasswordHash: string(hash),
+		Role:         "user",
+		PasswordHash: hash,
 		ImageURL:     req.ImageURL,
-		FounderID:    req.FounderID,
-		InvestorID:   req.InvestorID,
 	}
 	if err := h.db.Create(&u).Error; err != nil {
 		h.log.WithError(err).Error("db create user")
@@ -99,21 +113,6 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	if u.Role == "investor" && u.InvestorID == nil {
-		_ = h.alignInvestorSequence()
-		inv := models.Investor{Name: u.Name, Email: u.Email}
-		if err := h.db.Create(&inv).Error; err != nil {
-			h.log.WithError(err).Warn("failed to auto-create investor profile")
-		} else if inv.ID != 0 {
-			if err := h.db.Model(&u).Update("investor_id", inv.ID).Error; err != nil {
-				h.log.WithError(err).Warn("failed to assign investor_id to user")
-			} else {
-				id := inv.ID
-				u.InvestorID = &id
-			}
-		}
-	}
-
 	if h.mailer != nil {
 		if token, err := h.createOneTimeToken(c, u.ID, "verify", h.cfg.Auth.EmailVerificationTTL); err != nil {
 			h.log.WithError(err).Warn("createOneTimeToken verify failed")
@@ -176,7 +175,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		response.JSON(c, http.StatusForbidden, gin.H{"code": "email_not_verified", "message": "please verify your email before logging in"})
 		return
 	}
-	pair, err := auth.GenerateTokenPair(h.cfg, u.ID, u.Email, u.Role)
+	pair, err := auth.GenerateTokenPair(h.cfg, u.ID, u.Email, u.Role, u.TokenVersion)
 	if err != nil {
 		h.log.WithError(err).Error("GenerateTokenPair")
 		response.JSON(c, http.StatusInternalServerError, gin.H{"code": "internal_error", "message": "failed to issue tokens"})
@@ -216,13 +215,18 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		response.JSON(c, http.StatusUnauthorized, gin.H{"code": "invalid_token", "message": "user no longer exists"})
 		return
 	}
+	if claims.TokenVersion != u.TokenVersion {
+		h.clearAuthCookies(c)
+		response.JSON(c, http.StatusUnauthorized, gin.H{"code": "session_revoked", "message": "session has been revoked, please sign in again"})
+		return
+	}
 
 	if !u.EmailVerified {
 		response.JSON(c, http.StatusUnauthorized, gin.H{"code": "email_not_verified", "message": "email not verified"})
 		return
 	}
 
-	pair, err := auth.GenerateTokenPair(h.cfg, u.ID, u.Email, u.Role)
+	pair, err := auth.GenerateTokenPair(h.cfg, u.ID, u.Email, u.Role, u.TokenVersion)
 	if err != nil {
 		h.log.WithError(err).Error("GenerateTokenPair")
 		response.JSON(c, http.StatusInternalServerError, gin.H{"code": "internal_error", "message": "failed to issue tokens"})
@@ -328,7 +332,7 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 
 // ResetPassword godoc
 // @Summary      Reset password
-// @Description  Updates the password using a valid reset token.
+// @Description  Updates the password using a valid reset token and signs out every session of the user.
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
@@ -342,26 +346,42 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	var req struct {
 		Token       string `json:"token" binding:"required"`
-		NewPassword string `json:"new_password" binding:"required,min=6"`
+		NewPassword string `json:"new_password" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.JSON(c, http.StatusBadRequest, gin.H{"code": 2100, "message": "invalid request payload", "errors": err.Error()})
 		return
 	}
-	userID, err := h.consumeOneTimeToken(c, req.Token, "reset")
+	// The token is only consumed once the new password is accepted, so that a rejected password can be retried
+	at, err := h.findOneTimeToken(c, req.Token, "reset")
 	if err != nil {
 		response.JSON(c, http.StatusUnauthorized, gin.H{"code": "invalid_token", "message": err.Error()})
 		return
 	}
 	var u models.User
-	if err := h.db.First(&u, userID).Error; err != nil {
+	if err := h.db.First(&u, at.UserID).Error; err != nil {
 		response.JSON(c, http.StatusNotFound, gin.H{"code": "user_not_found", "message": "user not found"})
 		return
 	}
-	hash, _ := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
-	if err := h.db.Model(&u).Updates(map[string]interface{}{
-		"password_hash": string(hash),
-	}).Error; err != nil {
+	if !acceptablePassword(c, h.passwords, req.NewPassword, u.Email, u.Name) {
+		return
+	}
+	if _, err := h.consumeOneTimeTokenRecord(c, req.Token, "reset"); err != nil {
+		response.JSON(c, http.StatusUnauthorized, gin.H{"code": "invalid_token", "message": err.Error()})
+		return
+	}
+	hash, err := h.passwords.Hash(req.NewPassword)
+	if err != nil {
+		h.log.WithError(err).Error("failed to hash password")
+		response.JSON(c, http.StatusInternalServerError, gin.H{"code": "internal_error", "message": "failed to process password"})
+		return
+	}
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&u).Update("password_hash", hash).Error; err != nil {
+			return err
+		}
+		return revokeSessions(tx, &u)
+	}); err != nil {
 		h.log.WithError(err).Error("db update password_hash")
 		response.JSON(c, http.StatusInternalServerError, gin.H{"code": "internal_error", "message": "failed to reset password"})
 		return
@@ -380,6 +400,58 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// CSRFToken godoc
+// @Summary      CSRF token
+// @Description  Issues a CSRF token, set in the csrf_token cookie and returned in the body. Cookie-authenticated POST/PUT/PATCH/DELETE requests must send it in the X-CSRF-Token header.
+// @Tags         Auth
+// @Produce      json
+// @Success      200 {object} response.CSRFTokenResponse
+// @Failure      500 {object} response.ErrorBody
+// @Router       /auth/csrf [get]
+func (h *AuthHandler) CSRFToken(c *gin.Context) {
+	token, err := middleware.NewCSRFToken(h.cfg.Auth.JWT.Secret)
+	if err != nil {
+		h.log.WithError(err).Error("NewCSRFToken")
+		response.JSON(c, http.StatusInternalServerError, gin.H{"code": "internal_error", "message": "failed to issue CSRF token"})
+		return
+	}
+	secure, sameSite := h.cookieAttrs()
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     middleware.CSRFCookieName,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: false,
+		Secure:   secure,
+		SameSite: sameSite,
+		Expires:  time.Now().Add(h.cfg.Auth.JWT.RefreshTokenTTL),
+		MaxAge:   int(h.cfg.Auth.JWT.RefreshTokenTTL.Seconds()),
+	})
+	c.Header("Cache-Control", "no-store")
+	response.JSON(c, http.StatusOK, gin.H{"csrf_token": token})
+}
+
+// ensureInvestorProfile creates and links an investor profile for investors that have none. Failures are logged only
+func (h *AuthHandler) ensureInvestorProfile(u *models.User) {
+	if u.Role != "investor" || u.InvestorID != nil {
+		return
+	}
+	_ = h.alignInvestorSequence()
+	inv := models.Investor{Name: u.Name, Email: u.Email}
+	if err := h.db.Create(&inv).Error; err != nil {
+		h.log.WithError(err).Warn("failed to auto-create investor profile")
+		return
+	}
+	if inv.ID == 0 {
+		return
+	}
+	if err := h.db.Model(u).Update("investor_id", inv.ID).Error; err != nil {
+		h.log.WithError(err).Warn("failed to assign investor_id to user")
+		return
+	}
+	id := inv.ID
+	u.InvestorID = &id
+}
+
 // alignInvestorSequence bumps investors.id sequence above both existing investors.id
 // and any users.investor_id references, to ensure new investors get a fresh ID
 func (h *AuthHandler) alignInvestorSequence() error {
@@ -411,25 +483,50 @@ func (h *AuthHandler) alignUserSequence() error {
 	return nil
 }
 
-// createOneTimeToken creates and stores a one-time token (verify/reset) and returns the plain token string
-func (h *AuthHandler) createOneTimeToken(c *gin.Context, userID uint64, tokenType string, ttl time.Duration) (string, error) {
-	if tokenType != "verify" && tokenType != "reset" {
-		return "", fmt.Errorf("invalid token type")
-	}
+// oneTimeTokenTypes lists the purposes a one-time token can be issued for
+var oneTimeTokenTypes = map[string]struct{}{
+	"verify":       {},
+	"reset":        {},
+	"email_change": {},
+	"magic_link":   {},
+}
 
+// newTokenSecret returns a random secret to send to the user and the hash to store in its place
+func newTokenSecret() (string, string, error) {
 	buf := make([]byte, 32)
 	if _, err := rand.Read(buf); err != nil {
-		return "", fmt.Errorf("rand.Read: %w", err)
+		return "", "", fmt.Errorf("rand.Read: %w", err)
 	}
-
 	secret := hex.EncodeToString(buf)
+	return secret, hashTokenSecret(secret), nil
+}
+
+func hashTokenSecret(secret string) string {
 	sum := sha256.Sum256([]byte(secret))
-	hashHex := hex.EncodeToString(sum[:])
+	return hex.EncodeToString(sum[:])
+}
+
+// createOneTimeToken creates and stores a one-time token and returns the plain token string
+func (h *AuthHandler) createOneTimeToken(c *gin.Context, userID uint64, tokenType string, ttl time.Duration) (string, error) {
+	return h.createOneTimeTokenWithPayload(c, userID, tokenType, ttl, nil)
+}
+
+// createOneTimeTokenWithPayload is createOneTimeToken with a value bound to the token (e.g. the requested new email)
+func (h *AuthHandler) createOneTimeTokenWithPayload(c *gin.Context, userID uint64, tokenType string, ttl time.Duration, payload *string) (string, error) {
+	if _, ok := oneTimeTokenTypes[tokenType]; !ok {
+		return "", fmt.Errorf("invalid token type")
+	}
+
+	secret, hashHex, err := newTokenSecret()
+	if err != nil {
+		return "", err
+	}
 	expiresAt := time.Now().Add(ttl)
 	at := models.AuthToken{
 		UserID:    userID,
 		TokenHash: hashHex,
 		TokenType: tokenType,
+		Payload:   payload,
 		ExpiresAt: expiresAt,
 	}
 
@@ -441,15 +538,35 @@ func (h *AuthHandler) createOneTimeToken(c *gin.Context, userID uint64, tokenTyp
 
 // consumeOneTimeToken validates and deletes a token. Returns the associated userID
 func (h *AuthHandler) consumeOneTimeToken(c *gin.Context, secret string, tokenType string) (uint64, error) {
+	at, err := h.consumeOneTimeTokenRecord(c, secret, tokenType)
+	if err != nil {
+		return 0, err
+	}
+	return at.UserID, nil
+}
+
+// consumeOneTimeTokenRecord validates and deletes a token. Returns the stored token, including its payload
+func (h *AuthHandler) consumeOneTimeTokenRecord(c *gin.Context, secret string, tokenType string) (*models.AuthToken, error) {
+	at, err := h.findOneTimeToken(c, secret, tokenType)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.db.WithContext(c.Request.Context()).Delete(at).Error; err != nil {
+		h.log.WithError(err).Warn("failed to delete consumed token")
+	}
+	return at, nil
+}
+
+// findOneTimeToken validates a token without consuming it, for flows that check the request before using the token
+func (h *AuthHandler) findOneTimeToken(c *gin.Context, secret string, tokenType string) (*models.AuthToken, error) {
 	if secret == "" {
-		return 0, fmt.Errorf("missing token")
+		return nil, fmt.Errorf("missing token")
 	}
-	if tokenType != "verify" && tokenType != "reset" {
-		return 0, fmt.Errorf("invalid token type")
+	if _, ok := oneTimeTokenTypes[tokenType]; !ok {
+		return nil, fmt.Errorf("invalid token type")
 	}
 
-	sum := sha256.Sum256([]byte(secret))
-	hashHex := hex.EncodeToString(sum[:])
+	hashHex := hashTokenSecret(secret)
 	var at models.AuthToken
 	now := time.Now()
 
@@ -458,27 +575,28 @@ func (h *AuthHandler) consumeOneTimeToken(c *gin.Context, secret string, tokenTy
 		First(&at)
 	if tx.Error != nil {
 		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
-			return 0, fmt.Errorf("invalid or expired token")
+			return nil, fmt.Errorf("invalid or expired token")
 		}
-		return 0, fmt.Errorf("db find token: %w", tx.Error)
-	}
-
-	if err := h.db.WithContext(c.Request.Context()).Delete(&at).Error; err != nil {
-		h.log.WithError(err).Warn("failed to delete consumed token")
+		return nil, fmt.Errorf("db find token: %w", tx.Error)
 	}
-	return at.UserID, nil
+	return &at, nil
 }
 
-// setAuthCookies sets access and refresh tokens as HttpOnly cookies
-func (h *AuthHandler) setAuthCookies(c *gin.Context, pair *auth.TokenPair) {
-	// Determine cookie attributes based on environment
+// cookieAttrs determines cookie attributes based on environment.
+// In production-like envs, require Secure and SameSite=None for cross-site usage
+func (h *AuthHandler) cookieAttrs() (bool, http.SameSite) {
 	env := strings.ToLower(strings.TrimSpace(h.cfg.App.Env))
-	// In production-like envs, require Secure and SameSite=None for cross-site usage
 	secure := env == "staging" || env == "production" || env == "prod"
 	sameSite := http.SameSiteLaxMode
 	if secure {
 		sameSite = http.SameSiteNoneMode
 	}
+	return secure, sameSite
+}
+
+// setAuthCookies sets access and refresh tokens as HttpOnly cookies
+func (h *AuthHandler) setAuthCookies(c *gin.Context, pair *auth.TokenPair) {
+	secure, sameSite := h.cookieAttrs()
 
 	http.SetCookie(c.Writer, &http.Cookie{
 		Name:     "access_token",
@@ -502,33 +620,20 @@ func (h *AuthHandler) setAuthCookies(c *gin.Context, pair *auth.TokenPair) {
 	})
 }
 
-// clearAuthCookies deletes auth cookies
+// clearAuthCookies deletes auth cookies, including an admin session kept aside by an impersonation
 func (h *AuthHandler) clearAuthCookies(c *gin.Context) {
-	env := strings.ToLower(strings.TrimSpace(h.cfg.App.Env))
-	secure := env == "staging" || env == "production" || env == "prod"
-	sameSite := http.SameSiteLaxMode
-	if secure {
-		sameSite = http.SameSiteNoneMode
-	}
+	secure, sameSite := h.cookieAttrs()
 	expired := time.Now().Add(-time.Hour)
-	http.SetCookie(c.Writer, &http.Cookie{
-		Name:     "access_token",
-		Value:    "",
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   secure,
-		SameSite: sameSite,
-		Expires:  expired,
-		MaxAge:   -1,
-	})
-	http.SetCookie(c.Writer, &http.Cookie{
-		Name:     "refresh_token",
-		Value:    "",
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   secure,
-		SameSite: sameSite,
-		Expires:  expired,
-		MaxAge:   -1,
-	})
+	for _, name := range []string{"access_token", "refresh_token", impersonatorCookie} {
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     name,
+			Value:    "",
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   secure,
+			SameSite: sameSite,
+			Expires:  expired,
+			MaxAge:   -1,
+		})
+	}
 }
@@ -0,0 +1,316 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const defaultDeletionGracePeriod = 30 * 24 * time.Hour
+
+// RequestEmailChange godoc
+// @Summary      Change my email
+// @Description  Sends a confirmation link to the new address. The email is only changed once the link is used. Accounts with a password must provide it.
+// @Tags         Users
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        payload body requests.EmailChangeRequest true "New email" Example({"new_email":"jane@new.tld","password":"secret123"})
+// @Success      202 {object} response.MessageResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /users/me/email [post]
+func (h *AuthHandler) RequestEmailChange(c *gin.Context) {
+	u, ok := h.selfServiceUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		NewEmail string `json:"new_email" binding:"required,email"`
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+	if !h.checkCurrentPassword(c, u, req.Password) {
+		return
+	}
+
+	newEmail := strings.ToLower(strings.TrimSpace(req.NewEmail))
+	if strings.EqualFold(newEmail, u.Email) {
+		response.JSONError(c, http.StatusBadRequest, "same_email", "new email is the current email", nil)
+		return
+	}
+	taken, err := h.emailTaken(c, newEmail)
+	if err != nil {
+		h.log.WithError(err).Error("failed to check email")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to process request", nil)
+		return
+	}
+	if taken {
+		response.JSONError(c, http.StatusConflict, "email_taken", "email already in use", nil)
+		return
+	}
+	if h.mailer == nil {
+		h.log.Warn("mailer is nil; cannot send email change confirmation")
+		response.JSONError(c, http.StatusServiceUnavailable, "mailer_unavailable", "email delivery is not configured", nil)
+		return
+	}
+
+	// Only the latest request can be confirmed
+	if err := h.db.Where("user_id = ? AND token_type = ?", u.ID, "email_change").Delete(&models.AuthToken{}).Error; err != nil {
+		h.log.WithError(err).Warn("failed to delete previous email change tokens")
+	}
+	token, err := h.createOneTimeTokenWithPayload(c, u.ID, "email_change", h.cfg.Auth.EmailVerificationTTL, &newEmail)
+	if err != nil {
+		h.log.WithError(err).Error("createOneTimeToken email_change failed")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to process request", nil)
+		return
+	}
+
+	link := fmt.Sprintf("%s/confirm-email-change?token=%s", strings.TrimRight(h.cfg.App.BaseURL, "/"), token)
+	body := fmt.Sprintf("<p>Hello %s,</p><p>Confirm that this address should be used for your account by clicking the link below (valid for %d hours):</p><p><a href=\"%s\">Confirm my new email</a></p>",
+		html.EscapeString(u.Name), int(h.cfg.Auth.EmailVerificationTTL.Hours()), link)
+	if err := h.mailer.Send(c.Request.Context(), newEmail, "Confirm your new email address", body); err != nil {
+		h.log.WithError(err).Warn("mailer.Send email change confirmation failed")
+	}
+	h.notify(c, u.Email, "Email change requested",
+		fmt.Sprintf("<p>Hello %s,</p><p>A change of the email address of your account to %s was requested. If this was not you, change your password now.</p>",
+			html.EscapeString(u.Name), html.EscapeString(newEmail)))
+
+	response.JSON(c, http.StatusAccepted, gin.H{"message": "a confirmation link was sent to the new address"})
+}
+
+// ConfirmEmailChange godoc
+// @Summary      Confirm email change
+// @Description  Applies a pending email change using the token sent to the new address.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        token   query string false "Confirmation token"
+// @Param        payload body requests.AuthVerifyRequest false "Token in body" Example({"token":"<token>"})
+// @Success      200 {object} response.MessageResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /auth/email-change/confirm [get]
+// @Router       /auth/email-change/confirm [post]
+func (h *AuthHandler) ConfirmEmailChange(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		var body struct {
+			Token string `json:"token"`
+		}
+		_ = c.ShouldBindJSON(&body)
+		token = body.Token
+	}
+
+	at, err := h.consumeOneTimeTokenRecord(c, token, "email_change")
+	if err != nil {
+		response.JSONError(c, http.StatusUnauthorized, "invalid_token", err.Error(), nil)
+		return
+	}
+	if at.Payload == nil || *at.Payload == "" {
+		response.JSONError(c, http.StatusBadRequest, "invalid_token", "token has no pending email", nil)
+		return
+	}
+	newEmail := *at.Payload
+
+	var u models.User
+	if err := h.db.First(&u, at.UserID).Error; err != nil {
+		response.JSONError(c, http.StatusUnauthorized, "invalid_token", "user no longer exists", nil)
+		return
+	}
+	taken, err := h.emailTaken(c, newEmail)
+	if err != nil {
+		h.log.WithError(err).Error("failed to check email")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to process request", nil)
+		return
+	}
+	if taken {
+		response.JSONError(c, http.StatusConflict, "email_taken", "email already in use", nil)
+		return
+	}
+
+	// Tokens issued before carry the old email, so every session is signed out
+	oldEmail := u.Email
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&u).Updates(map[string]interface{}{
+			"email":          newEmail,
+			"email_verified": true,
+		}).Error; err != nil {
+			return err
+		}
+		return revokeSessions(tx, &u)
+	}); err != nil {
+		h.log.WithError(err).Error("db update email")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to change email", nil)
+		return
+	}
+
+	h.notify(c, oldEmail, "Your email address was changed",
+		fmt.Sprintf("<p>Hello %s,</p><p>The email address of your account is now %s and your sessions were signed out. If this was not you, contact support.</p>",
+			html.EscapeString(u.Name), html.EscapeString(newEmail)))
+	response.JSON(c, http.StatusOK, gin.H{"message": "email updated"})
+}
+
+// DeleteMe godoc
+// @Summary      Delete my account
+// @Description  Schedules the deletion of the current account and signs out every session. After the grace period the account is anonymized: messages are blanked and founder links removed. Accounts with a password must provide it.
+// @Tags         Users
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        payload body requests.AccountDeleteRequest false "Current password" Example({"password":"secret123"})
+// @Success      202 {object} response.AccountDeletionResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /users/me [delete]
+func (h *AuthHandler) DeleteMe(c *gin.Context) {
+	u, ok := h.selfServiceUser(c)
+	if !ok {
+		return
+	}
+	if u.DeletionScheduledAt != nil {
+		response.JSONError(c, http.StatusConflict, "deletion_pending", "account deletion is already scheduled", gin.H{"deletion_scheduled_at": u.DeletionScheduledAt})
+		return
+	}
+
+	var req struct {
+		Password string `json:"password"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+			return
+		}
+	}
+	if !h.checkCurrentPassword(c, u, req.Password) {
+		return
+	}
+
+	grace := h.cfg.Auth.AccountDeletion.GracePeriod
+	if grace <= 0 {
+		grace = defaultDeletionGracePeriod
+	}
+	scheduledAt := time.Now().UTC().Add(grace)
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(u).Update("deletion_scheduled_at", scheduledAt).Error; err != nil {
+			return err
+		}
+		return revokeSessions(tx, u)
+	}); err != nil {
+		h.log.WithError(err).Error("db schedule account deletion")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to schedule deletion", nil)
+		return
+	}
+
+	h.notify(c, u.Email, "Your account will be deleted",
+		fmt.Sprintf("<p>Hello %s,</p><p>Your account will be deleted on %s. Sign in and cancel the deletion before that date to keep it.</p>",
+			html.EscapeString(u.Name), scheduledAt.Format("January 2, 2006")))
+	h.clearAuthCookies(c)
+	response.JSON(c, http.StatusAccepted, gin.H{
+		"message":               "account deletion scheduled",
+		"deletion_scheduled_at": scheduledAt,
+	})
+}
+
+// CancelDeletion godoc
+// @Summary      Cancel account deletion
+// @Description  Cancels a scheduled deletion of the current account during its grace period.
+// @Tags         Users
+// @Security     CookieAuth
+// @Produce      json
+// @Success      200 {object} response.MessageResponse
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /users/me/deletion/cancel [post]
+func (h *AuthHandler) CancelDeletion(c *gin.Context) {
+	u, ok := h.selfServiceUser(c)
+	if !ok {
+		return
+	}
+	if u.DeletionScheduledAt == nil {
+		response.JSONError(c, http.StatusConflict, "no_deletion_pending", "no account deletion is scheduled", nil)
+		return
+	}
+	if err := h.db.Model(u).Update("deletion_scheduled_at", nil).Error; err != nil {
+		h.log.WithError(err).Error("db cancel account deletion")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to cancel deletion", nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"message": "account deletion cancelled"})
+}
+
+// selfServiceUser loads the authenticated user. Account changes are refused during an impersonation
+func (h *AuthHandler) selfServiceUser(c *gin.Context) (*models.User, bool) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return nil, false
+	}
+	if claims.Impersonating() {
+		response.JSONError(c, http.StatusForbidden, "forbidden", "account settings cannot be changed while impersonating", nil)
+		return nil, false
+	}
+	var u models.User
+	if err := h.db.First(&u, claims.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusUnauthorized, "unauthorized", "user no longer exists", nil)
+			return nil, false
+		}
+		h.log.WithError(err).Error("failed to fetch current user")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve profile", nil)
+		return nil, false
+	}
+	return &u, true
+}
+
+// checkCurrentPassword re-authenticates sensitive requests. Password-less accounts (social login) have nothing to check
+func (h *AuthHandler) checkCurrentPassword(c *gin.Context, u *models.User, password string) bool {
+	if u.PasswordHash == "" {
+		return true
+	}
+	if password == "" || bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+		response.JSONError(c, http.StatusUnauthorized, "invalid_credentials", "current password is incorrect", nil)
+		return false
+	}
+	return true
+}
+
+func (h *AuthHandler) emailTaken(c *gin.Context, email string) (bool, error) {
+	var count int64
+	err := h.db.WithContext(c.Request.Context()).Model(&models.User{}).Where("LOWER(email) = ?", strings.ToLower(email)).Count(&count).Error
+	return count > 0, err
+}
+
+// notify sends an informational email, logging failures; it is a no-op without a mailer
+func (h *AuthHandler) notify(c *gin.Context, to, subject, body string) {
+	if h.mailer == nil {
+		return
+	}
+	if err := h.mailer.Send(c.Request.Context(), to, subject, body); err != nil {
+		h.log.WithError(err).WithField("subject", subject).Warn("mailer.Send notification failed")
+	}
+}
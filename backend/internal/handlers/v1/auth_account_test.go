@@ -0,0 +1,145 @@
+package v1_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/accounts"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/password"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type sentMail struct {
+	To, Subject, Body string
+}
+
+// recordingMailer keeps sent emails in memory
+type recordingMailer struct {
+	sent []sentMail
+}
+
+func (m *recordingMailer) Send(_ context.Context, to, subject, body string) error {
+	m.sent = append(m.sent, sentMail{To: to, Subject: subject, Body: body})
+	return nil
+}
+
+func (m *recordingMailer) last(to string) *sentMail {
+	for i := len(m.sent) - 1; i >= 0; i-- {
+		if m.sent[i].To == to {
+			return &m.sent[i]
+		}
+	}
+	return nil
+}
+
+var tokenLinkPattern = regexp.MustCompile(`token=([0-9a-f]+)`)
+
+func TestAuthHandler_EmailChangeAndDeletion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupUsersDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AuthToken{}, &models.UserIdentity{}, &models.Founder{}, &models.Message{}, &models.MessageEdit{}, &models.MessageAttachment{}))
+
+	cfg := &config.Config{
+		App: config.AppConfig{BaseURL: "http://app.local"},
+		Auth: config.AuthConfig{
+			JWT:                  config.JWTConfig{Secret: "test-secret", AccessTokenTTL: time.Hour, RefreshTokenTTL: time.Hour},
+			EmailVerificationTTL: time.Hour,
+			AccountDeletion:      config.AccountDeletionConfig{GracePeriod: time.Hour},
+		},
+	}
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	u := models.User{Email: "jane@old.tld", Name: "Jane", Role: "founder", PasswordHash: string(hash), EmailVerified: true}
+	require.NoError(t, db.Create(&u).Error)
+	require.NoError(t, db.Create(&models.User{Email: "taken@corp.tld", Name: "Other", Role: "user"}).Error)
+	pair, err := auth.GenerateTokenPair(cfg, u.ID, u.Email, u.Role, u.TokenVersion)
+	require.NoError(t, err)
+	access := &http.Cookie{Name: "access_token", Value: pair.AccessToken}
+
+	mailer := &recordingMailer{}
+	h := v1.NewAuthHandler(cfg, db, logrus.New(), mailer, password.NewPolicy(cfg.Auth.Password, nil))
+	r := gin.New()
+	r.GET("/auth/email-change/confirm", h.ConfirmEmailChange)
+	me := r.Group("/users/me", middleware.AuthRequired(cfg))
+	me.POST("/email", h.RequestEmailChange)
+	me.DELETE("", h.DeleteMe)
+	me.POST("/deletion/cancel", h.CancelDeletion)
+
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(access)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, "/users/me/email", `{"new_email":"jane@new.tld","password":"wrong"}`).Code)
+	assert.Equal(t, http.StatusConflict, call(http.MethodPost, "/users/me/email", `{"new_email":"taken@corp.tld","password":"secret123"}`).Code)
+
+	w := call(http.MethodPost, "/users/me/email", `{"new_email":"Jane@New.tld","password":"secret123"}`)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	assert.NotNil(t, mailer.last("jane@old.tld"), "the current address is warned")
+	confirmation := mailer.last("jane@new.tld")
+	require.NotNil(t, confirmation)
+	m := tokenLinkPattern.FindStringSubmatch(confirmation.Body)
+	require.Len(t, m, 2)
+
+	// Nothing changes until the link is used, and the token is single-use
+	require.NoError(t, db.First(&u, u.ID).Error)
+	assert.Equal(t, "jane@old.tld", u.Email)
+	version := u.TokenVersion
+	w = call(http.MethodGet, "/auth/email-change/confirm?token="+url.QueryEscape(m[1]), "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, db.First(&u, u.ID).Error)
+	assert.Equal(t, "jane@new.tld", u.Email)
+	assert.Equal(t, version+1, u.TokenVersion, "sessions carrying the old email are revoked")
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/auth/email-change/confirm?token="+m[1], "").Code)
+
+	// Deletion is scheduled, cancellable, then anonymizes the account once the grace period is over
+	assert.Equal(t, http.StatusConflict, call(http.MethodPost, "/users/me/deletion/cancel", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodDelete, "/users/me", `{"password":"nope"}`).Code)
+	require.Equal(t, http.StatusAccepted, call(http.MethodDelete, "/users/me", `{"password":"secret123"}`).Code)
+	assert.Equal(t, http.StatusConflict, call(http.MethodDelete, "/users/me", `{"password":"secret123"}`).Code)
+	require.NoError(t, db.First(&u, u.ID).Error)
+	assert.Equal(t, version+2, u.TokenVersion, "every session is signed out")
+	require.Equal(t, http.StatusOK, call(http.MethodPost, "/users/me/deletion/cancel", "").Code)
+	require.Equal(t, http.StatusAccepted, call(http.MethodDelete, "/users/me", `{"password":"secret123"}`).Code)
+
+	require.NoError(t, db.Create(&models.Founder{UserID: u.ID, StartupID: 7}).Error)
+	require.NoError(t, db.Create(&models.Message{ConversationID: 1, SenderID: u.ID, Content: "my phone is 0600000000"}).Error)
+
+	n, err := accounts.PurgeDue(context.Background(), db, time.Now().UTC())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "grace period not over yet")
+	n, err = accounts.PurgeDue(context.Background(), db, time.Now().UTC().Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.NoError(t, db.First(&u, u.ID).Error)
+	assert.Equal(t, accounts.DeletedUserName, u.Name)
+	assert.NotContains(t, u.Email, "jane")
+	assert.Empty(t, u.PasswordHash)
+	assert.NotNil(t, u.AnonymizedAt)
+	assert.Equal(t, version+4, u.TokenVersion)
+	var msg models.Message
+	require.NoError(t, db.First(&msg).Error)
+	assert.Equal(t, accounts.DeletedMessageContent, msg.Content)
+	var founders int64
+	db.Model(&models.Founder{}).Where("user_id = ?", u.ID).Count(&founders)
+	assert.Zero(t, founders)
+}
@@ -0,0 +1,188 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// impersonatorCookie keeps the admin's refresh token while they act as another user
+const impersonatorCookie = "impersonator_token"
+
+const defaultImpersonationTTL = 15 * time.Minute
+
+// StartImpersonation godoc
+// @Summary      Impersonate user
+// @Description  Replaces the admin session with a short-lived session of the target user. The admin's session is kept aside and restored by /auth/impersonation/stop. Users who can impersonate cannot be impersonated.
+// @Tags         Users
+// @Security     CookieAuth
+// @Produce      json
+// @Param        id path int true "User ID"
+// @Success      200 {object} response.ImpersonationResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/users/{id}/impersonate [post]
+func (h *AuthHandler) StartImpersonation(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	if claims.Impersonating() {
+		response.JSONError(c, http.StatusConflict, "already_impersonating", "stop the current impersonation first", nil)
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_id", "invalid user id", nil)
+		return
+	}
+	if targetID == claims.UserID {
+		response.JSONError(c, http.StatusBadRequest, "invalid_target", "cannot impersonate yourself", nil)
+		return
+	}
+
+	var target models.User
+	if err := h.db.First(&target, targetID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusNotFound, "not_found", "user not found", nil)
+			return
+		}
+		h.log.WithError(err).Error("failed to fetch user to impersonate")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve user", nil)
+		return
+	}
+
+	privileged, err := auth.RoleHasPermission(c.Request.Context(), h.db, target.Role, auth.PermUsersImpersonate)
+	if err != nil {
+		h.log.WithError(err).Error("failed to check target permissions")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to check permissions", nil)
+		return
+	}
+	if privileged {
+		response.JSONError(c, http.StatusForbidden, "forbidden", "users who can impersonate cannot be impersonated", nil)
+		return
+	}
+
+	ttl := h.impersonationTTL()
+	token, expiresAt, err := auth.GenerateImpersonationToken(h.cfg, target.ID, target.Email, target.Role, claims.UserID, claims.Email, ttl)
+	if err != nil {
+		h.log.WithError(err).Error("GenerateImpersonationToken")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to issue token", nil)
+		return
+	}
+
+	h.clearAuthCookies(c)
+	secure, sameSite := h.cookieAttrs()
+	if refresh, err := c.Cookie("refresh_token"); err == nil && refresh != "" {
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     impersonatorCookie,
+			Value:    refresh,
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   secure,
+			SameSite: sameSite,
+			Expires:  time.Now().Add(h.cfg.Auth.JWT.RefreshTokenTTL),
+			MaxAge:   int(h.cfg.Auth.JWT.RefreshTokenTTL.Seconds()),
+		})
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     "access_token",
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: sameSite,
+		Expires:  expiresAt,
+		MaxAge:   int(ttl.Seconds()),
+	})
+
+	h.log.WithFields(logrus.Fields{"admin_id": claims.UserID, "user_id": target.ID}).Info("impersonation started")
+	response.JSON(c, http.StatusOK, gin.H{
+		"data": gin.H{
+			"user":            target,
+			"impersonator_id": claims.UserID,
+			"expires_at":      expiresAt.UTC(),
+			"read_only":       h.cfg.Auth.Impersonation.ReadOnly,
+		},
+	})
+}
+
+// StopImpersonation godoc
+// @Summary      Stop impersonation
+// @Description  Ends the impersonation session and restores the admin's session when it is still valid. Otherwise all auth cookies are cleared and the admin has to sign in again.
+// @Tags         Auth
+// @Security     CookieAuth
+// @Produce      json
+// @Success      200 {object} response.ImpersonationStopResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /auth/impersonation/stop [post]
+func (h *AuthHandler) StopImpersonation(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	if !claims.Impersonating() {
+		response.JSONError(c, http.StatusBadRequest, "not_impersonating", "no impersonation in progress", nil)
+		return
+	}
+
+	h.clearAuthCookies(c)
+	h.log.WithFields(logrus.Fields{"admin_id": claims.ImpersonatorID, "user_id": claims.UserID}).Info("impersonation stopped")
+
+	admin, ok := h.impersonatorFromCookie(c, claims.ImpersonatorID)
+	if !ok {
+		response.JSON(c, http.StatusOK, gin.H{"message": "impersonation stopped", "restored": false})
+		return
+	}
+
+	pair, err := auth.GenerateTokenPair(h.cfg, admin.ID, admin.Email, admin.Role, admin.TokenVersion)
+	if err != nil {
+		h.log.WithError(err).Error("GenerateTokenPair")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to issue tokens", nil)
+		return
+	}
+	h.setAuthCookies(c, pair)
+	response.JSON(c, http.StatusOK, gin.H{"message": "impersonation stopped", "restored": true})
+}
+
+// impersonatorFromCookie returns the admin whose refresh token was kept aside when the impersonation started
+func (h *AuthHandler) impersonatorFromCookie(c *gin.Context, adminID uint64) (*models.User, bool) {
+	raw, err := c.Cookie(impersonatorCookie)
+	if err != nil || raw == "" {
+		return nil, false
+	}
+	rc, err := auth.ParseClaims(h.cfg, raw)
+	if err != nil || rc.Type != "refresh" || rc.UserID != adminID {
+		return nil, false
+	}
+	var admin models.User
+	if err := h.db.First(&admin, adminID).Error; err != nil || rc.TokenVersion != admin.TokenVersion {
+		return nil, false
+	}
+	return &admin, true
+}
+
+func (h *AuthHandler) impersonationTTL() time.Duration {
+	if h.cfg.Auth.Impersonation.TTL > 0 {
+		return h.cfg.Auth.Impersonation.TTL
+	}
+	return defaultImpersonationTTL
+}
@@ -0,0 +1,116 @@
+package v1_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/password"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthHandler_Impersonation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupUsersDB(t)
+	seedRoles(t, db)
+	require.NoError(t, db.AutoMigrate(&models.AuditEvent{}))
+
+	cfg := &config.Config{
+		Auth: config.AuthConfig{
+			JWT:           config.JWTConfig{Secret: "test-secret", AccessTokenTTL: time.Hour, RefreshTokenTTL: 24 * time.Hour},
+			Impersonation: config.ImpersonationConfig{TTL: 5 * time.Minute, ReadOnly: true},
+		},
+		Security: config.SecurityConfig{AuditLog: true},
+	}
+	admin := models.User{Email: "admin@corp.tld", Name: "Admin", Role: "admin", EmailVerified: true}
+	founder := models.User{Email: "founder@corp.tld", Name: "Founder", Role: "founder", EmailVerified: true}
+	require.NoError(t, db.Create(&[]*models.User{&admin, &founder}).Error)
+	pair, err := auth.GenerateTokenPair(cfg, admin.ID, admin.Email, admin.Role, admin.TokenVersion)
+	require.NoError(t, err)
+
+	log := logrus.New()
+	passwords := password.NewPolicy(cfg.Auth.Password, log)
+	h := v1.NewAuthHandler(cfg, db, log, nil, passwords)
+	users := v1.NewUsersHandler(db, log, nil, passwords)
+	r := gin.New()
+	r.POST("/admin/users/:id/impersonate", middleware.AuthRequired(cfg), middleware.RequirePermission(db, auth.PermUsersImpersonate),
+		middleware.AuditAction(cfg, db, log, "user.impersonate_start", "user"), h.StartImpersonation)
+	r.POST("/auth/impersonation/stop", middleware.ImpersonationAuth(cfg), h.StopImpersonation)
+	r.GET("/users/me", middleware.AuthRequired(cfg), users.GetMe)
+	r.PATCH("/users/me", middleware.AuthRequired(cfg), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	call := func(method, path string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for _, ck := range cookies {
+			req.AddCookie(ck)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	cookie := func(w *httptest.ResponseRecorder, name string) *http.Cookie {
+		var found *http.Cookie
+		for _, ck := range w.Result().Cookies() {
+			if ck.Name == name {
+				found = ck
+			}
+		}
+		return found
+	}
+	adminAccess := &http.Cookie{Name: "access_token", Value: pair.AccessToken}
+	adminRefresh := &http.Cookie{Name: "refresh_token", Value: pair.RefreshToken}
+
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/admin/users/1/impersonate", adminAccess).Code)
+	assert.Equal(t, http.StatusNotFound, call(http.MethodPost, "/admin/users/999/impersonate", adminAccess).Code)
+
+	w := call(http.MethodPost, "/admin/users/2/impersonate", adminAccess, adminRefresh)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	access := cookie(w, "access_token")
+	kept := cookie(w, "impersonator_token")
+	require.NotNil(t, access)
+	require.NotNil(t, kept)
+	assert.Equal(t, pair.RefreshToken, kept.Value)
+
+	// The founder is seen by handlers, with the admin attached
+	w = call(http.MethodGet, "/users/me", access)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"email":"founder@corp.tld"`)
+	assert.Contains(t, w.Body.String(), `"impersonator_email":"admin@corp.tld"`)
+
+	// Writes are blocked in read-only mode
+	w = call(http.MethodPatch, "/users/me", access)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "impersonation_read_only")
+
+	var ev models.AuditEvent
+	require.NoError(t, db.Where("action = ?", "user.impersonate_start").First(&ev).Error)
+	assert.Equal(t, admin.ID, *ev.ActorID)
+	assert.Equal(t, "2", *ev.TargetID)
+
+	// A plain session cannot stop an impersonation
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/auth/impersonation/stop", adminAccess).Code)
+
+	w = call(http.MethodPost, "/auth/impersonation/stop", access, kept)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"restored":true`)
+	restored := cookie(w, "access_token")
+	require.NotNil(t, restored)
+	claims, err := auth.ParseClaims(cfg, restored.Value)
+	require.NoError(t, err)
+	assert.Equal(t, admin.ID, claims.UserID)
+	assert.False(t, claims.Impersonating())
+
+	// Admins cannot be impersonated
+	other := models.User{Email: "root@corp.tld", Name: "Root", Role: "admin"}
+	require.NoError(t, db.Create(&other).Error)
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, "/admin/users/3/impersonate", adminAccess).Code)
+}
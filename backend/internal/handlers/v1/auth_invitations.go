@@ -0,0 +1,545 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/http/pagination"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const defaultInvitationTTL = 7 * 24 * time.Hour
+
+// errInvitationTaken is returned when an invitation was accepted or revoked concurrently
+var errInvitationTaken = errors.New("invitation is no longer pending")
+
+// CreateInvitation godoc
+// @Summary      Invite a user
+// @Description  Emails an invitation to join with a role. Holders of users.invite can invite any role, optionally linked to a startup (founder role, required) or an investor profile (investor role).
+// @Description  Founders can invite other founders to their own startups.
+// @Tags         Invitations
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        payload body requests.InvitationCreateRequest true "Invitation" Example({"email":"jane@startup.tld","role":"founder","startup_id":3})
+// @Success      201 {object} response.InvitationObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Failure      503 {object} response.ErrorBody
+// @Router       /invitations [post]
+func (h *AuthHandler) CreateInvitation(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+
+	var req struct {
+		Email      string  `json:"email" binding:"required,email"`
+		Role       string  `json:"role" binding:"required,max=50"`
+		StartupID  *uint64 `json:"startup_id,omitempty"`
+		InvestorID *uint64 `json:"investor_id,omitempty"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+
+	privileged, err := auth.UserHasPermission(c.Request.Context(), h.db, claims.UserID, auth.PermUsersInvite)
+	if err != nil {
+		h.log.WithError(err).Error("failed to check permissions")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to check permissions", nil)
+		return
+	}
+	if !privileged {
+		if role != "founder" || req.StartupID == nil {
+			response.JSONError(c, http.StatusForbidden, "forbidden", "founders can only invite founders to their startup", nil)
+			return
+		}
+		var count int64
+		if err := h.db.Model(&models.Founder{}).
+			Where("user_id = ? AND startup_id = ?", claims.UserID, *req.StartupID).
+			Count(&count).Error; err != nil || count == 0 {
+			response.JSONError(c, http.StatusForbidden, "forbidden", "you are not a founder of this startup", nil)
+			return
+		}
+	}
+
+	if ok, err := auth.RoleExists(c.Request.Context(), h.db, role); err != nil {
+		h.log.WithError(err).Error("failed to check role")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to check role", nil)
+		return
+	} else if !ok {
+		response.JSONError(c, http.StatusBadRequest, "unknown_role", "unknown role", gin.H{"role": role})
+		return
+	}
+	switch {
+	case role == "founder" && req.StartupID == nil:
+		response.JSONError(c, http.StatusBadRequest, "missing_startup_id", "startup_id is required when inviting a founder", nil)
+		return
+	case role != "founder" && req.StartupID != nil:
+		response.JSONError(c, http.StatusBadRequest, "invalid_target", "startup_id is only allowed for the founder role", nil)
+		return
+	case role != "investor" && req.InvestorID != nil:
+		response.JSONError(c, http.StatusBadRequest, "invalid_target", "investor_id is only allowed for the investor role", nil)
+		return
+	}
+	if req.StartupID != nil {
+		if err := h.db.First(&models.Startup{}, *req.StartupID).Error; err != nil {
+			response.JSONError(c, http.StatusNotFound, "not_found", "startup not found", nil)
+			return
+		}
+		var members int64
+		if err := h.db.Model(&models.Founder{}).
+			Joins("JOIN users ON users.id = founders.user_id").
+			Where("founders.startup_id = ? AND LOWER(users.email) = ?", *req.StartupID, email).
+			Count(&members).Error; err != nil {
+			h.log.WithError(err).Error("failed to check founders")
+			response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to process request", nil)
+			return
+		}
+		if members > 0 {
+			response.JSONError(c, http.StatusConflict, "already_member", "this user is already a founder of the startup", nil)
+			return
+		}
+	}
+	if req.InvestorID != nil {
+		if err := h.db.First(&models.Investor{}, *req.InvestorID).Error; err != nil {
+			response.JSONError(c, http.StatusNotFound, "not_found", "investor not found", nil)
+			return
+		}
+	}
+
+	now := time.Now().UTC()
+	pending := h.db.Model(&models.Invitation{}).
+		Where("LOWER(email) = ? AND role = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", email, role, now)
+	if req.StartupID != nil {
+		pending = pending.Where("startup_id = ?", *req.StartupID)
+	} else {
+		pending = pending.Where("startup_id IS NULL")
+	}
+	var duplicates int64
+	if err := pending.Count(&duplicates).Error; err != nil {
+		h.log.WithError(err).Error("failed to check pending invitations")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to process request", nil)
+		return
+	}
+	if duplicates > 0 {
+		response.JSONError(c, http.StatusConflict, "invitation_pending", "a pending invitation already exists for this email", nil)
+		return
+	}
+	if h.mailer == nil {
+		h.log.Warn("mailer is nil; cannot send invitation")
+		response.JSONError(c, http.StatusServiceUnavailable, "mailer_unavailable", "email delivery is not configured", nil)
+		return
+	}
+
+	secret, hash, err := newTokenSecret()
+	if err != nil {
+		h.log.WithError(err).Error("newTokenSecret")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to create invitation", nil)
+		return
+	}
+	inv := models.Invitation{
+		Email:      email,
+		Role:       role,
+		StartupID:  req.StartupID,
+		InvestorID: req.InvestorID,
+		TokenHash:  hash,
+		InvitedBy:  claims.UserID,
+		ExpiresAt:  now.Add(h.invitationTTL()),
+	}
+	if err := h.db.Create(&inv).Error; err != nil {
+		h.log.WithError(err).Error("failed to create invitation")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to create invitation", nil)
+		return
+	}
+	inv.Status = inv.CurrentStatus(now)
+
+	link := fmt.Sprintf("%s/accept-invitation?token=%s", strings.TrimRight(h.cfg.App.BaseURL, "/"), secret)
+	body := fmt.Sprintf("<p>Hello,</p><p>%s invited you to join as %s. Click the link below to accept (valid until %s):</p><p><a href=\"%s\">Accept the invitation</a></p>",
+		html.EscapeString(claims.Email), html.EscapeString(role), inv.ExpiresAt.Format("January 2, 2006"), link)
+	if err := h.mailer.Send(c.Request.Context(), email, "You are invited", body); err != nil {
+		h.log.WithError(err).Warn("mailer.Send invitation failed")
+	}
+	response.JSON(c, http.StatusCreated, gin.H{"data": inv})
+}
+
+// ListInvitations godoc
+// @Summary      List invitations
+// @Description  Returns every invitation to holders of users.invite, and the invitations they sent to other users.
+// @Tags         Invitations
+// @Security     CookieAuth
+// @Produce      json
+// @Param        page        query int    false "Page" default(1)
+// @Param        per_page    query int    false "Page size" default(20)
+// @Param        status      query string false "Filter by status" Enums(pending,accepted,revoked,expired)
+// @Param        startup_id  query int    false "Filter by startup"
+// @Success      200 {object} response.InvitationListResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /invitations [get]
+func (h *AuthHandler) ListInvitations(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	params := pagination.Parse(c)
+
+	privileged, err := auth.UserHasPermission(c.Request.Context(), h.db, claims.UserID, auth.PermUsersInvite)
+	if err != nil {
+		h.log.WithError(err).Error("failed to check permissions")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to check permissions", nil)
+		return
+	}
+	now := time.Now().UTC()
+	query := h.db.Model(&models.Invitation{})
+	if !privileged {
+		query = query.Where("invited_by = ?", claims.UserID)
+	}
+	if v := c.Query("startup_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			response.JSONError(c, http.StatusBadRequest, "invalid_params", "invalid startup_id", nil)
+			return
+		}
+		query = query.Where("startup_id = ?", id)
+	}
+	switch c.Query("status") {
+	case "":
+	case models.InvitationPending:
+		query = query.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", now)
+	case models.InvitationAccepted:
+		query = query.Where("accepted_at IS NOT NULL")
+	case models.InvitationRevoked:
+		query = query.Where("accepted_at IS NULL AND revoked_at IS NOT NULL")
+	case models.InvitationExpired:
+		query = query.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at <= ?", now)
+	default:
+		response.JSONError(c, http.StatusBadRequest, "invalid_params", "invalid status", nil)
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		h.log.WithError(err).Error("failed to count invitations")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to count invitations", nil)
+		return
+	}
+	var invitations []models.Invitation
+	if err := query.Order("created_at DESC, id DESC").
+		Offset((params.Page - 1) * params.PerPage).
+		Limit(params.PerPage).
+		Find(&invitations).Error; err != nil {
+		h.log.WithError(err).Error("failed to list invitations")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve invitations", nil)
+		return
+	}
+	for i := range invitations {
+		invitations[i].Status = invitations[i].CurrentStatus(now)
+	}
+
+	totalPages := (int(total) + params.PerPage - 1) / params.PerPage
+	response.JSON(c, http.StatusOK, gin.H{
+		"data": invitations,
+		"pagination": gin.H{
+			"page":     params.Page,
+			"per_page": params.PerPage,
+			"total":    total,
+			"has_next": params.Page < totalPages,
+			"has_prev": params.Page > 1,
+		},
+	})
+}
+
+// RevokeInvitation godoc
+// @Summary      Revoke invitation
+// @Description  Revokes a pending invitation. Allowed to its sender and to holders of users.invite.
+// @Tags         Invitations
+// @Security     CookieAuth
+// @Produce      json
+// @Param        id path int true "Invitation ID"
+// @Success      200 {object} response.InvitationObjectResponse
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /invitations/{id} [delete]
+func (h *AuthHandler) RevokeInvitation(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	var inv models.Invitation
+	if err := h.db.First(&inv, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusNotFound, "not_found", "invitation not found", nil)
+			return
+		}
+		h.log.WithError(err).Error("failed to fetch invitation")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve invitation", nil)
+		return
+	}
+	if inv.InvitedBy != claims.UserID {
+		ok, err := auth.UserHasPermission(c.Request.Context(), h.db, claims.UserID, auth.PermUsersInvite)
+		if err != nil {
+			h.log.WithError(err).Error("failed to check permissions")
+			response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to check permissions", nil)
+			return
+		}
+		if !ok {
+			response.JSONError(c, http.StatusForbidden, "forbidden", "not your invitation", nil)
+			return
+		}
+	}
+	now := time.Now().UTC()
+	if status := inv.CurrentStatus(now); status != models.InvitationPending {
+		response.JSONError(c, http.StatusConflict, "not_pending", "invitation is "+status, nil)
+		return
+	}
+	if err := h.db.Model(&inv).Update("revoked_at", now).Error; err != nil {
+		h.log.WithError(err).Error("failed to revoke invitation")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to revoke invitation", nil)
+		return
+	}
+	inv.RevokedAt = &now
+	inv.Status = models.InvitationRevoked
+	response.JSON(c, http.StatusOK, gin.H{"data": inv})
+}
+
+// LookupInvitation godoc
+// @Summary      Inspect invitation
+// @Description  Describes the invitation behind a token so that the acceptance page can ask the invitee to sign in (existing account) or to choose a name and password.
+// @Tags         Invitations
+// @Produce      json
+// @Param        token query string true "Invitation token"
+// @Success      200 {object} response.InvitationLookupResponse
+// @Failure      404 {object} response.ErrorBody
+// @Failure      410 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /invitations/lookup [get]
+func (h *AuthHandler) LookupInvitation(c *gin.Context) {
+	inv, ok := h.pendingInvitation(c, c.Query("token"))
+	if !ok {
+		return
+	}
+	var count int64
+	if err := h.db.Model(&models.User{}).Where("LOWER(email) = ?", strings.ToLower(inv.Email)).Count(&count).Error; err != nil {
+		h.log.WithError(err).Error("failed to look up invited account")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve invitation", nil)
+		return
+	}
+
+	data := gin.H{
+		"email":          inv.Email,
+		"role":           inv.Role,
+		"startup_id":     inv.StartupID,
+		"expires_at":     inv.ExpiresAt.UTC(),
+		"account_exists": count > 0,
+	}
+	if inv.StartupID != nil {
+		var startup models.Startup
+		if err := h.db.Select("id", "name").First(&startup, *inv.StartupID).Error; err == nil {
+			data["startup_name"] = startup.Name
+		}
+	}
+	response.JSON(c, http.StatusOK, gin.H{"data": data})
+}
+
+// AcceptInvitation godoc
+// @Summary      Accept invitation
+// @Description  Accepts an invitation and signs the invitee in. Without an account for the invited email, one is created from name and password (checked against the password policy) with the email already verified.
+// @Description  With an existing account, the invitee must be signed in as that account; users with the basic "user" role get the invited role, and accounts holding another role cannot accept (409). The user becomes a founder of the invited startup or is linked to the invited investor profile.
+// @Tags         Invitations
+// @Accept       json
+// @Produce      json
+// @Param        payload body requests.InvitationAcceptRequest true "Acceptance" Example({"token":"<invitation-token>","name":"Jane Doe","password":"c0rrect-Horse-battery"})
+// @Success      200 {object} response.InvitationAcceptResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      410 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /invitations/accept [post]
+func (h *AuthHandler) AcceptInvitation(c *gin.Context) {
+	var req struct {
+		Token    string `json:"token" binding:"required"`
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+	inv, ok := h.pendingInvitation(c, req.Token)
+	if !ok {
+		return
+	}
+	if exists, err := auth.RoleExists(c.Request.Context(), h.db, inv.Role); err != nil || !exists {
+		response.JSONError(c, http.StatusConflict, "invalid_invitation", "the invited role no longer exists", nil)
+		return
+	}
+
+	var u models.User
+	err := h.db.Where("LOWER(email) = ?", strings.ToLower(inv.Email)).First(&u).Error
+	existing := err == nil
+	switch {
+	case existing:
+		claims := middleware.GetClaims(c)
+		if claims == nil || claims.Impersonating() || claims.UserID != u.ID {
+			response.JSONError(c, http.StatusUnauthorized, "login_required", "sign in as "+inv.Email+" to accept this invitation", nil)
+			return
+		}
+		// Accepting never swaps an account's role for another, which could take privileges away or grant them sideways
+		if u.Role != "user" && u.Role != inv.Role {
+			response.JSONError(c, http.StatusConflict, "role_mismatch", "this account already has the "+u.Role+" role", gin.H{"role": u.Role, "invited_role": inv.Role})
+			return
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		name := strings.TrimSpace(req.Name)
+		if name == "" || req.Password == "" {
+			response.JSONError(c, http.StatusBadRequest, "invalid_payload", "name and password are required to create the account", nil)
+			return
+		}
+		if !acceptablePassword(c, h.passwords, req.Password, inv.Email, name) {
+			return
+		}
+		hash, err := h.passwords.Hash(req.Password)
+		if err != nil {
+			h.log.WithError(err).Error("failed to hash password")
+			response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to process password", nil)
+			return
+		}
+		_ = h.alignUserSequence()
+		u = models.User{Email: inv.Email, Name: name, Role: inv.Role, PasswordHash: hash, EmailVerified: true}
+	default:
+		h.log.WithError(err).Error("failed to fetch invited user")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to process request", nil)
+		return
+	}
+
+	now := time.Now().UTC()
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if existing {
+			if u.Role != inv.Role {
+				if err := tx.Model(&u).Update("role", inv.Role).Error; err != nil {
+					return fmt.Errorf("update role: %w", err)
+				}
+			}
+		} else if err := tx.Create(&u).Error; err != nil {
+			return fmt.Errorf("create user: %w", err)
+		}
+
+		if inv.StartupID != nil {
+			var startup models.Startup
+			if err := tx.First(&startup, *inv.StartupID).Error; err != nil {
+				return fmt.Errorf("find startup: %w", err)
+			}
+			var founder models.Founder
+			err := tx.Where("user_id = ? AND startup_id = ?", u.ID, startup.ID).First(&founder).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				created, err := assignFounder(tx, &u, &startup)
+				if err != nil {
+					return err
+				}
+				founder = *created
+			} else if err != nil {
+				return fmt.Errorf("find founder: %w", err)
+			}
+			if u.FounderID == nil {
+				if err := tx.Model(&u).Update("founder_id", founder.ID).Error; err != nil {
+					return fmt.Errorf("link founder: %w", err)
+				}
+			}
+		}
+		if inv.InvestorID != nil {
+			if err := tx.Model(&u).Update("investor_id", *inv.InvestorID).Error; err != nil {
+				return fmt.Errorf("link investor: %w", err)
+			}
+		}
+
+		res := tx.Model(&models.Invitation{}).
+			Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", inv.ID).
+			Updates(map[string]interface{}{"accepted_at": now, "accepted_by": u.ID})
+		if res.Error != nil {
+			return fmt.Errorf("mark accepted: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return errInvitationTaken
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errInvitationTaken) {
+			response.JSONError(c, http.StatusGone, "invitation_not_pending", "invitation is no longer pending", nil)
+			return
+		}
+		h.log.WithError(err).WithField("invitation_id", inv.ID).Error("failed to accept invitation")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to accept invitation", nil)
+		return
+	}
+	h.ensureInvestorProfile(&u)
+
+	pair, err := auth.GenerateTokenPair(h.cfg, u.ID, u.Email, u.Role, u.TokenVersion)
+	if err != nil {
+		h.log.WithError(err).Error("GenerateTokenPair")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to issue tokens", nil)
+		return
+	}
+	h.setAuthCookies(c, pair)
+
+	inv.AcceptedAt = &now
+	inv.AcceptedBy = &u.ID
+	inv.Status = models.InvitationAccepted
+	response.JSON(c, http.StatusOK, gin.H{"user": u, "invitation": inv})
+}
+
+// pendingInvitation loads the invitation behind secret and answers 404 or 410 when it cannot be used
+func (h *AuthHandler) pendingInvitation(c *gin.Context, secret string) (*models.Invitation, bool) {
+	if secret == "" {
+		response.JSONError(c, http.StatusNotFound, "invalid_invitation", "invitation not found", nil)
+		return nil, false
+	}
+	var inv models.Invitation
+	if err := h.db.Where("token_hash = ?", hashTokenSecret(secret)).First(&inv).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			h.log.WithError(err).Error("failed to fetch invitation")
+		}
+		response.JSONError(c, http.StatusNotFound, "invalid_invitation", "invitation not found", nil)
+		return nil, false
+	}
+	if status := inv.CurrentStatus(time.Now()); status != models.InvitationPending {
+		response.JSONError(c, http.StatusGone, "invitation_"+status, "invitation is "+status, nil)
+		return nil, false
+	}
+	inv.Status = models.InvitationPending
+	return &inv, true
+}
+
+func (h *AuthHandler) invitationTTL() time.Duration {
+	if h.cfg.Auth.Registration.InvitationTTL > 0 {
+		return h.cfg.Auth.Registration.InvitationTTL
+	}
+	return defaultInvitationTTL
+}
@@ -0,0 +1,165 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/password"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthHandler_Invitations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupUsersDB(t)
+	seedRoles(t, db)
+	require.NoError(t, db.AutoMigrate(&models.Invitation{}, &models.AuthToken{}, &models.Founder{}, &models.Startup{}, &models.Investor{}))
+
+	cfg := &config.Config{
+		App: config.AppConfig{BaseURL: "http://app.local"},
+		Auth: config.AuthConfig{
+			JWT:          config.JWTConfig{Secret: "test-secret", AccessTokenTTL: time.Hour, RefreshTokenTTL: time.Hour},
+			Registration: config.RegistrationConfig{InviteOnly: true, InvitationTTL: time.Hour},
+		},
+	}
+	admin := models.User{Email: "admin@corp.tld", Name: "Admin", Role: "admin"}
+	founder := models.User{Email: "founder@acme.tld", Name: "Founder", Role: "founder"}
+	member := models.User{Email: "member@corp.tld", Name: "Member", Role: "user"}
+	for _, u := range []*models.User{&admin, &founder, &member} {
+		require.NoError(t, db.Create(u).Error)
+	}
+	acme := models.Startup{Name: "Acme"}
+	other := models.Startup{Name: "Other"}
+	require.NoError(t, db.Create(&acme).Error)
+	require.NoError(t, db.Create(&other).Error)
+	require.NoError(t, db.Create(&models.Founder{UserID: founder.ID, StartupID: acme.ID}).Error)
+
+	mailer := &recordingMailer{}
+	h := v1.NewAuthHandler(cfg, db, logrus.New(), mailer, password.NewPolicy(cfg.Auth.Password, nil))
+	r := gin.New()
+	r.POST("/auth/register", h.Register)
+	r.GET("/invitations/lookup", h.LookupInvitation)
+	r.POST("/invitations/accept", middleware.AuthOptional(cfg), h.AcceptInvitation)
+	r.GET("/invitations", middleware.AuthRequired(cfg), h.ListInvitations)
+	r.POST("/invitations", middleware.AuthRequired(cfg), h.CreateInvitation)
+	r.DELETE("/invitations/:id", middleware.AuthRequired(cfg), h.RevokeInvitation)
+
+	cookieFor := func(u models.User) *http.Cookie {
+		pair, err := auth.GenerateTokenPair(cfg, u.ID, u.Email, u.Role, u.TokenVersion)
+		require.NoError(t, err)
+		return &http.Cookie{Name: "access_token", Value: pair.AccessToken}
+	}
+	call := func(method, path, body string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	tokenFor := func(to string) string {
+		mail := mailer.last(to)
+		require.NotNil(t, mail)
+		m := tokenLinkPattern.FindStringSubmatch(mail.Body)
+		require.Len(t, m, 2)
+		return m[1]
+	}
+
+	// Open registration is closed
+	w := call(http.MethodPost, "/auth/register", `{"email":"new@corp.tld","name":"New","password":"c0rrect-Horse-battery"}`, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Founders can only invite founders to their own startup
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, "/invitations",
+		fmt.Sprintf(`{"email":"cofounder@acme.tld","role":"founder","startup_id":%d}`, other.ID), cookieFor(founder)).Code)
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, "/invitations", `{"email":"x@corp.tld","role":"admin"}`, cookieFor(founder)).Code)
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, "/invitations", `{"email":"x@corp.tld","role":"investor"}`, cookieFor(member)).Code)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/invitations", `{"email":"x@corp.tld","role":"founder"}`, cookieFor(admin)).Code)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/invitations", `{"email":"x@corp.tld","role":"pirate"}`, cookieFor(admin)).Code)
+	assert.Equal(t, http.StatusConflict, call(http.MethodPost, "/invitations",
+		fmt.Sprintf(`{"email":"founder@acme.tld","role":"founder","startup_id":%d}`, acme.ID), cookieFor(admin)).Code)
+
+	w = call(http.MethodPost, "/invitations", fmt.Sprintf(`{"email":"CoFounder@acme.tld","role":"founder","startup_id":%d}`, acme.ID), cookieFor(founder))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"status":"pending"`)
+	assert.NotContains(t, w.Body.String(), "token_hash")
+	assert.Equal(t, http.StatusConflict, call(http.MethodPost, "/invitations",
+		fmt.Sprintf(`{"email":"cofounder@acme.tld","role":"founder","startup_id":%d}`, acme.ID), cookieFor(founder)).Code)
+	token := tokenFor("cofounder@acme.tld")
+
+	w = call(http.MethodGet, "/invitations/lookup?token="+token, "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"startup_name":"Acme"`)
+	assert.Contains(t, w.Body.String(), `"account_exists":false`)
+
+	// A new account is created by accepting, already verified and signed in
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/invitations/accept", `{"token":"`+token+`"}`, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/invitations/accept", `{"token":"`+token+`","name":"Co","password":"short"}`, nil).Code)
+	w = call(http.MethodPost, "/invitations/accept", `{"token":"`+token+`","name":"Co Founder","password":"c0rrect-Horse-battery"}`, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "access_token=")
+	var created models.User
+	require.NoError(t, db.Where("email = ?", "cofounder@acme.tld").First(&created).Error)
+	assert.Equal(t, "founder", created.Role)
+	assert.True(t, created.EmailVerified)
+	require.NotNil(t, created.FounderID)
+	var count int64
+	db.Model(&models.Founder{}).Where("user_id = ? AND startup_id = ?", created.ID, acme.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, http.StatusGone, call(http.MethodPost, "/invitations/accept", `{"token":"`+token+`","name":"Co","password":"c0rrect-Horse-battery"}`, nil).Code)
+
+	// Existing accounts must sign in as the invited address
+	w = call(http.MethodPost, "/invitations", `{"email":"member@corp.tld","role":"investor"}`, cookieFor(admin))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	token = tokenFor("member@corp.tld")
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, "/invitations/accept", `{"token":"`+token+`"}`, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, "/invitations/accept", `{"token":"`+token+`"}`, cookieFor(founder)).Code)
+	require.Equal(t, http.StatusOK, call(http.MethodPost, "/invitations/accept", `{"token":"`+token+`"}`, cookieFor(member)).Code)
+	require.NoError(t, db.First(&member, member.ID).Error)
+	assert.Equal(t, "investor", member.Role)
+
+	// Revocation is limited to the sender or privileged users, and to pending invitations
+	w = call(http.MethodPost, "/invitations", fmt.Sprintf(`{"email":"late@acme.tld","role":"founder","startup_id":%d}`, acme.ID), cookieFor(founder))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var body struct {
+		Data models.Invitation `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	path := fmt.Sprintf("/invitations/%d", body.Data.ID)
+	assert.Equal(t, http.StatusForbidden, call(http.MethodDelete, path, "", cookieFor(member)).Code)
+	require.Equal(t, http.StatusOK, call(http.MethodDelete, path, "", cookieFor(founder)).Code)
+	assert.Equal(t, http.StatusConflict, call(http.MethodDelete, path, "", cookieFor(admin)).Code)
+	assert.Equal(t, http.StatusGone, call(http.MethodGet, "/invitations/lookup?token="+tokenFor("late@acme.tld"), "", nil).Code)
+
+	// Founders only see their own invitations
+	w = call(http.MethodGet, "/invitations?status=pending", "", cookieFor(founder))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total":0`)
+	w = call(http.MethodGet, "/invitations", "", cookieFor(founder))
+	assert.Contains(t, w.Body.String(), `"total":2`)
+	w = call(http.MethodGet, "/invitations", "", cookieFor(admin))
+	assert.Contains(t, w.Body.String(), `"total":3`)
+
+	// Accounts holding another role than "user" keep it and cannot accept
+	require.Equal(t, http.StatusCreated, call(http.MethodPost, "/invitations", `{"email":"founder@acme.tld","role":"investor"}`, cookieFor(admin)).Code)
+	w = call(http.MethodPost, "/invitations/accept", `{"token":"`+tokenFor("founder@acme.tld")+`"}`, cookieFor(founder))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "role_mismatch")
+	require.NoError(t, db.First(&founder, founder.ID).Error)
+	assert.Equal(t, "founder", founder.Role)
+	assert.Nil(t, founder.InvestorID)
+}
@@ -0,0 +1,210 @@
+package v1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultMagicLinkTTL            = 15 * time.Minute
+	defaultMagicLinkResendInterval = time.Minute
+	magicLinkSentMessage           = "if the email exists, a sign-in link was sent"
+)
+
+// magicLinkBinding is stored as the token payload when auth.magic_link binds links to the requesting client
+type magicLinkBinding struct {
+	IP     string `json:"ip,omitempty"`
+	Device string `json:"device,omitempty"`
+}
+
+// RequestMagicLink godoc
+// @Summary      Request a sign-in link
+// @Description  Emails a single-use link that signs the user in without a password. The answer does not reveal whether the email exists.
+// @Description  Links are throttled per email (auth.magic_link.resend_interval) and a new link invalidates the previous one. They can be bound to the requesting IP and browser.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        payload body requests.MagicLinkRequest true "Email" Example({"email":"jane@doe.tld"})
+// @Success      202 {object} response.MessageResponse
+// @Failure      400 {object} response.ErrorBody
+// @Router       /auth/magic-link [post]
+func (h *AuthHandler) RequestMagicLink(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSON(c, http.StatusBadRequest, gin.H{"code": 2100, "message": "invalid request payload", "errors": err.Error()})
+		return
+	}
+
+	var u models.User
+	if err := h.db.Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(req.Email))).First(&u).Error; err != nil {
+		response.JSON(c, http.StatusAccepted, gin.H{"message": magicLinkSentMessage})
+		return
+	}
+	if u.AnonymizedAt != nil {
+		response.JSON(c, http.StatusAccepted, gin.H{"message": magicLinkSentMessage})
+		return
+	}
+	if h.mailer == nil {
+		h.log.Warn("mailer is nil; cannot send magic link")
+		response.JSON(c, http.StatusAccepted, gin.H{"message": magicLinkSentMessage})
+		return
+	}
+
+	var last models.AuthToken
+	err := h.db.Where("user_id = ? AND token_type = ?", u.ID, "magic_link").Order("created_at DESC").Limit(1).Find(&last).Error
+	if err != nil {
+		h.log.WithError(err).Warn("failed to check previous magic links")
+	} else if last.ID != 0 && time.Since(last.CreatedAt) < h.magicLinkResendInterval() {
+		h.log.WithField("user_id", u.ID).Info("magic link throttled")
+		response.JSON(c, http.StatusAccepted, gin.H{"message": magicLinkSentMessage})
+		return
+	}
+
+	// Only the latest link can be used
+	if err := h.db.Where("user_id = ? AND token_type = ?", u.ID, "magic_link").Delete(&models.AuthToken{}).Error; err != nil {
+		h.log.WithError(err).Warn("failed to delete previous magic links")
+	}
+	var payload *string
+	if binding := h.magicLinkBindingFor(c); binding != (magicLinkBinding{}) {
+		raw, _ := json.Marshal(binding)
+		s := string(raw)
+		payload = &s
+	}
+	ttl := h.magicLinkTTL()
+	token, err := h.createOneTimeTokenWithPayload(c, u.ID, "magic_link", ttl, payload)
+	if err != nil {
+		h.log.WithError(err).Warn("createOneTimeToken magic_link failed")
+		response.JSON(c, http.StatusAccepted, gin.H{"message": magicLinkSentMessage})
+		return
+	}
+
+	link := fmt.Sprintf("%s/api/%s/auth/magic-link/consume?token=%s", strings.TrimRight(h.cfg.App.BaseURL, "/"), h.cfg.App.Version, token)
+	body := fmt.Sprintf("<p>Hello %s,</p><p>Click the link below to sign in (valid for %d minutes, single use):</p><p><a href=\"%s\">Sign in</a></p><p>If you did not ask for it, you can ignore this email.</p>",
+		html.EscapeString(u.Name), int(ttl.Minutes()), link)
+	if err := h.mailer.Send(c.Request.Context(), u.Email, "Your sign-in link", body); err != nil {
+		h.log.WithError(err).Warn("mailer.Send magic link failed")
+	}
+	response.JSON(c, http.StatusAccepted, gin.H{"message": magicLinkSentMessage})
+}
+
+// ConsumeMagicLink godoc
+// @Summary      Sign in with a link
+// @Description  Uses a sign-in link and sets the auth cookies like /auth/login. The browser is redirected to auth.magic_link.success_redirect (or failure_redirect with an error code) when configured; the user profile is returned otherwise.
+// @Description  A link bound to another IP or browser is rejected without being used up. Using a link also verifies the email.
+// @Tags         Auth
+// @Produce      json
+// @Param        token query string true "Sign-in token"
+// @Success      200 {object} response.AuthLoginResponse
+// @Success      302 "Redirect to the configured success URL"
+// @Failure      401 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /auth/magic-link/consume [get]
+func (h *AuthHandler) ConsumeMagicLink(c *gin.Context) {
+	secret := c.Query("token")
+	at, err := h.findOneTimeToken(c, secret, "magic_link")
+	if err != nil {
+		h.magicLinkFail(c, http.StatusUnauthorized, "invalid_token", "invalid or expired link")
+		return
+	}
+	if !h.magicLinkBindingMatches(c, at) {
+		h.log.WithField("user_id", at.UserID).Warn("magic link used from another client")
+		h.magicLinkFail(c, http.StatusUnauthorized, "link_binding_mismatch", "open the link on the device that requested it")
+		return
+	}
+	if _, err := h.consumeOneTimeTokenRecord(c, secret, "magic_link"); err != nil {
+		h.magicLinkFail(c, http.StatusUnauthorized, "invalid_token", "invalid or expired link")
+		return
+	}
+
+	var u models.User
+	if err := h.db.First(&u, at.UserID).Error; err != nil {
+		h.magicLinkFail(c, http.StatusUnauthorized, "invalid_token", "user no longer exists")
+		return
+	}
+	if !u.EmailVerified {
+		if err := h.db.Model(&u).Update("email_verified", true).Error; err != nil {
+			h.log.WithError(err).Warn("failed to mark email verified")
+		}
+	}
+
+	pair, err := auth.GenerateTokenPair(h.cfg, u.ID, u.Email, u.Role, u.TokenVersion)
+	if err != nil {
+		h.log.WithError(err).Error("GenerateTokenPair")
+		h.magicLinkFail(c, http.StatusInternalServerError, "internal_error", "failed to issue tokens")
+		return
+	}
+	h.setAuthCookies(c, pair)
+	if target := h.cfg.Auth.MagicLink.SuccessRedirect; target != "" {
+		c.Redirect(http.StatusFound, target)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"user": u})
+}
+
+func (h *AuthHandler) magicLinkFail(c *gin.Context, status int, code, message string) {
+	if target := h.cfg.Auth.MagicLink.FailureRedirect; target != "" {
+		c.Redirect(http.StatusFound, appendQuery(target, "error", code))
+		return
+	}
+	response.JSONError(c, status, code, message, nil)
+}
+
+// magicLinkBindingFor describes the requesting client according to auth.magic_link.bind_ip and bind_device
+func (h *AuthHandler) magicLinkBindingFor(c *gin.Context) magicLinkBinding {
+	var b magicLinkBinding
+	if h.cfg.Auth.MagicLink.BindIP {
+		b.IP = c.ClientIP()
+	}
+	if h.cfg.Auth.MagicLink.BindDevice {
+		b.Device = deviceFingerprint(c)
+	}
+	return b
+}
+
+// magicLinkBindingMatches checks the client against the binding recorded when the link was requested
+func (h *AuthHandler) magicLinkBindingMatches(c *gin.Context, at *models.AuthToken) bool {
+	if at.Payload == nil {
+		return true
+	}
+	var want magicLinkBinding
+	if err := json.Unmarshal([]byte(*at.Payload), &want); err != nil {
+		return false
+	}
+	if want.IP != "" && want.IP != c.ClientIP() {
+		return false
+	}
+	return want.Device == "" || want.Device == deviceFingerprint(c)
+}
+
+// deviceFingerprint identifies the browser without storing its User-Agent
+func deviceFingerprint(c *gin.Context) string {
+	sum := sha256.Sum256([]byte(c.Request.UserAgent()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (h *AuthHandler) magicLinkTTL() time.Duration {
+	if h.cfg.Auth.MagicLink.TTL > 0 {
+		return h.cfg.Auth.MagicLink.TTL
+	}
+	return defaultMagicLinkTTL
+}
+
+func (h *AuthHandler) magicLinkResendInterval() time.Duration {
+	if h.cfg.Auth.MagicLink.ResendInterval > 0 {
+		return h.cfg.Auth.MagicLink.ResendInterval
+	}
+	return defaultMagicLinkResendInterval
+}
@@ -0,0 +1,85 @@
+package v1_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/password"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthHandler_MagicLink(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupUsersDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AuthToken{}))
+
+	cfg := &config.Config{
+		App: config.AppConfig{BaseURL: "http://app.local", Version: "v1"},
+		Auth: config.AuthConfig{
+			JWT:       config.JWTConfig{Secret: "test-secret", AccessTokenTTL: time.Hour, RefreshTokenTTL: time.Hour},
+			MagicLink: config.MagicLinkConfig{TTL: 10 * time.Minute, ResendInterval: time.Hour, BindDevice: true},
+		},
+	}
+	u := models.User{Email: "investor@fund.tld", Name: "Ivy", Role: "investor"}
+	require.NoError(t, db.Create(&u).Error)
+
+	mailer := &recordingMailer{}
+	h := v1.NewAuthHandler(cfg, db, logrus.New(), mailer, password.NewPolicy(cfg.Auth.Password, nil))
+	r := gin.New()
+	r.POST("/auth/magic-link", h.RequestMagicLink)
+	r.GET("/auth/magic-link/consume", h.ConsumeMagicLink)
+
+	call := func(method, path, body, userAgent string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusAccepted, call(http.MethodPost, "/auth/magic-link", `{"email":"nobody@fund.tld"}`, "laptop").Code)
+	assert.Empty(t, mailer.sent)
+
+	require.Equal(t, http.StatusAccepted, call(http.MethodPost, "/auth/magic-link", `{"email":"Investor@fund.tld"}`, "laptop").Code)
+	require.Len(t, mailer.sent, 1)
+	assert.Contains(t, mailer.sent[0].Body, "http://app.local/api/v1/auth/magic-link/consume?token=")
+	token := tokenLinkPattern.FindStringSubmatch(mailer.sent[0].Body)[1]
+
+	// Throttled: no second email within the resend interval
+	require.Equal(t, http.StatusAccepted, call(http.MethodPost, "/auth/magic-link", `{"email":"investor@fund.tld"}`, "laptop").Code)
+	assert.Len(t, mailer.sent, 1)
+
+	// Bound to the requesting browser, and not used up by a mismatch
+	w := call(http.MethodGet, "/auth/magic-link/consume?token="+token, "", "phone")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "link_binding_mismatch")
+
+	w = call(http.MethodGet, "/auth/magic-link/consume?token="+token, "", "laptop")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	names := map[string]bool{}
+	for _, ck := range w.Result().Cookies() {
+		names[ck.Name] = ck.Value != ""
+	}
+	assert.True(t, names["access_token"])
+	assert.True(t, names["refresh_token"])
+	require.NoError(t, db.First(&u, u.ID).Error)
+	assert.True(t, u.EmailVerified)
+
+	// Single use
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/auth/magic-link/consume?token="+token, "", "laptop").Code)
+
+	cfg.Auth.MagicLink.FailureRedirect = "http://front.local/login"
+	w = call(http.MethodGet, "/auth/magic-link/consume?token=deadbeef", "", "laptop")
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "http://front.local/login?error=invalid_token", w.Header().Get("Location"))
+}
@@ -0,0 +1,478 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/oauth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const oauthStateCookie = "oauth_state"
+
+// OAuthProviders godoc
+// @Summary      List social login providers
+// @Description  Returns the names of the enabled OAuth2/OIDC providers.
+// @Tags         Auth
+// @Produce      json
+// @Success      200 {object} map[string][]string
+// @Router       /auth/oauth/providers [get]
+func (h *AuthHandler) OAuthProviders(c *gin.Context) {
+	response.JSON(c, http.StatusOK, gin.H{"data": h.oauth.Names()})
+}
+
+// OAuthStart godoc
+// @Summary      Start social login
+// @Description  Redirects to the provider authorization page (authorization code + PKCE). Accounts created by this login get the basic "user" role.
+// @Tags         Auth
+// @Param        provider path  string true  "Provider name" example(github)
+// @Success      302 "Redirect to provider"
+// @Failure      400 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Router       /auth/oauth/{provider}/login [get]
+func (h *AuthHandler) OAuthStart(c *gin.Context) {
+	h.redirectToProvider(c, oauth.StateClaims{})
+}
+
+// LinkIdentity godoc
+// @Summary      Link a social identity
+// @Description  Redirects to the provider to link an additional login method to the current account.
+// @Tags         Users
+// @Security     CookieAuth
+// @Param        provider path string true "Provider name" example(github)
+// @Success      302 "Redirect to provider"
+// @Failure      401 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Router       /users/me/identities/{provider}/link [get]
+func (h *AuthHandler) LinkIdentity(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	h.redirectToProvider(c, oauth.StateClaims{LinkUserID: claims.UserID})
+}
+
+// OAuthCallback godoc
+// @Summary      Social login callback
+// @Description  Completes the authorization code flow, links or creates the user, sets HttpOnly cookies and redirects to the frontend.
+// @Tags         Auth
+// @Param        provider path  string true "Provider name" example(github)
+// @Param        code     query string true "Authorization code"
+// @Param        state    query string true "Opaque state"
+// @Success      302 "Redirect to frontend"
+// @Success      200 {object} response.AuthLoginResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Router       /auth/oauth/{provider}/callback [get]
+func (h *AuthHandler) OAuthCallback(c *gin.Context) {
+	p, ok := h.oauth.Get(c.Param("provider"))
+	if !ok {
+		response.JSONError(c, http.StatusNotFound, "not_found", "unknown provider", nil)
+		return
+	}
+
+	raw, _ := c.Cookie(oauthStateCookie)
+	h.clearOAuthStateCookie(c)
+	st, err := oauth.ParseState(h.cfg.Auth.JWT.Secret, raw)
+	if err != nil || st.Provider != p.Name || st.State == "" || st.State != c.Query("state") {
+		h.oauthFail(c, http.StatusBadRequest, "invalid_state", "invalid or expired oauth state")
+		return
+	}
+	if e := c.Query("error"); e != "" {
+		h.oauthFail(c, http.StatusUnauthorized, "oauth_denied", e)
+		return
+	}
+	code := c.Query("code")
+	if code == "" {
+		h.oauthFail(c, http.StatusBadRequest, "invalid_params", "missing code")
+		return
+	}
+
+	ctx := c.Request.Context()
+	redirectURL := p.RedirectURL(h.oauthCallbackURL(p.Name))
+	tok, err := p.Exchange(ctx, code, st.Verifier, redirectURL)
+	if err != nil {
+		h.log.WithError(err).WithField("provider", p.Name).Warn("oauth exchange failed")
+		h.oauthFail(c, http.StatusUnauthorized, "oauth_failed", "failed to exchange authorization code")
+		return
+	}
+	ident, err := p.UserInfo(ctx, tok)
+	if err != nil {
+		h.log.WithError(err).WithField("provider", p.Name).Warn("oauth userinfo failed")
+		h.oauthFail(c, http.StatusUnauthorized, "oauth_failed", "failed to fetch provider profile")
+		return
+	}
+
+	if st.LinkUserID != 0 {
+		h.completeLink(c, p.Name, st.LinkUserID, ident)
+		return
+	}
+
+	u, status, code, err := h.resolveOAuthUser(c, p.Name, ident)
+	if err != nil {
+		if status >= http.StatusInternalServerError {
+			h.log.WithError(err).WithField("provider", p.Name).Error("oauth resolve user")
+		}
+		h.oauthFail(c, status, code, err.Error())
+		return
+	}
+	if !u.EmailVerified {
+		h.oauthFail(c, http.StatusForbidden, "email_not_verified", "please verify your email before logging in")
+		return
+	}
+
+	pair, err := auth.GenerateTokenPair(h.cfg, u.ID, u.Email, u.Role, u.TokenVersion)
+	if err != nil {
+		h.log.WithError(err).Error("GenerateTokenPair")
+		h.oauthFail(c, http.StatusInternalServerError, "internal_error", "failed to issue tokens")
+		return
+	}
+	h.setAuthCookies(c, pair)
+	if target := h.cfg.Auth.OAuth.SuccessRedirect; target != "" {
+		c.Redirect(http.StatusFound, target)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"user": u})
+}
+
+// ListIdentities godoc
+// @Summary      My linked identities
+// @Description  Lists the social login identities linked to the current account.
+// @Tags         Users
+// @Security     CookieAuth
+// @Success      200 {object} map[string]interface{}
+// @Failure      401 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /users/me/identities [get]
+func (h *AuthHandler) ListIdentities(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	var identities []models.UserIdentity
+	if err := h.db.Where("user_id = ?", claims.UserID).Order("created_at ASC").Find(&identities).Error; err != nil {
+		h.log.WithError(err).Error("failed to list identities")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve identities", nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"data": identities, "providers": h.oauth.Names()})
+}
+
+// UnlinkIdentity godoc
+// @Summary      Unlink a social identity
+// @Description  Removes a linked identity. The last login method of an account without password cannot be removed.
+// @Tags         Users
+// @Security     CookieAuth
+// @Param        provider path string true "Provider name" example(github)
+// @Success      200 {object} response.MessageResponse
+// @Failure      401 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /users/me/identities/{provider} [delete]
+func (h *AuthHandler) UnlinkIdentity(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+
+	var ident models.UserIdentity
+	if err := h.db.Where("provider = ? AND user_id = ?", c.Param("provider"), claims.UserID).First(&ident).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusNotFound, "not_found", "identity not found", nil)
+			return
+		}
+		h.log.WithError(err).Error("failed to fetch identity")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve identity", nil)
+		return
+	}
+
+	var u models.User
+	if err := h.db.First(&u, claims.UserID).Error; err != nil {
+		response.JSONError(c, http.StatusNotFound, "not_found", "user not found", nil)
+		return
+	}
+	if u.PasswordHash == "" {
+		var count int64
+		if err := h.db.Model(&models.UserIdentity{}).Where("user_id = ?", u.ID).Count(&count).Error; err != nil {
+			h.log.WithError(err).Error("failed to count identities")
+			response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to unlink identity", nil)
+			return
+		}
+		if count <= 1 {
+			response.JSONError(c, http.StatusConflict, "last_login_method", "set a password before removing your last linked identity", nil)
+			return
+		}
+	}
+
+	if err := h.db.Delete(&ident).Error; err != nil {
+		h.log.WithError(err).Error("failed to delete identity")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to unlink identity", nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"message": "identity unlinked"})
+}
+
+// redirectToProvider stores the signed state + PKCE verifier in a cookie and redirects to the provider
+func (h *AuthHandler) redirectToProvider(c *gin.Context, st oauth.StateClaims) {
+	p, ok := h.oauth.Get(c.Param("provider"))
+	if !ok {
+		response.JSONError(c, http.StatusNotFound, "not_found", "unknown provider", nil)
+		return
+	}
+
+	state, err := oauth.RandomString(24)
+	if err != nil {
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to start login", nil)
+		return
+	}
+	verifier, challenge, err := oauth.NewPKCE()
+	if err != nil {
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to start login", nil)
+		return
+	}
+	st.State = state
+	st.Verifier = verifier
+	st.Provider = p.Name
+
+	ttl := h.oauthStateTTL()
+	signed, err := oauth.SignState(h.cfg.Auth.JWT.Secret, ttl, st)
+	if err != nil {
+		h.log.WithError(err).Error("oauth.SignState")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to start login", nil)
+		return
+	}
+
+	target, err := p.AuthCodeURL(c.Request.Context(), state, challenge, p.RedirectURL(h.oauthCallbackURL(p.Name)))
+	if err != nil {
+		h.log.WithError(err).WithField("provider", p.Name).Warn("oauth provider unavailable")
+		response.JSONError(c, http.StatusBadGateway, "provider_unavailable", "oauth provider unavailable", nil)
+		return
+	}
+
+	secure, _ := h.cookieAttrs()
+	// Lax is required: the callback is a top-level cross-site navigation from the provider
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    signed,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+		Expires:  time.Now().Add(ttl),
+		MaxAge:   int(ttl.Seconds()),
+	})
+	c.Redirect(http.StatusFound, target)
+}
+
+// resolveOAuthUser finds the user owning the identity, links by verified email, or creates a new account
+func (h *AuthHandler) resolveOAuthUser(c *gin.Context, provider string, ident *oauth.Identity) (*models.User, int, string, error) {
+	var existing models.UserIdentity
+	err := h.db.Where("provider = ? AND subject = ?", provider, ident.Subject).First(&existing).Error
+	switch {
+	case err == nil:
+		var u models.User
+		if err := h.db.First(&u, existing.UserID).Error; err != nil {
+			return nil, http.StatusUnauthorized, "invalid_credentials", fmt.Errorf("linked user no longer exists")
+		}
+		now := time.Now()
+		_ = h.db.Model(&existing).Updates(map[string]interface{}{"last_login_at": now, "email_verified": ident.EmailVerified}).Error
+		if ident.EmailVerified && !u.EmailVerified && strings.EqualFold(u.Email, ident.Email) {
+			if err := h.db.Model(&u).Update("email_verified", true).Error; err == nil {
+				u.EmailVerified = true
+			}
+		}
+		return &u, 0, "", nil
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, http.StatusInternalServerError, "internal_error", fmt.Errorf("failed to process login")
+	}
+
+	if ident.Email == "" {
+		return nil, http.StatusBadRequest, "email_required", fmt.Errorf("the provider did not share an email address")
+	}
+
+	var u models.User
+	err = h.db.Where("email = ?", ident.Email).First(&u).Error
+	switch {
+	case err == nil:
+		// Only auto-link an existing account when the provider vouches for the address
+		if !ident.EmailVerified {
+			return nil, http.StatusConflict, "email_taken", fmt.Errorf("email already registered; sign in and link this provider from your profile")
+		}
+		if !u.EmailVerified {
+			if err := h.db.Model(&u).Update("email_verified", true).Error; err != nil {
+				return nil, http.StatusInternalServerError, "internal_error", fmt.Errorf("failed to process login")
+			}
+			u.EmailVerified = true
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if h.cfg.Auth.Registration.InviteOnly {
+			return nil, http.StatusForbidden, "registration_closed", fmt.Errorf("sign-up is by invitation only")
+		}
+		created, err := h.createOAuthUser(c, ident)
+		if err != nil {
+			return nil, http.StatusInternalServerError, "internal_error", fmt.Errorf("failed to create user")
+		}
+		u = *created
+	default:
+		return nil, http.StatusInternalServerError, "internal_error", fmt.Errorf("failed to process login")
+	}
+
+	if err := h.createIdentity(u.ID, provider, ident); err != nil {
+		return nil, http.StatusInternalServerError, "internal_error", fmt.Errorf("failed to link identity")
+	}
+	return &u, 0, "", nil
+}
+
+// createOAuthUser creates a password-less account with the basic role from a provider profile
+func (h *AuthHandler) createOAuthUser(c *gin.Context, ident *oauth.Identity) (*models.User, error) {
+	_ = h.alignUserSequence()
+
+	name := strings.TrimSpace(ident.Name)
+	if name == "" {
+		name = strings.Split(ident.Email, "@")[0]
+	}
+	u := models.User{
+		Email:         ident.Email,
+		Name:          name,
+		Role:          "user",
+		EmailVerified: ident.EmailVerified,
+	}
+	if ident.Picture != "" {
+		pic := ident.Picture
+		u.ImageURL = &pic
+	}
+	if err := h.db.Create(&u).Error; err != nil {
+		h.log.WithError(err).Error("db create oauth user")
+		return nil, err
+	}
+
+	if !u.EmailVerified && h.mailer != nil {
+		if token, err := h.createOneTimeToken(c, u.ID, "verify", h.cfg.Auth.EmailVerificationTTL); err != nil {
+			h.log.WithError(err).Warn("createOneTimeToken verify failed")
+		} else {
+			link := fmt.Sprintf("%s/api/%s/auth/verify?token=%s", strings.TrimRight(h.cfg.App.BaseURL, "/"), h.cfg.App.Version, token)
+			body := fmt.Sprintf("<p>Welcome %s,</p><p>Please verify your email by clicking the link below:</p><p><a href=\"%s\">Verify Email</a></p>", u.Name, link)
+			if err := h.mailer.Send(c.Request.Context(), u.Email, "Verify your email", body); err != nil {
+				h.log.WithError(err).Warn("mailer.Send verify email failed")
+			}
+		}
+	}
+	return &u, nil
+}
+
+// completeLink attaches the provider identity to an already authenticated user
+func (h *AuthHandler) completeLink(c *gin.Context, provider string, userID uint64, ident *oauth.Identity) {
+	var existing models.UserIdentity
+	err := h.db.Where("provider = ? AND subject = ?", provider, ident.Subject).First(&existing).Error
+	if err == nil {
+		if existing.UserID != userID {
+			h.oauthFail(c, http.StatusConflict, "identity_taken", "this identity is linked to another account")
+			return
+		}
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		if err := h.createIdentity(userID, provider, ident); err != nil {
+			h.oauthFail(c, http.StatusConflict, "identity_conflict", "a different identity from this provider is already linked")
+			return
+		}
+	} else {
+		h.log.WithError(err).Error("failed to look up identity")
+		h.oauthFail(c, http.StatusInternalServerError, "internal_error", "failed to link identity")
+		return
+	}
+
+	if ident.EmailVerified {
+		_ = h.db.Model(&models.User{}).
+			Where("id = ? AND LOWER(email) = LOWER(?)", userID, ident.Email).
+			Update("email_verified", true).Error
+	}
+
+	if target := h.cfg.Auth.OAuth.SuccessRedirect; target != "" {
+		c.Redirect(http.StatusFound, appendQuery(target, "linked", provider))
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"message": "identity linked", "provider": provider})
+}
+
+func (h *AuthHandler) createIdentity(userID uint64, provider string, ident *oauth.Identity) error {
+	now := time.Now()
+	row := models.UserIdentity{
+		UserID:        userID,
+		Provider:      provider,
+		Subject:       ident.Subject,
+		EmailVerified: ident.EmailVerified,
+		LastLoginAt:   &now,
+	}
+	if ident.Email != "" {
+		e := ident.Email
+		row.Email = &e
+	}
+	res := h.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+	if res.Error != nil {
+		h.log.WithError(res.Error).Error("db create user identity")
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("identity already linked")
+	}
+	return nil
+}
+
+// oauthFail redirects to the configured failure page, or answers with a JSON error when none is configured
+func (h *AuthHandler) oauthFail(c *gin.Context, status int, code, message string) {
+	if target := h.cfg.Auth.OAuth.FailureRedirect; target != "" {
+		c.Redirect(http.StatusFound, appendQuery(target, "error", code))
+		return
+	}
+	response.JSONError(c, status, code, message, nil)
+}
+
+func (h *AuthHandler) oauthCallbackURL(provider string) string {
+	return fmt.Sprintf("%s/api/%s/auth/oauth/%s/callback", strings.TrimRight(h.cfg.App.BaseURL, "/"), h.cfg.App.Version, url.PathEscape(provider))
+}
+
+func (h *AuthHandler) oauthStateTTL() time.Duration {
+	if h.cfg.Auth.OAuth.StateTTL > 0 {
+		return h.cfg.Auth.OAuth.StateTTL
+	}
+	return 10 * time.Minute
+}
+
+func (h *AuthHandler) clearOAuthStateCookie(c *gin.Context) {
+	secure, _ := h.cookieAttrs()
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    "",
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+		Expires:  time.Now().Add(-time.Hour),
+		MaxAge:   -1,
+	})
+}
+
+func appendQuery(target, key, value string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
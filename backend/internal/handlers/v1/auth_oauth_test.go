@@ -0,0 +1,205 @@
+package v1_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/password"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOIDCStandIn starts a minimal OpenID provider: discovery, token (with PKCE check) and userinfo
+func newOIDCStandIn(t *testing.T, email string, verified bool) *httptest.Server {
+	challenges := map[string]string{}
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 srv.URL,
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+			"userinfo_endpoint":      srv.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		challenges["code-123"] = r.URL.Query().Get("code_challenge")
+		http.Redirect(w, r, r.URL.Query().Get("redirect_uri")+"?code=code-123&state="+url.QueryEscape(r.URL.Query().Get("state")), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if challenges[r.PostForm.Get("code")] != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "at-1", "token_type": "Bearer"})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer at-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"sub": "subject-42", "email": email, "email_verified": verified, "name": "Olive Idc",
+		})
+	})
+	return srv
+}
+
+// oauthLogin drives the browser side of the flow and returns the callback response
+func oauthLogin(t *testing.T, r *gin.Engine, startPath string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, startPath, nil)
+	for _, ck := range cookies {
+		req.AddCookie(ck)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusFound, w.Code)
+
+	var stateCookie *http.Cookie
+	for _, ck := range w.Result().Cookies() {
+		if ck.Name == "oauth_state" {
+			stateCookie = ck
+		}
+	}
+	require.NotNil(t, stateCookie)
+
+	// Follow the redirect to the stand-in provider, which bounces back with a code
+	resp, err := (&http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}).
+		Get(w.Header().Get("Location"))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+
+	req = httptest.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+	req.AddCookie(stateCookie)
+	for _, ck := range cookies {
+		req.AddCookie(ck)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthHandler_OAuthFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupUsersDB(t)
+	require.NoError(t, db.AutoMigrate(&models.UserIdentity{}, &models.Investor{}, &models.AuthToken{}))
+
+	provider := newOIDCStandIn(t, "olive@idc.tld", true)
+	cfg := &config.Config{
+		App: config.AppConfig{BaseURL: "http://api.local", Version: "v1"},
+		Auth: config.AuthConfig{
+			JWT: config.JWTConfig{Secret: "test-secret", AccessTokenTTL: time.Hour, RefreshTokenTTL: 24 * time.Hour},
+			OAuth: config.OAuthConfig{Providers: map[string]config.OAuthProviderConfig{
+				"oidc": {Type: "oidc", Issuer: provider.URL, ClientID: "client", ClientSecret: "secret"},
+			}},
+		},
+	}
+	h := v1.NewAuthHandler(cfg, db, logrus.New(), nil, password.NewPolicy(cfg.Auth.Password, nil))
+
+	r := gin.New()
+	r.GET("/api/v1/auth/oauth/providers", h.OAuthProviders)
+	r.GET("/api/v1/auth/oauth/:provider/login", h.OAuthStart)
+	r.GET("/api/v1/auth/oauth/:provider/callback", h.OAuthCallback)
+	me := r.Group("/api/v1/users/me", middleware.AuthRequired(cfg))
+	me.GET("/identities", h.ListIdentities)
+	me.DELETE("/identities/:provider", h.UnlinkIdentity)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/providers", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "oidc")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/unknown/login", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// First login creates a verified, password-less account linked to the identity
+	w = oauthLogin(t, r, "/api/v1/auth/oauth/oidc/login")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var access *http.Cookie
+	for _, ck := range w.Result().Cookies() {
+		if ck.Name == "access_token" {
+			access = ck
+		}
+	}
+	require.NotNil(t, access)
+
+	var u models.User
+	require.NoError(t, db.Where("email = ?", "olive@idc.tld").First(&u).Error)
+	assert.True(t, u.EmailVerified)
+	assert.Equal(t, "user", u.Role)
+	assert.Nil(t, u.InvestorID)
+	assert.Empty(t, u.PasswordHash)
+
+	// Second login reuses the same identity
+	w = oauthLogin(t, r, "/api/v1/auth/oauth/oidc/login")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var count int64
+	db.Model(&models.UserIdentity{}).Count(&count)
+	assert.EqualValues(t, 1, count)
+
+	// A tampered state is rejected
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/oidc/callback?code=code-123&state=forged", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/identities", nil)
+	req.AddCookie(access)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"provider":"oidc"`)
+
+	// The only login method of a password-less account cannot be removed
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/users/me/identities/oidc", nil)
+	req.AddCookie(access)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAuthHandler_OAuthUnverifiedEmailDoesNotTakeOver(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupUsersDB(t)
+	require.NoError(t, db.AutoMigrate(&models.UserIdentity{}, &models.Investor{}, &models.AuthToken{}))
+	db.Create(&models.User{Email: "owner@corp.tld", Name: "Owner", Role: "investor", PasswordHash: "x", EmailVerified: true})
+
+	provider := newOIDCStandIn(t, "owner@corp.tld", false)
+	cfg := &config.Config{
+		App: config.AppConfig{BaseURL: "http://api.local", Version: "v1"},
+		Auth: config.AuthConfig{
+			JWT: config.JWTConfig{Secret: "test-secret", AccessTokenTTL: time.Hour, RefreshTokenTTL: 24 * time.Hour},
+			OAuth: config.OAuthConfig{Providers: map[string]config.OAuthProviderConfig{
+				"oidc": {Type: "oidc", Issuer: provider.URL, ClientID: "client"},
+			}},
+		},
+	}
+	h := v1.NewAuthHandler(cfg, db, logrus.New(), nil, password.NewPolicy(cfg.Auth.Password, nil))
+	r := gin.New()
+	r.GET("/api/v1/auth/oauth/:provider/login", h.OAuthStart)
+	r.GET("/api/v1/auth/oauth/:provider/callback", h.OAuthCallback)
+
+	w := oauthLogin(t, r, "/api/v1/auth/oauth/oidc/login")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var count int64
+	db.Model(&models.UserIdentity{}).Count(&count)
+	assert.EqualValues(t, 0, count)
+}
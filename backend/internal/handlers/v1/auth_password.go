@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/accounts"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/password"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ChangePassword godoc
+// @Summary      Change my password
+// @Description  Sets a new password that must satisfy the password policy. Accounts with a password must provide the current one; social-login accounts can set a first password.
+// @Description  Every other session is signed out and pending verification, reset and email change links are invalidated. The current session gets fresh cookies and a notification email is sent.
+// @Tags         Users
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        payload body requests.PasswordChangeRequest true "Passwords" Example({"current_password":"secret123","new_password":"c0rrect-Horse-battery"})
+// @Success      200 {object} response.MessageResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /users/me/password [post]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	u, ok := h.selfServiceUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+	if !h.checkCurrentPassword(c, u, req.CurrentPassword) {
+		return
+	}
+	if !acceptablePassword(c, h.passwords, req.NewPassword, u.Email, u.Name) {
+		return
+	}
+
+	hash, err := h.passwords.Hash(req.NewPassword)
+	if err != nil {
+		h.log.WithError(err).Error("failed to hash password")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to process password", nil)
+		return
+	}
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(u).Update("password_hash", hash).Error; err != nil {
+			return err
+		}
+		return revokeSessions(tx, u)
+	}); err != nil {
+		h.log.WithError(err).Error("failed to change password")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to change password", nil)
+		return
+	}
+
+	// Keep the session the change was made from
+	pair, err := auth.GenerateTokenPair(h.cfg, u.ID, u.Email, u.Role, u.TokenVersion)
+	if err != nil {
+		h.log.WithError(err).Error("GenerateTokenPair")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to issue tokens", nil)
+		return
+	}
+	h.setAuthCookies(c, pair)
+
+	h.notify(c, u.Email, "Your password was changed",
+		fmt.Sprintf("<p>Hello %s,</p><p>The password of your account was changed and your other sessions were signed out. If this was not you, reset your password now.</p>",
+			html.EscapeString(u.Name)))
+	response.JSON(c, http.StatusOK, gin.H{"message": "password updated"})
+}
+
+// PasswordPolicy godoc
+// @Summary      Password policy
+// @Description  Returns the requirements new passwords must meet, so that forms can validate them before submitting.
+// @Tags         Auth
+// @Produce      json
+// @Success      200 {object} response.PasswordPolicyResponse
+// @Router       /auth/password-policy [get]
+func (h *AuthHandler) PasswordPolicy(c *gin.Context) {
+	response.JSON(c, http.StatusOK, gin.H{"data": h.passwords.Requirements()})
+}
+
+// revokeSessions invalidates every refresh token and pending one-time token of u by bumping its token version.
+// u.TokenVersion is updated so that the caller can issue tokens of the new generation
+func revokeSessions(tx *gorm.DB, u *models.User) error {
+	return accounts.RevokeSessions(tx, u)
+}
+
+// acceptablePassword checks pw against the policy and answers 400 weak_password with the broken rules otherwise
+func acceptablePassword(c *gin.Context, p *password.Policy, pw, email, name string) bool {
+	violations := p.Validate(pw, email, name)
+	if len(violations) == 0 {
+		return true
+	}
+	response.JSONError(c, http.StatusBadRequest, "weak_password", "password does not meet the password policy", gin.H{
+		"violations":   violations,
+		"requirements": p.Requirements(),
+	})
+	return false
+}
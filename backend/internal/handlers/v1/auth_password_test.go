@@ -0,0 +1,151 @@
+package v1_test
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/password"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAuthHandler_PasswordPolicyAndChange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupUsersDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AuthToken{}))
+
+	sum := sha1.Sum([]byte("Tr0ub4dor&3"))
+	list := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(list, []byte("# test list\n"+strings.ToUpper(hex.EncodeToString(sum[:]))+":42\n"), 0o600))
+
+	cfg := &config.Config{
+		App: config.AppConfig{BaseURL: "http://app.local"},
+		Auth: config.AuthConfig{
+			JWT:              config.JWTConfig{Secret: "test-secret", AccessTokenTTL: time.Hour, RefreshTokenTTL: time.Hour},
+			PasswordResetTTL: time.Hour,
+			Password:         config.PasswordConfig{MinLength: 10, MinCharacterClasses: 3, BcryptCost: bcrypt.MinCost, BreachedList: list},
+		},
+	}
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	u := models.User{Email: "jane@corp.tld", Name: "Jane Doe", Role: "founder", PasswordHash: string(hash), EmailVerified: true}
+	require.NoError(t, db.Create(&u).Error)
+	pair, err := auth.GenerateTokenPair(cfg, u.ID, u.Email, u.Role, u.TokenVersion)
+	require.NoError(t, err)
+	access := &http.Cookie{Name: "access_token", Value: pair.AccessToken}
+
+	mailer := &recordingMailer{}
+	h := v1.NewAuthHandler(cfg, db, logrus.New(), mailer, password.NewPolicy(cfg.Auth.Password, nil))
+	r := gin.New()
+	r.POST("/auth/register", h.Register)
+	r.GET("/auth/password-policy", h.PasswordPolicy)
+	r.POST("/auth/forgot-password", h.ForgotPassword)
+	r.POST("/auth/reset-password", h.ResetPassword)
+	r.POST("/auth/refresh", h.Refresh)
+	r.POST("/users/me/password", middleware.AuthRequired(cfg), h.ChangePassword)
+
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(access)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	violations := func(w *httptest.ResponseRecorder) []string {
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		var body struct {
+			Code    string `json:"code"`
+			Details struct {
+				Violations []string `json:"violations"`
+			} `json:"details"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "weak_password", body.Code)
+		return body.Details.Violations
+	}
+
+	w := call(http.MethodGet, "/auth/password-policy", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"min_length":10`)
+	assert.Contains(t, w.Body.String(), `"breached_check":true`)
+
+	register := `{"email":"new@corp.tld","name":"Newcomer","password":"%s"}`
+	assert.ElementsMatch(t, []string{"too_short", "too_few_character_classes"},
+		violations(call(http.MethodPost, "/auth/register", strings.Replace(register, "%s", "short", 1))))
+	assert.Equal(t, []string{"contains_personal_info"},
+		violations(call(http.MethodPost, "/auth/register", strings.Replace(register, "%s", "Newcomer-2024!", 1))))
+	assert.Equal(t, []string{"breached"},
+		violations(call(http.MethodPost, "/auth/register", strings.Replace(register, "%s", "Tr0ub4dor&3", 1))))
+
+	// Open sign-up grants the basic role, whatever role the client asks for
+	w = call(http.MethodPost, "/auth/register", `{"email":"new@corp.tld","name":"Newcomer","role":"investor","password":"Blue-Pencil-42"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var registered models.User
+	require.NoError(t, db.Where("email = ?", "new@corp.tld").First(&registered).Error)
+	assert.Equal(t, "user", registered.Role)
+	assert.Nil(t, registered.InvestorID)
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/refresh", nil)
+		req.AddCookie(&http.Cookie{Name: "refresh_token", Value: token})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	require.Equal(t, http.StatusNoContent, refresh(pair.RefreshToken).Code)
+	require.NoError(t, db.Create(&models.AuthToken{UserID: u.ID, TokenHash: "pending", TokenType: "verify", ExpiresAt: time.Now().Add(time.Hour)}).Error)
+
+	// Change password re-authenticates and applies the policy
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, "/users/me/password", `{"current_password":"nope","new_password":"c0rrect-Horse-battery"}`).Code)
+	assert.Equal(t, []string{"contains_personal_info"},
+		violations(call(http.MethodPost, "/users/me/password", `{"current_password":"secret123","new_password":"Jane-Doe-1234"}`)))
+	w = call(http.MethodPost, "/users/me/password", `{"current_password":"secret123","new_password":"c0rrect-Horse-battery"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, db.First(&u, u.ID).Error)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte("c0rrect-Horse-battery")))
+	assert.Equal(t, uint64(1), u.TokenVersion)
+
+	// Other sessions and pending links are revoked, the current session got fresh cookies
+	w2 := refresh(pair.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, w2.Code)
+	assert.Contains(t, w2.Body.String(), "session_revoked")
+	var fresh string
+	for _, ck := range w.Result().Cookies() {
+		if ck.Name == "refresh_token" {
+			fresh = ck.Value
+		}
+	}
+	require.NotEmpty(t, fresh)
+	assert.Equal(t, http.StatusNoContent, refresh(fresh).Code)
+	var pending int64
+	require.NoError(t, db.Model(&models.AuthToken{}).Where("user_id = ?", u.ID).Count(&pending).Error)
+	assert.Zero(t, pending)
+	require.NotNil(t, mailer.last("jane@corp.tld"))
+	assert.Equal(t, "Your password was changed", mailer.last("jane@corp.tld").Subject)
+
+	// A rejected reset keeps the token usable
+	require.Equal(t, http.StatusOK, call(http.MethodPost, "/auth/forgot-password", `{"email":"jane@corp.tld"}`).Code)
+	mail := mailer.last("jane@corp.tld")
+	require.NotNil(t, mail)
+	token := tokenLinkPattern.FindStringSubmatch(mail.Body)[1]
+	violations(call(http.MethodPost, "/auth/reset-password", `{"token":"`+token+`","new_password":"Tr0ub4dor&3"}`))
+	require.Equal(t, http.StatusOK, call(http.MethodPost, "/auth/reset-password", `{"token":"`+token+`","new_password":"An0ther-long-one"}`).Code)
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, "/auth/reset-password", `{"token":"`+token+`","new_password":"An0ther-long-one"}`).Code)
+	assert.Equal(t, http.StatusUnauthorized, refresh(fresh).Code, "a reset signs out every session")
+}
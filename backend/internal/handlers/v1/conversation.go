@@ -6,19 +6,30 @@ import (
 	"net/http"
 	"slices"
 	"strconv"
+	"strings"
 
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/attachments"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/http/pagination"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/storage/s3"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type ConversationsHandler struct {
-	db  *gorm.DB
-	log *logrus.Logger
+	cfg         *config.Config
+	db          *gorm.DB
+	log         *logrus.Logger
+	broker      realtime.Broker
+	media       s3.ObjectStore
+	attachments attachments.Policy
+	upgrader    websocket.Upgrader
 }
 
 var validConversationSortFields = []string{
@@ -27,38 +38,73 @@ var validConversationSortFields = []string{
 	"updated_at",
 }
 
-func NewConversationsHandler(db *gorm.DB, log *logrus.Logger) *ConversationsHandler {
+// NewConversationsHandler creates the handler. broker may be nil, in which case no real-time events are pushed,
+// and so may media, in which case attachments are disabled
+func NewConversationsHandler(cfg *config.Config, db *gorm.DB, log *logrus.Logger, broker realtime.Broker, media s3.ObjectStore) *ConversationsHandler {
 	return &ConversationsHandler{
-		db:  db,
-		log: log,
+		cfg:         cfg,
+		db:          db,
+		log:         log,
+		broker:      broker,
+		media:       media,
+		attachments: attachments.NewPolicy(cfg.Messaging.Attachments),
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     middleware.WebSocketOriginChecker(cfg),
+		},
 	}
 }
 
+// isUserParticipant reports whether userID currently takes part in the conversation; those who left lose access
 func (h *ConversationsHandler) isUserParticipant(userID uint64, conversationID string) bool {
 	var count int64
 
 	h.db.Model(&models.ConversationParticipant{}).
-		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", conversationID, userID).
 		Count(&count)
 	return count > 0
 }
 
 func (h *ConversationsHandler) getUnreadCount(userID, conversationID uint64) int {
-	var participant models.ConversationParticipant
-	if err := h.db.Where("conversation_id = ? AND user_id = ?", conversationID, userID).
-		First(&participant).Error; err != nil {
-		return 0
-	}
-
-	var count int64
-	query := h.db.Model(&models.Message{}).
-		Where("conversation_id = ? AND deleted_at IS NULL", conversationID)
+	return h.unreadCounts(userID, []uint64{conversationID})[conversationID]
+}
 
-	if participant.LastReadMessageID != nil {
-		query = query.Where("id > ?", *participant.LastReadMessageID)
-	}
+// unreadCounts counts the unread messages of userID in each of conversationIDs with a single query
+func (h *ConversationsHandler) unreadCounts(userID uint64, conversationIDs []uint64) map[uint64]int {
+	counts := make(map[uint64]int, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return counts
+	}
+	var rows []struct {
+		ConversationID uint64
+		Unread         int
+	}
+	if err := h.db.Table("conversation_participants cp").
+		Select("cp.conversation_id, COUNT(m.id) AS unread").
+		Joins("LEFT JOIN messages m ON m.conversation_id = cp.conversation_id AND m.deleted_at IS NULL AND (cp.last_read_message_id IS NULL OR m.id > cp.last_read_message_id) "+
+			"AND m.sender_id <> cp.user_id AND m.sender_id NOT IN (SELECT blocked_id FROM user_blocks WHERE blocker_id = cp.user_id)").
+		Where("cp.user_id = ? AND cp.left_at IS NULL AND cp.conversation_id IN ?", userID, conversationIDs).
+		Group("cp.conversation_id").
+		Scan(&rows).Error; err != nil {
+		h.log.WithError(err).Error("failed to count unread messages")
+		return counts
+	}
+	for _, row := range rows {
+		counts[row.ConversationID] = row.Unread
+	}
+	return counts
+}
 
-	query.Count(&count)
+// getTotalUnreadCount counts the unread messages of userID across all their conversations, muted ones aside
+func (h *ConversationsHandler) getTotalUnreadCount(userID uint64) int {
+	var count int64
+	h.db.Model(&models.Message{}).
+		Joins("JOIN conversation_participants cp ON cp.conversation_id = messages.conversation_id").
+		Where("cp.user_id = ? AND cp.left_at IS NULL AND NOT cp.muted AND messages.deleted_at IS NULL AND messages.sender_id <> cp.user_id", userID).
+		Where("messages.sender_id NOT IN (SELECT blocked_id FROM user_blocks WHERE blocker_id = ?)", userID).
+		Where("cp.last_read_message_id IS NULL OR messages.id > cp.last_read_message_id").
+		Count(&count)
 	return int(count)
 }
 
@@ -71,6 +117,8 @@ func (h *ConversationsHandler) getUnreadCount(userID, conversationID uint64) int
 // @Param        per_page  query int    false "Page size" default(20)
 // @Param        sort      query string false "Sort field" Enums(id,created_at,updated_at) default(updated_at)
 // @Param        order     query string false "Sort order" Enums(asc,desc) default(desc)
+// @Param        context_type  query string false "Only conversations about this kind of subject" Enums(startup,opportunity)
+// @Param        context_id    query int    false "Only conversations about this startup or opportunity, with context_type"
 // @Success      200 {object} response.ConversationsWithUnreadResponse
 // @Failure      400 {object} response.ErrorBody
 // @Failure      401 {object} response.ErrorBody
@@ -96,10 +144,27 @@ func (h *ConversationsHandler) GetConversations(c *gin.Context) {
 	var conversations []models.Conversation
 	query := h.db.
 		Joins("JOIN conversation_participants cp ON cp.conversation_id = conversations.id").
-		Where("cp.user_id = ?", claims.UserID).
+		Where("cp.user_id = ? AND cp.left_at IS NULL", claims.UserID).
+		Preload("Participants", "left_at IS NULL").
 		Preload("Participants.User").
 		Preload("LastMessage.Sender")
 
+	if contextType := c.Query("context_type"); contextType != "" {
+		if contextType != models.ConversationContextStartup && contextType != models.ConversationContextOpportunity {
+			response.JSONError(c, http.StatusBadRequest, "invalid_params", "context_type must be startup or opportunity", nil)
+			return
+		}
+		query = query.Where("conversations.context_type = ?", contextType)
+	}
+	contextID, err := optionalID(c.Query("context_id"))
+	if err != nil || (contextID > 0 && c.Query("context_type") == "") {
+		response.JSONError(c, http.StatusBadRequest, "invalid_params", "context_id requires a valid context_type", nil)
+		return
+	}
+	if contextID > 0 {
+		query = query.Where("conversations.context_id = ?", contextID)
+	}
+
 	var total int64
 	if err := query.Model(&models.Conversation{}).Count(&total).Error; err != nil {
 		h.log.WithError(err).Error("failed to count conversations")
@@ -116,12 +181,16 @@ func (h *ConversationsHandler) GetConversations(c *gin.Context) {
 		return
 	}
 
+	ids := make([]uint64, len(conversations))
+	for i, conv := range conversations {
+		ids[i] = conv.ID
+	}
+	unread := h.unreadCounts(claims.UserID, ids)
 	result := make([]response.ConversationWithUnreadCountResponse, 0)
 	for _, conv := range conversations {
-		unreadCount := h.getUnreadCount(claims.UserID, conv.ID)
 		result = append(result, response.ConversationWithUnreadCountResponse{
 			Data:        conv,
-			UnreadCount: unreadCount,
+			UnreadCount: unread[conv.ID],
 		})
 	}
 
@@ -143,7 +212,7 @@ func (h *ConversationsHandler) GetConversations(c *gin.Context) {
 
 // GetConversation godoc
 // @Summary      Get conversation
-// @Description  Retrieves a conversation by ID with messages.
+// @Description  Retrieves a conversation by ID with messages, leaving out those sent by users the caller blocked.
 // @Tags         Conversations
 // @Security     CookieAuth
 // @Param        id path int true "Conversation ID"
@@ -171,8 +240,11 @@ func (h *ConversationsHandler) GetConversation(c *gin.Context) {
 
 	var conversation models.Conversation
 	if err := h.db.
+		Preload("Participants", "left_at IS NULL").
 		Preload("Participants.User").
+		Preload("Messages", "deleted_at IS NULL AND sender_id NOT IN (SELECT blocked_id FROM user_blocks WHERE blocker_id = ?)", claims.UserID).
 		Preload("Messages.Sender").
+		Preload("Messages.Attachments").
 		Where("id = ?", id).
 		First(&conversation).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -191,12 +263,14 @@ func (h *ConversationsHandler) GetConversation(c *gin.Context) {
 
 // CreateConversation godoc
 // @Summary      Create conversation
-// @Description  Creates a new conversation with specified participants.
+// @Description  Creates a new conversation with specified participants, optionally about a startup or an opportunity (context_type and context_id).
+// @Description  One-to-one conversations are idempotent: when the two users already have a conversation about the same context, it is returned with 200 instead.
 // @Tags         Conversations
 // @Security     CookieAuth
 // @Accept       json
 // @Produce      json
-// @Param        payload body requests.ConversationCreateRequest true "Conversation" Example({"participant_ids":[1,2],"title":"Project Discussion","is_group":false})
+// @Param        payload body requests.ConversationCreateRequest true "Conversation" Example({"participant_ids":[2],"is_group":false,"context_type":"startup","context_id":12})
+// @Success      200 {object} response.ConversationObjectResponse
 // @Success      201 {object} response.ConversationObjectResponse
 // @Failure      400 {object} response.ErrorBody
 // @Failure      401 {object} response.ErrorBody
@@ -214,6 +288,8 @@ func (h *ConversationsHandler) CreateConversation(c *gin.Context) {
 		ParticipantIDs []uint64 `json:"participant_ids" binding:"required,min=1"`
 		Title          *string  `json:"title,omitempty"`
 		IsGroup        bool     `json:"is_group"`
+		ContextType    *string  `json:"context_type,omitempty"`
+		ContextID      *uint64  `json:"context_id,omitempty"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -225,6 +301,8 @@ func (h *ConversationsHandler) CreateConversation(c *gin.Context) {
 	if !slices.Contains(req.ParticipantIDs, claims.UserID) {
 		req.ParticipantIDs = append(req.ParticipantIDs, claims.UserID)
 	}
+	slices.Sort(req.ParticipantIDs)
+	req.ParticipantIDs = slices.Compact(req.ParticipantIDs)
 
 	var userCount int64
 	if err := h.db.Model(&models.User{}).Where("id IN ?", req.ParticipantIDs).Count(&userCount).Error; err != nil {
@@ -240,43 +318,73 @@ func (h *ConversationsHandler) CreateConversation(c *gin.Context) {
 		return
 	}
 
-	conversation := models.Conversation{
-		Title:   req.Title,
-		IsGroup: req.IsGroup,
+	if !h.validContext(c, req.ContextType, req.ContextID) {
+		return
 	}
 
-	if err := h.db.Create(&conversation).Error; err != nil {
-		h.log.WithError(err).Error("failed to create conversation")
+	blocked, err := h.blockedBetween(claims.UserID, req.ParticipantIDs)
+	if err != nil {
+		h.log.WithError(err).Error("failed to check blocks")
 		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to create conversation", nil)
 		return
 	}
+	if blocked {
+		response.JSONError(c, http.StatusForbidden, "blocked", "you cannot start a conversation with a user you blocked or who blocked you", nil)
+		return
+	}
 
-	for i, userID := range req.ParticipantIDs {
-		role := "member"
-		if userID == claims.UserID {
-			role = "owner"
+	conversation := models.Conversation{
+		Title:       req.Title,
+		IsGroup:     req.IsGroup,
+		ContextType: req.ContextType,
+		ContextID:   req.ContextID,
+	}
+	if !req.IsGroup && len(req.ParticipantIDs) == 2 {
+		key := models.DirectKey(req.ParticipantIDs[0], req.ParticipantIDs[1], req.ContextType, req.ContextID)
+		conversation.DirectKey = &key
+		if existing, ok := h.directConversation(key); ok {
+			response.JSON(c, http.StatusOK, gin.H{"data": existing})
+			return
 		}
+	}
 
-		participant := models.ConversationParticipant{
-			ConversationID: conversation.ID,
-			UserID:         userID,
-			Role:           role,
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&conversation).Error; err != nil {
+			return err
 		}
-
-		if err := h.db.Create(&participant).Error; err != nil {
-			h.log.WithError(err).WithField("user_id", userID).Error("failed to add participant")
+		for _, userID := range req.ParticipantIDs {
+			role := models.ParticipantRoleMember
+			if userID == claims.UserID {
+				role = models.ParticipantRoleOwner
+			}
+			participant := models.ConversationParticipant{
+				ConversationID: conversation.ID,
+				UserID:         userID,
+				Role:           role,
+			}
+			if err := tx.Create(&participant).Error; err != nil {
+				return err
+			}
 		}
-
-		if i == 0 {
-			conversation.Participants = []models.ConversationParticipant{participant}
-		} else {
-			conversation.Participants = append(conversation.Participants, participant)
+		return nil
+	})
+	if err != nil {
+		// Lost a race against the same request: the unique direct key kept a single thread
+		if conversation.DirectKey != nil {
+			if existing, ok := h.directConversation(*conversation.DirectKey); ok {
+				response.JSON(c, http.StatusOK, gin.H{"data": existing})
+				return
+			}
 		}
+		h.log.WithError(err).Error("failed to create conversation")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to create conversation", nil)
+		return
 	}
 
 	if err := h.db.Preload("Participants.User").First(&conversation, conversation.ID).Error; err != nil {
 		h.log.WithError(err).Error("failed to reload conversation")
 	}
+	h.publish(c.Request.Context(), realtime.EventConversationCreated, conversation.ID, h.participantIDs(conversation.ID, 0), conversation)
 
 	response.JSON(c, http.StatusCreated, gin.H{
 		"message": "conversation created successfully",
@@ -284,15 +392,72 @@ func (h *ConversationsHandler) CreateConversation(c *gin.Context) {
 	})
 }
 
+// directConversation returns the one-to-one conversation with the given key, if any
+func (h *ConversationsHandler) directConversation(key string) (models.Conversation, bool) {
+	var conversation models.Conversation
+	err := h.db.
+		Preload("Participants", "left_at IS NULL").
+		Preload("Participants.User").
+		Preload("LastMessage.Sender").
+		Where("direct_key = ?", key).
+		First(&conversation).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			h.log.WithError(err).Error("failed to look up direct conversation")
+		}
+		return conversation, false
+	}
+	return conversation, true
+}
+
+// validContext checks that a conversation context is either absent or complete and refers to an
+// existing record, and responds with the error otherwise
+func (h *ConversationsHandler) validContext(c *gin.Context, contextType *string, contextID *uint64) bool {
+	if contextType == nil && contextID == nil {
+		return true
+	}
+	if contextType == nil || contextID == nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_context", "context_type and context_id go together", nil)
+		return false
+	}
+	var target interface{}
+	switch *contextType {
+	case models.ConversationContextStartup:
+		target = &models.Startup{}
+	case models.ConversationContextOpportunity:
+		target = &models.Opportunity{}
+	default:
+		response.JSONError(c, http.StatusBadRequest, "invalid_context", "context_type must be startup or opportunity", nil)
+		return false
+	}
+	var count int64
+	if err := h.db.Model(target).Where("id = ?", *contextID).Count(&count).Error; err != nil {
+		h.log.WithError(err).Error("failed to validate conversation context")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to validate context", nil)
+		return false
+	}
+	if count == 0 {
+		response.JSONError(c, http.StatusBadRequest, "invalid_context", fmt.Sprintf("%s %d does not exist", *contextType, *contextID), nil)
+		return false
+	}
+	return true
+}
+
 // GetMessages godoc
 // @Summary      Get conversation messages
-// @Description  Returns paginated messages for a conversation.
+// @Description  Returns messages of a conversation in chronological order, per_page at a time. latest returns the newest messages; before returns the messages preceding a message ID (scrolling back) and after the ones following it (catching up).
+// @Description  The cursors object gives the IDs to pass as before and after for the adjacent pages. Without latest, before or after, the deprecated offset pagination applies: the page-th page from the oldest message, with a pagination object instead of cursors.
+// @Description  Messages sent by users the caller blocked are left out.
 // @Tags         Conversations
 // @Security     CookieAuth
 // @Param        id        path  int false "Conversation ID"
-// @Param        page      query int false "Page" default(1)
+// @Param        before    query int false "Return messages older than this message ID"
+// @Param        after     query int false "Return messages newer than this message ID"
+// @Param        latest    query bool false "Return the newest messages"
 // @Param        per_page  query int false "Page size" default(20)
-// @Success      200 {object} response.MessageListResponse
+// @Param        page      query int false "Page (deprecated offset pagination)"
+// @Success      200 {object} response.MessageCursorListResponse
+// @Failure      400 {object} response.ErrorBody
 // @Failure      401 {object} response.ErrorBody
 // @Failure      403 {object} response.ErrorBody
 // @Failure      404 {object} response.ErrorBody
@@ -315,10 +480,77 @@ func (h *ConversationsHandler) GetMessages(c *gin.Context) {
 	}
 
 	params := pagination.Parse(c)
+	before, errBefore := optionalID(c.Query("before"))
+	after, errAfter := optionalID(c.Query("after"))
+	if errBefore != nil || errAfter != nil || (before > 0 && after > 0) {
+		response.JSONError(c, http.StatusBadRequest, "invalid_cursor", "before and after must be message IDs, and cannot be combined", nil)
+		return
+	}
+	latest := false
+	if raw := c.Query("latest"); raw != "" {
+		var err error
+		if latest, err = strconv.ParseBool(raw); err != nil {
+			response.JSONError(c, http.StatusBadRequest, "invalid_cursor", "latest must be a boolean", nil)
+			return
+		}
+	}
+	// Cursors are opt-in so that clients of the offset pagination keep the response they expect
+	if !latest && before == 0 && after == 0 {
+		h.getMessagesPage(c, id, claims.UserID, params)
+		return
+	}
+
+	// One extra row tells whether another page follows in the direction of travel
+	query := h.visibleMessages(id, claims.UserID).
+		Preload("Sender").
+		Preload("Attachments").
+		Limit(params.PerPage + 1)
+	if after > 0 {
+		query = query.Where("id > ?", after).Order("id ASC")
+	} else {
+		if before > 0 {
+			query = query.Where("id < ?", before)
+		}
+		query = query.Order("id DESC")
+	}
+	var messages []models.Message
+	if err := query.Find(&messages).Error; err != nil {
+		h.log.WithError(err).Error("failed to fetch messages")
+		response.JSONError(c, http.StatusInternalServerError,
+			"internal_error", "failed to retrieve messages", nil)
+		return
+	}
+	more := len(messages) > params.PerPage
+	if more {
+		messages = messages[:params.PerPage]
+	}
+	if after == 0 {
+		slices.Reverse(messages)
+	}
+
+	cursors := gin.H{"before": nil, "after": nil, "has_before": false, "has_after": false}
+	if len(messages) > 0 {
+		first, last := messages[0].ID, messages[len(messages)-1].ID
+		cursors["before"], cursors["after"] = first, last
+		if after > 0 {
+			cursors["has_after"], cursors["has_before"] = more, h.messageExists(id, claims.UserID, "id < ?", first)
+		} else {
+			cursors["has_before"], cursors["has_after"] = more, before > 0 && h.messageExists(id, claims.UserID, "id > ?", last)
+		}
+	}
+
+	response.JSON(c, http.StatusOK, gin.H{
+		"data":    messages,
+		"cursors": cursors,
+	})
+}
+
+// getMessagesPage serves the deprecated offset pagination of GetMessages
+func (h *ConversationsHandler) getMessagesPage(c *gin.Context, id string, viewerID uint64, params pagination.Params) {
 	offset := (params.Page - 1) * params.PerPage
 
 	var total int64
-	if err := h.db.Model(&models.Message{}).Where("conversation_id = ? AND deleted_at IS NULL", id).Count(&total).Error; err != nil {
+	if err := h.visibleMessages(id, viewerID).Count(&total).Error; err != nil {
 		h.log.WithError(err).Error("failed to count messages")
 		response.JSONError(c, http.StatusInternalServerError,
 			"internal_error", "failed to retrieve messages count", nil)
@@ -326,9 +558,9 @@ func (h *ConversationsHandler) GetMessages(c *gin.Context) {
 	}
 
 	var messages []models.Message
-	if err := h.db.
+	if err := h.visibleMessages(id, viewerID).
 		Preload("Sender").
-		Where("conversation_id = ? AND deleted_at IS NULL", id).
+		Preload("Attachments").
 		Order("created_at ASC").
 		Offset(offset).
 		Limit(params.PerPage).
@@ -355,15 +587,41 @@ func (h *ConversationsHandler) GetMessages(c *gin.Context) {
 	})
 }
 
+// visibleMessages selects the messages of the conversation viewerID gets to see: not deleted, and not sent by
+// a user they blocked, as in the unread counts
+func (h *ConversationsHandler) visibleMessages(conversationID string, viewerID uint64) *gorm.DB {
+	return h.db.Model(&models.Message{}).
+		Where("conversation_id = ? AND deleted_at IS NULL", conversationID).
+		Where("sender_id NOT IN (?)", h.db.Model(&models.UserBlock{}).Select("blocked_id").Where("blocker_id = ?", viewerID))
+}
+
+// messageExists reports whether the conversation has a message visible to viewerID matching cond
+func (h *ConversationsHandler) messageExists(conversationID string, viewerID uint64, cond string, args ...interface{}) bool {
+	var ids []uint64
+	h.visibleMessages(conversationID, viewerID).
+		Where(cond, args...).
+		Limit(1).
+		Pluck("id", &ids)
+	return len(ids) > 0
+}
+
+// optionalID parses an optional ID query parameter, 0 meaning absent
+func optionalID(raw string) (uint64, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(raw, 10, 64)
+}
+
 // SendMessage godoc
 // @Summary      Send message
-// @Description  Sends a message to a conversation.
+// @Description  Sends a message to a conversation. Files are first uploaded with POST /conversations/{id}/attachments, then sent through attachment_ids.
 // @Tags         Conversations
 // @Security     CookieAuth
 // @Accept       json
 // @Produce      json
 // @Param        id      path int true "Conversation ID"
-// @Param        payload body requests.MessageSendRequest true "Message" Example({"content":"Hello, how are you?"})
+// @Param        payload body requests.MessageSendRequest true "Message" Example({"content":"Here is our deck","attachment_ids":[12]})
 // @Success      201 {object} response.MessageObjectResponse
 // @Failure      400 {object} response.ErrorBody
 // @Failure      401 {object} response.ErrorBody
@@ -390,22 +648,65 @@ func (h *ConversationsHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
+	// Groups still take the message; it is just not delivered to those who blocked the sender
+	var conversation models.Conversation
+	if err := h.db.Select("id", "is_group").First(&conversation, conversationID).Error; err != nil {
+		h.log.WithError(err).Error("failed to fetch conversation")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to send message", nil)
+		return
+	}
+	if !conversation.IsGroup {
+		blocked, err := h.blockedBetween(claims.UserID, h.participantIDs(conversationID, claims.UserID))
+		if err != nil {
+			h.log.WithError(err).Error("failed to check blocks")
+			response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to send message", nil)
+			return
+		}
+		if blocked {
+			response.JSONError(c, http.StatusForbidden, "blocked", "you cannot message a user you blocked or who blocked you", nil)
+			return
+		}
+	}
+
 	var req struct {
-		Content string `json:"content" binding:"required,max=2000"`
+		Content       string   `json:"content" binding:"max=2000"`
+		AttachmentIDs []uint64 `json:"attachment_ids,omitempty"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
 		return
 	}
+	slices.Sort(req.AttachmentIDs)
+	req.AttachmentIDs = slices.Compact(req.AttachmentIDs)
+	if strings.TrimSpace(req.Content) == "" && len(req.AttachmentIDs) == 0 {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "a message needs content or attachments", nil)
+		return
+	}
+	if len(req.AttachmentIDs) > h.attachments.MaxPerMessage {
+		response.JSONError(c, http.StatusBadRequest, "too_many_attachments",
+			fmt.Sprintf("a message can carry at most %d attachments", h.attachments.MaxPerMessage), nil)
+		return
+	}
 
 	message := models.Message{
 		ConversationID: conversationID,
 		SenderID:       claims.UserID,
 		Content:        req.Content,
+		Kind:           models.MessageKindText,
 	}
 
-	if err := h.db.Create(&message).Error; err != nil {
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+		return linkAttachments(tx, conversationID, claims.UserID, message.ID, req.AttachmentIDs)
+	})
+	if errors.Is(err, errInvalidAttachments) {
+		response.JSONError(c, http.StatusBadRequest, "invalid_attachments", "attachments must be your own unsent uploads to this conversation", nil)
+		return
+	}
+	if err != nil {
 		h.log.WithError(err).Error("failed to create message")
 		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to send message", nil)
 		return
@@ -415,9 +716,11 @@ func (h *ConversationsHandler) SendMessage(c *gin.Context) {
 		h.log.WithError(err).Error("failed to update conversation last_message_id")
 	}
 
-	if err := h.db.Preload("Sender").First(&message, message.ID).Error; err != nil {
+	if err := h.db.Preload("Sender").Preload("Attachments").First(&message, message.ID).Error; err != nil {
 		h.log.WithError(err).Error("failed to reload message with sender")
 	}
+	h.publish(c.Request.Context(), realtime.EventMessageCreated, conversationID, h.recipientIDs(conversationID, claims.UserID, false), message)
+	h.publishUnread(c.Request.Context(), conversationID, h.recipientIDs(conversationID, claims.UserID, true)...)
 
 	response.JSON(c, http.StatusCreated, gin.H{
 		"message": "message sent successfully",
@@ -438,6 +741,7 @@ func (h *ConversationsHandler) SendMessage(c *gin.Context) {
 // @Failure      400 {object} response.ErrorBody
 // @Failure      401 {object} response.ErrorBody
 // @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
 // @Failure      500 {object} response.ErrorBody
 // @Router       /conversations/{id}/mark-read [post]
 func (h *ConversationsHandler) MarkMessageRead(c *gin.Context) {
@@ -472,24 +776,17 @@ func (h *ConversationsHandler) MarkMessageRead(c *gin.Context) {
 		return
 	}
 
-	if err := h.db.Model(&models.ConversationParticipant{}).
-		Where("conversation_id = ? AND user_id = ?", conversationID, claims.UserID).
-		Update("last_read_message_id", req.MessageID).Error; err != nil {
+	if err := h.markRead(c.Request.Context(), claims.UserID, conversationID, req.MessageID); errors.Is(err, errMessageNotInConversation) {
+		response.JSONError(c, http.StatusNotFound,
+			"not_found", "message not found in this conversation", nil)
+		return
+	} else if err != nil {
 		h.log.WithError(err).Error("failed to update last read message")
 		response.JSONError(c, http.StatusInternalServerError,
 			"internal_error", "failed to mark message as read", nil)
 		return
 	}
 
-	messageRead := models.MessageRead{
-		MessageID: req.MessageID,
-		UserID:    claims.UserID,
-	}
-
-	if err := h.db.Create(&messageRead).Error; err != nil {
-		h.log.WithError(err).Debug("failed to create message read record (might already exist)")
-	}
-
 	response.JSON(c, http.StatusOK, gin.H{
 		"message": "message marked as read",
 	})
@@ -0,0 +1,202 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/attachments"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// multipartOverhead is the room left for the multipart envelope around an uploaded file
+const multipartOverhead = 64 << 10
+
+var errInvalidAttachments = errors.New("invalid attachments")
+
+// UploadAttachment godoc
+// @Summary      Upload attachment
+// @Description  Uploads a file to the conversation. The returned attachment is only visible to its uploader until its ID is sent in the attachment_ids of a message; unsent uploads are removed after messaging.attachments.purge_after.
+// @Description  Size and types are limited by messaging.attachments (10 MiB of PDF, images and Office documents by default).
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id   path     int  true "Conversation ID"
+// @Param        file formData file true "File"
+// @Success      201 {object} response.MessageAttachmentObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      413 {object} response.ErrorBody
+// @Failure      415 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Failure      503 {object} response.ErrorBody
+// @Router       /conversations/{id}/attachments [post]
+func (h *ConversationsHandler) UploadAttachment(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_id", "invalid conversation id", nil)
+		return
+	}
+	if !h.isUserParticipant(claims.UserID, c.Param("id")) {
+		response.JSONError(c, http.StatusForbidden, "forbidden", "not a participant", nil)
+		return
+	}
+	if h.media == nil {
+		response.JSONError(c, http.StatusServiceUnavailable, "attachments_unavailable", "attachments are not available", nil)
+		return
+	}
+
+	limit := h.attachments.MaxSize
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit+multipartOverhead)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.JSONError(c, http.StatusRequestEntityTooLarge, "file_too_large", fmt.Sprintf("files are limited to %d bytes", limit), nil)
+			return
+		}
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "missing file", nil)
+		return
+	}
+	if file.Size > limit {
+		response.JSONError(c, http.StatusRequestEntityTooLarge, "file_too_large", fmt.Sprintf("files are limited to %d bytes", limit), nil)
+		return
+	}
+	if file.Size == 0 {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "file is empty", nil)
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "unreadable file", nil)
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, limit))
+	if err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "unreadable file", nil)
+		return
+	}
+
+	contentType, err := h.attachments.ContentType(file.Header.Get("Content-Type"), data)
+	if err != nil {
+		response.JSONError(c, http.StatusUnsupportedMediaType, "unsupported_media_type", "file type not allowed", h.attachments.Types)
+		return
+	}
+	name := attachments.FileName(file.Filename)
+	key, err := attachments.Key(conversationID, name)
+	if err != nil {
+		h.log.WithError(err).Error("failed to generate attachment key")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to upload attachment", nil)
+		return
+	}
+	if _, err := h.media.Upload(c.Request.Context(), key, contentType, data); err != nil {
+		h.log.WithError(err).WithField("conversation_id", conversationID).Error("failed to upload attachment")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to upload attachment", nil)
+		return
+	}
+
+	attachment := models.MessageAttachment{
+		ConversationID: conversationID,
+		UploaderID:     claims.UserID,
+		StorageKey:     key,
+		FileName:       name,
+		ContentType:    contentType,
+		Size:           int64(len(data)),
+	}
+	if err := h.db.Create(&attachment).Error; err != nil {
+		h.log.WithError(err).Error("failed to save attachment")
+		if delErr := h.media.Delete(c.Request.Context(), key); delErr != nil {
+			h.log.WithError(delErr).WithField("key", key).Warn("failed to remove unsaved attachment")
+		}
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to upload attachment", nil)
+		return
+	}
+	response.JSON(c, http.StatusCreated, gin.H{"data": attachment})
+}
+
+// DownloadAttachment godoc
+// @Summary      Download attachment
+// @Description  Redirects to a presigned URL of the file, valid for messaging.attachments.url_ttl. Only participants can download the attachments of visible messages; unsent uploads are only available to their uploader.
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Param        id  path int true "Conversation ID"
+// @Param        aid path int true "Attachment ID"
+// @Success      302 "Redirect to the file"
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Failure      503 {object} response.ErrorBody
+// @Router       /conversations/{id}/attachments/{aid} [get]
+func (h *ConversationsHandler) DownloadAttachment(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	if !h.isUserParticipant(claims.UserID, c.Param("id")) {
+		response.JSONError(c, http.StatusForbidden, "forbidden", "not a participant", nil)
+		return
+	}
+	if h.media == nil {
+		response.JSONError(c, http.StatusServiceUnavailable, "attachments_unavailable", "attachments are not available", nil)
+		return
+	}
+
+	var attachment models.MessageAttachment
+	err := h.db.
+		Where("message_attachments.id = ? AND message_attachments.conversation_id = ?", c.Param("aid"), c.Param("id")).
+		Where(h.db.
+			Where("message_attachments.message_id IS NULL AND message_attachments.uploader_id = ?", claims.UserID).
+			Or("EXISTS (SELECT 1 FROM messages m WHERE m.id = message_attachments.message_id AND m.deleted_at IS NULL)")).
+		First(&attachment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusNotFound, "not_found", "attachment not found", nil)
+			return
+		}
+		h.log.WithError(err).Error("failed to fetch attachment")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve attachment", nil)
+		return
+	}
+
+	url, err := h.media.PresignGet(c.Request.Context(), attachment.StorageKey, h.attachments.URLTTL, attachments.Disposition(attachment))
+	if err != nil {
+		h.log.WithError(err).WithField("attachment_id", attachment.ID).Error("failed to presign attachment")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve attachment", nil)
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.Redirect(http.StatusFound, url)
+}
+
+// linkAttachments attaches the pending uploads ids of userID to a new message of the conversation
+func linkAttachments(tx *gorm.DB, conversationID, userID, messageID uint64, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	res := tx.Model(&models.MessageAttachment{}).
+		Where("id IN ? AND conversation_id = ? AND uploader_id = ? AND message_id IS NULL", ids, conversationID, userID).
+		Update("message_id", messageID)
+	if res.Error != nil {
+		return fmt.Errorf("link attachments: %w", res.Error)
+	}
+	if res.RowsAffected != int64(len(ids)) {
+		return errInvalidAttachments
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package v1_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an s3.ObjectStore keeping objects in memory
+type memoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemoryStore() *memoryStore { return &memoryStore{objects: make(map[string][]byte)} }
+
+func (s *memoryStore) Upload(_ context.Context, key, _ string, data []byte) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return "https://public.example/" + key, nil
+}
+
+func (s *memoryStore) PresignGet(_ context.Context, key string, ttl time.Duration, disposition string) (string, error) {
+	return fmt.Sprintf("https://bucket.example/%s?ttl=%s&disposition=%s", key, ttl, disposition), nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memoryStore) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for k := range s.objects {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func multipartFile(t *testing.T, name, contentType string, data []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	header := textproto.MIMEHeader{}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, escaped))
+	header.Set("Content-Type", contentType)
+	part, err := w.CreatePart(header)
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return body, w.FormDataContentType()
+}
+
+func TestConversationsHandler_Attachments(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupConversationsDB(t)
+	cfg := &config.Config{
+		Auth: config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret"}},
+		Messaging: config.MessagingConfig{Attachments: config.AttachmentsConfig{
+			MaxSize:       1024,
+			AllowedTypes:  []string{"application/pdf", "image/png"},
+			MaxPerMessage: 2,
+			URLTTL:        time.Minute,
+		}},
+	}
+	store := newMemoryStore()
+	h := v1.NewConversationsHandler(cfg, db, logrus.New(), nil, store)
+	r := gin.New()
+	r.Use(middleware.AuthRequired(cfg))
+	r.GET("/conversations/:id/messages", h.GetMessages)
+	r.POST("/conversations/:id/messages", h.SendMessage)
+	r.DELETE("/conversations/:id/messages/:mid", h.DeleteMessage)
+	r.POST("/conversations/:id/attachments", h.UploadAttachment)
+	r.GET("/conversations/:id/attachments/:aid", h.DownloadAttachment)
+
+	for _, u := range []models.User{
+		{ID: 1, Email: "user1@test.com", Name: "User 1", Role: "founder"},
+		{ID: 2, Email: "user2@test.com", Name: "User 2", Role: "investor"},
+		{ID: 3, Email: "user3@test.com", Name: "User 3", Role: "investor"},
+	} {
+		require.NoError(t, db.Create(&u).Error)
+	}
+	conv := models.Conversation{}
+	require.NoError(t, db.Create(&conv).Error)
+	for _, id := range []uint64{1, 2} {
+		require.NoError(t, db.Create(&models.ConversationParticipant{ConversationID: conv.ID, UserID: id, Role: "member"}).Error)
+	}
+
+	do := func(userID uint64, method, path string, body *bytes.Buffer, contentType string) *httptest.ResponseRecorder {
+		if body == nil {
+			body = &bytes.Buffer{}
+		}
+		req := httptest.NewRequest(method, path, body)
+		req.Header.Set("Content-Type", contentType)
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(userID, fmt.Sprintf("user%d@test.com", userID), "founder")})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	upload := func(userID uint64, name, contentType string, data []byte) *httptest.ResponseRecorder {
+		body, ct := multipartFile(t, name, contentType, data)
+		return do(userID, http.MethodPost, fmt.Sprintf("/conversations/%d/attachments", conv.ID), body, ct)
+	}
+	uploadPath := fmt.Sprintf("/conversations/%d/attachments", conv.ID)
+	pdf := []byte("%PDF-1.4\n% deck\n")
+
+	// Limits are enforced on size, declared type and actual content
+	assert.Equal(t, http.StatusForbidden, upload(3, "deck.pdf", "application/pdf", pdf).Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, upload(1, "big.pdf", "application/pdf", append(pdf, bytes.Repeat([]byte("x"), 2048)...)).Code)
+	assert.Equal(t, http.StatusUnsupportedMediaType, upload(1, "deck.pptx", "application/zip", []byte("PK\x03\x04")).Code)
+	assert.Equal(t, http.StatusUnsupportedMediaType, upload(1, "cat.png", "image/png", []byte("<html><script>alert(1)</script></html>")).Code)
+	assert.Empty(t, store.keys())
+
+	w := upload(1, `..\..\Pitch "deck".pdf`, "application/pdf", pdf)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Data models.MessageAttachment `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	att := created.Data
+	assert.Equal(t, "Pitch deck.pdf", att.FileName)
+	assert.Equal(t, "application/pdf", att.ContentType)
+	assert.NotContains(t, w.Body.String(), "conversations/", "storage keys are not exposed")
+	require.Len(t, store.keys(), 1)
+	assert.True(t, strings.HasPrefix(store.keys()[0], fmt.Sprintf("conversations/%d/attachments/", conv.ID)))
+
+	// Until it is sent, only the uploader can fetch the upload
+	downloadPath := fmt.Sprintf("%s/%d", uploadPath, att.ID)
+	assert.Equal(t, http.StatusNotFound, do(2, http.MethodGet, downloadPath, nil, "").Code)
+	assert.Equal(t, http.StatusFound, do(1, http.MethodGet, downloadPath, nil, "").Code)
+
+	// Sending links the uploads of the sender only
+	sendPath := fmt.Sprintf("/conversations/%d/messages", conv.ID)
+	send := func(userID uint64, body string) *httptest.ResponseRecorder {
+		return do(userID, http.MethodPost, sendPath, bytes.NewBufferString(body), "application/json")
+	}
+	assert.Equal(t, http.StatusBadRequest, send(1, `{"content":"  "}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send(1, `{"attachment_ids":[1,2,3]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send(2, fmt.Sprintf(`{"attachment_ids":[%d]}`, att.ID)).Code)
+	w = send(1, fmt.Sprintf(`{"attachment_ids":[%d,%d]}`, att.ID, att.ID))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"file_name":"Pitch deck.pdf"`)
+	assert.Equal(t, http.StatusBadRequest, send(1, fmt.Sprintf(`{"content":"again","attachment_ids":[%d]}`, att.ID)).Code, "uploads are sent once")
+
+	w = do(2, http.MethodGet, sendPath, nil, "")
+	assert.Contains(t, w.Body.String(), `"attachments":[`)
+
+	// Participants download through a short-lived URL
+	w = do(2, http.MethodGet, downloadPath, nil, "")
+	require.Equal(t, http.StatusFound, w.Code)
+	location := w.Header().Get("Location")
+	assert.Contains(t, location, "https://bucket.example/conversations/")
+	assert.Contains(t, location, "ttl=1m0s")
+	assert.Contains(t, location, `attachment; filename="Pitch deck.pdf"`)
+	assert.Equal(t, http.StatusForbidden, do(3, http.MethodGet, downloadPath, nil, "").Code)
+
+	// Deleting the message withdraws its attachments
+	var message models.Message
+	require.NoError(t, db.Where("conversation_id = ?", conv.ID).First(&message).Error)
+	require.Equal(t, http.StatusOK, do(1, http.MethodDelete, fmt.Sprintf("%s/%d", sendPath, message.ID), nil, "").Code)
+	assert.Equal(t, http.StatusNotFound, do(2, http.MethodGet, downloadPath, nil, "").Code)
+
+	// Without a configured store, attachments are unavailable
+	h = v1.NewConversationsHandler(cfg, db, logrus.New(), nil, nil)
+	r = gin.New()
+	r.Use(middleware.AuthRequired(cfg))
+	r.POST("/conversations/:id/attachments", h.UploadAttachment)
+	assert.Equal(t, http.StatusServiceUnavailable, upload(1, "deck.pdf", "application/pdf", pdf).Code)
+}
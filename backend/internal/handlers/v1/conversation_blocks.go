@@ -0,0 +1,210 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ListBlocks godoc
+// @Summary      List blocked users
+// @Description  Returns the users the authenticated user blocked, most recent first.
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Produce      json
+// @Success      200 {object} response.UserBlockListResponse
+// @Failure      401 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /users/me/blocks [get]
+func (h *ConversationsHandler) ListBlocks(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	blocks := make([]models.UserBlock, 0)
+	if err := h.db.Preload("Blocked").Where("blocker_id = ?", claims.UserID).Order("created_at DESC, id DESC").Find(&blocks).Error; err != nil {
+		h.log.WithError(err).Error("failed to list blocks")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve blocked users", nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"data": blocks})
+}
+
+// BlockUser godoc
+// @Summary      Block a user
+// @Description  Blocks a user: they can no longer start a conversation with the authenticated user nor message them in one-to-one conversations, and their messages in groups are neither pushed nor counted as unread.
+// @Description  Blocking someone already blocked returns the existing block with 200.
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        payload body requests.UserBlockRequest true "User to block" Example({"user_id":2})
+// @Success      200 {object} response.UserBlockObjectResponse
+// @Success      201 {object} response.UserBlockObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /users/me/blocks [post]
+func (h *ConversationsHandler) BlockUser(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	var req struct {
+		UserID uint64 `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+	if req.UserID == claims.UserID {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "you cannot block yourself", nil)
+		return
+	}
+	var blocked models.User
+	if err := h.db.First(&blocked, req.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusNotFound, "not_found", "user not found", nil)
+			return
+		}
+		h.log.WithError(err).Error("failed to fetch user")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to block user", nil)
+		return
+	}
+
+	block := models.UserBlock{BlockerID: claims.UserID, BlockedID: req.UserID}
+	result := h.db.Where(&block).FirstOrCreate(&block)
+	if result.Error != nil {
+		h.log.WithError(result.Error).Error("failed to block user")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to block user", nil)
+		return
+	}
+	block.Blocked = &blocked
+	status := http.StatusOK
+	if result.RowsAffected > 0 {
+		status = http.StatusCreated
+	}
+	response.JSON(c, status, gin.H{"data": block})
+}
+
+// UnblockUser godoc
+// @Summary      Unblock a user
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Produce      json
+// @Param        uid path int true "Blocked user ID"
+// @Success      200 {object} response.MessageResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /users/me/blocks/{uid} [delete]
+func (h *ConversationsHandler) UnblockUser(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("uid"), 10, 64)
+	if err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_id", "invalid user id", nil)
+		return
+	}
+	result := h.db.Where("blocker_id = ? AND blocked_id = ?", claims.UserID, userID).Delete(&models.UserBlock{})
+	if result.Error != nil {
+		h.log.WithError(result.Error).Error("failed to unblock user")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to unblock user", nil)
+		return
+	}
+	if result.RowsAffected == 0 {
+		response.JSONError(c, http.StatusNotFound, "not_found", "user is not blocked", nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"message": "user unblocked"})
+}
+
+// MuteConversation godoc
+// @Summary      Mute conversation
+// @Description  Mutes a conversation for the authenticated user: new messages are still delivered but push no unread update and are left out of the total unread count.
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Produce      json
+// @Param        id path int true "Conversation ID"
+// @Success      200 {object} response.ConversationParticipantObjectResponse
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /conversations/{id}/mute [post]
+func (h *ConversationsHandler) MuteConversation(c *gin.Context) {
+	h.setMuted(c, true)
+}
+
+// UnmuteConversation godoc
+// @Summary      Unmute conversation
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Produce      json
+// @Param        id path int true "Conversation ID"
+// @Success      200 {object} response.ConversationParticipantObjectResponse
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /conversations/{id}/mute [delete]
+func (h *ConversationsHandler) UnmuteConversation(c *gin.Context) {
+	h.setMuted(c, false)
+}
+
+func (h *ConversationsHandler) setMuted(c *gin.Context, muted bool) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	var participant models.ConversationParticipant
+	if err := h.db.Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", c.Param("id"), claims.UserID).First(&participant).Error; err != nil {
+		response.JSONError(c, http.StatusForbidden, "forbidden", "not a participant", nil)
+		return
+	}
+	if err := h.db.Model(&participant).Update("muted", muted).Error; err != nil {
+		h.log.WithError(err).Error("failed to update conversation mute")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to update conversation", nil)
+		return
+	}
+	// The total unread count changes with the mute
+	h.publishUnread(c.Request.Context(), participant.ConversationID, claims.UserID)
+	response.JSON(c, http.StatusOK, gin.H{"data": participant})
+}
+
+// blockedBetween reports whether userID blocked, or was blocked by, any of others
+func (h *ConversationsHandler) blockedBetween(userID uint64, others []uint64) (bool, error) {
+	var count int64
+	err := h.db.Model(&models.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id IN ?) OR (blocked_id = ? AND blocker_id IN ?)", userID, others, userID, others).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// recipientIDs lists the participants a message of senderID is delivered to, leaving out those who blocked them.
+// With unread, the participants who muted the conversation and the sender are left out as well
+func (h *ConversationsHandler) recipientIDs(conversationID, senderID uint64, unread bool) []uint64 {
+	query := h.db.Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND left_at IS NULL", conversationID).
+		Where("user_id NOT IN (?)", h.db.Model(&models.UserBlock{}).Select("blocker_id").Where("blocked_id = ?", senderID))
+	if unread {
+		query = query.Where("user_id <> ? AND NOT muted", senderID)
+	}
+	var ids []uint64
+	if err := query.Pluck("user_id", &ids).Error; err != nil {
+		h.log.WithError(err).Error("failed to list message recipients")
+	}
+	return ids
+}
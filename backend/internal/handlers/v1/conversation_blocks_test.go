@@ -0,0 +1,204 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestConversationsHandler_BlocksAndMutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupConversationsDB(t)
+	cfg := &config.Config{Auth: config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret"}}}
+	broker := realtime.NewMemoryBroker(64)
+	h := v1.NewConversationsHandler(cfg, db, logrus.New(), broker, nil)
+	r := gin.New()
+	r.Use(middleware.AuthRequired(cfg))
+	r.GET("/conversations", h.GetConversations)
+	r.GET("/conversations/:id", h.GetConversation)
+	r.POST("/conversations", h.CreateConversation)
+	r.GET("/conversations/:id/messages", h.GetMessages)
+	r.POST("/conversations/:id/messages", h.SendMessage)
+	r.POST("/conversations/:id/participants", h.AddParticipants)
+	r.POST("/conversations/:id/mute", h.MuteConversation)
+	r.DELETE("/conversations/:id/mute", h.UnmuteConversation)
+	r.GET("/users/me/blocks", h.ListBlocks)
+	r.POST("/users/me/blocks", h.BlockUser)
+	r.DELETE("/users/me/blocks/:uid", h.UnblockUser)
+
+	for _, u := range []models.User{
+		{ID: 1, Email: "alice@test.com", Name: "Alice", Role: "founder"},
+		{ID: 2, Email: "bob@test.com", Name: "Bob", Role: "investor"},
+		{ID: 3, Email: "carol@test.com", Name: "Carol", Role: "investor"},
+	} {
+		require.NoError(t, db.Create(&u).Error)
+	}
+	group := models.Conversation{IsGroup: true}
+	require.NoError(t, db.Create(&group).Error)
+	for _, id := range []uint64{1, 2, 3} {
+		require.NoError(t, db.Create(&models.ConversationParticipant{ConversationID: group.ID, UserID: id}).Error)
+	}
+
+	do := func(userID uint64, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(userID, fmt.Sprintf("user%d@test.com", userID), "founder")})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	unread := func(userID uint64) int {
+		w := do(userID, http.MethodGet, "/conversations", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Data []struct {
+				Data        models.Conversation `json:"data"`
+				UnreadCount int                 `json:"unread_count"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		for _, conv := range body.Data {
+			if conv.Data.ID == group.ID {
+				return conv.UnreadCount
+			}
+		}
+		return -1
+	}
+	groupMessages := fmt.Sprintf("/conversations/%d/messages", group.ID)
+
+	// A direct conversation exists before the block
+	w := do(1, http.MethodPost, "/conversations", `{"participant_ids":[2]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Data models.Conversation `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	directMessages := fmt.Sprintf("/conversations/%d/messages", created.Data.ID)
+
+	assert.Equal(t, http.StatusBadRequest, do(1, http.MethodPost, "/users/me/blocks", `{"user_id":1}`).Code)
+	assert.Equal(t, http.StatusNotFound, do(1, http.MethodPost, "/users/me/blocks", `{"user_id":99}`).Code)
+	require.Equal(t, http.StatusCreated, do(1, http.MethodPost, "/users/me/blocks", `{"user_id":2}`).Code)
+	assert.Equal(t, http.StatusOK, do(1, http.MethodPost, "/users/me/blocks", `{"user_id":2}`).Code)
+	w = do(1, http.MethodGet, "/users/me/blocks", "")
+	assert.Contains(t, w.Body.String(), `"blocked_id":2`)
+	assert.Contains(t, w.Body.String(), `"name":"Bob"`)
+
+	// Neither side can start or continue a one-to-one conversation
+	for _, tc := range []struct {
+		userID     uint64
+		method     string
+		path, body string
+	}{
+		{2, http.MethodPost, "/conversations", `{"participant_ids":[1]}`},
+		{1, http.MethodPost, "/conversations", `{"participant_ids":[2,3],"is_group":true}`},
+		{2, http.MethodPost, directMessages, `{"content":"hello?"}`},
+		{1, http.MethodPost, directMessages, `{"content":"hello?"}`},
+	} {
+		w := do(tc.userID, tc.method, tc.path, tc.body)
+		assert.Equal(t, http.StatusForbidden, w.Code, tc.path)
+		assert.Contains(t, w.Body.String(), "blocked")
+	}
+
+	// In groups, messages of the blocked user are neither pushed nor counted for the blocker
+	alice := broker.Subscribe(1)
+	defer alice.Close()
+	carol := broker.Subscribe(3)
+	defer carol.Close()
+	require.Equal(t, http.StatusCreated, do(2, http.MethodPost, groupMessages, `{"content":"pitch?"}`).Code)
+	assert.Equal(t, realtime.EventMessageCreated, (<-carol.Events()).Type)
+	assert.Equal(t, realtime.EventUnreadUpdated, (<-carol.Events()).Type)
+	select {
+	case ev := <-alice.Events():
+		t.Fatalf("unexpected %s event for the blocker", ev.Type)
+	default:
+	}
+	assert.Equal(t, 0, unread(1))
+	assert.Equal(t, 1, unread(3))
+
+	// Nor listed for the blocker, whichever pagination is used
+	w = do(1, http.MethodGet, groupMessages+"?latest=true", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "pitch?")
+	assert.Contains(t, w.Body.String(), `"has_before":false`)
+	w = do(1, http.MethodGet, groupMessages+"?page=1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "pitch?")
+	assert.Contains(t, w.Body.String(), `"total":0`)
+	assert.Contains(t, do(3, http.MethodGet, groupMessages, "").Body.String(), "pitch?")
+	w = do(1, http.MethodGet, fmt.Sprintf("/conversations/%d", group.ID), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "pitch?")
+	assert.Contains(t, do(3, http.MethodGet, fmt.Sprintf("/conversations/%d", group.ID), "").Body.String(), "pitch?")
+
+	// Muted conversations still deliver messages, without unread updates or badge
+	w = do(3, http.MethodPost, fmt.Sprintf("/conversations/%d/mute", group.ID), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"muted":true`)
+	ev := <-carol.Events()
+	require.Equal(t, realtime.EventUnreadUpdated, ev.Type)
+	assert.Contains(t, string(ev.Data), `"total_unread":0`)
+	assert.Contains(t, string(ev.Data), `"unread_count":1`)
+
+	require.Equal(t, http.StatusCreated, do(1, http.MethodPost, groupMessages, `{"content":"deck attached"}`).Code)
+	assert.Equal(t, realtime.EventMessageCreated, (<-carol.Events()).Type)
+	select {
+	case ev := <-carol.Events():
+		t.Fatalf("unexpected %s event in a muted conversation", ev.Type)
+	default:
+	}
+	assert.Equal(t, 2, unread(3))
+	assert.Equal(t, 0, unread(1), "senders have not missed their own messages")
+
+	assert.Equal(t, http.StatusForbidden, do(3, http.MethodPost, "/conversations/999/mute", "").Code)
+	require.Equal(t, http.StatusOK, do(3, http.MethodDelete, fmt.Sprintf("/conversations/%d/mute", group.ID), "").Code)
+	assert.Contains(t, string((<-carol.Events()).Data), `"total_unread":2`)
+
+	// Neither side can add the other to a group either
+	owned := models.Conversation{IsGroup: true}
+	require.NoError(t, db.Create(&owned).Error)
+	require.NoError(t, db.Create(&models.ConversationParticipant{ConversationID: owned.ID, UserID: 1, Role: models.ParticipantRoleOwner}).Error)
+	w = do(1, http.MethodPost, fmt.Sprintf("/conversations/%d/participants", owned.ID), `{"user_ids":[2,3]}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "blocked")
+	assert.Equal(t, http.StatusOK, do(1, http.MethodPost, fmt.Sprintf("/conversations/%d/participants", owned.ID), `{"user_ids":[3]}`).Code)
+
+	// Unblocking restores one-to-one messaging
+	require.Equal(t, http.StatusOK, do(1, http.MethodDelete, "/users/me/blocks/2", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(1, http.MethodDelete, "/users/me/blocks/2", "").Code)
+	assert.Equal(t, http.StatusCreated, do(2, http.MethodPost, directMessages, `{"content":"hello!"}`).Code)
+}
+
+// Message search relies on Postgres full-text search, which sqlite lacks, so only the generated query is checked
+func TestConversationsHandler_SearchMessagesLeavesOutBlocked(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupConversationsDB(t)
+	var statements []string
+	record := func(tx *gorm.DB) { statements = append(statements, tx.Statement.SQL.String()) }
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:record", record))
+	cfg := &config.Config{Auth: config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret"}}}
+	h := v1.NewConversationsHandler(cfg, db, logrus.New(), nil, nil)
+	r := gin.New()
+	r.Use(middleware.AuthRequired(cfg))
+	r.GET("/conversations/search", h.SearchMessages)
+
+	req := httptest.NewRequest(http.MethodGet, "/conversations/search?q=pitch", nil)
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(1, "user1@test.com", "founder")})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.NotEmpty(t, statements)
+	assert.Contains(t, statements[0], "m.sender_id NOT IN (SELECT blocked_id FROM user_blocks WHERE blocker_id = cp.user_id)")
+}
@@ -0,0 +1,102 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversationsHandler_DirectAndContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupConversationsDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Startup{}, &models.Opportunity{}))
+	cfg := &config.Config{Auth: config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret"}}}
+	h := v1.NewConversationsHandler(cfg, db, logrus.New(), nil, nil)
+	r := gin.New()
+	r.Use(middleware.AuthRequired(cfg))
+	r.GET("/conversations", h.GetConversations)
+	r.POST("/conversations", h.CreateConversation)
+
+	for _, u := range []models.User{
+		{ID: 1, Email: "user1@test.com", Name: "User 1", Role: "investor"},
+		{ID: 2, Email: "user2@test.com", Name: "User 2", Role: "founder"},
+		{ID: 3, Email: "user3@test.com", Name: "User 3", Role: "founder"},
+	} {
+		require.NoError(t, db.Create(&u).Error)
+	}
+	startup := models.Startup{Name: "Acme", CreatedAt: time.Now()}
+	require.NoError(t, db.Create(&startup).Error)
+
+	do := func(userID uint64, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(userID, fmt.Sprintf("user%d@test.com", userID), "investor")})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	conversationID := func(w *httptest.ResponseRecorder) uint64 {
+		var body struct {
+			Data models.Conversation `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body.Data.ID
+	}
+
+	// One-to-one conversations are created once, whoever starts them
+	w := do(1, http.MethodPost, "/conversations", `{"participant_ids":[2]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	direct := conversationID(w)
+	w = do(2, http.MethodPost, "/conversations", `{"participant_ids":[1,2]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, direct, conversationID(w))
+	assert.NotContains(t, w.Body.String(), "direct_key")
+
+	// Groups are never deduplicated
+	group := `{"participant_ids":[2],"is_group":true}`
+	require.Equal(t, http.StatusCreated, do(1, http.MethodPost, "/conversations", group).Code)
+	require.Equal(t, http.StatusCreated, do(1, http.MethodPost, "/conversations", group).Code)
+
+	// A conversation about a startup is a thread of its own
+	assert.Equal(t, http.StatusBadRequest, do(1, http.MethodPost, "/conversations", `{"participant_ids":[2],"context_type":"startup"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do(1, http.MethodPost, "/conversations", `{"participant_ids":[2],"context_type":"event","context_id":1}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do(1, http.MethodPost, "/conversations", `{"participant_ids":[2],"context_type":"opportunity","context_id":1}`).Code)
+	about := fmt.Sprintf(`{"participant_ids":[%%d],"context_type":"startup","context_id":%d}`, startup.ID)
+	w = do(1, http.MethodPost, "/conversations", fmt.Sprintf(about, 2))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	deal := conversationID(w)
+	assert.NotEqual(t, direct, deal)
+	assert.Contains(t, w.Body.String(), `"context_type":"startup"`)
+	w = do(1, http.MethodPost, "/conversations", fmt.Sprintf(about, 2))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, deal, conversationID(w))
+	require.Equal(t, http.StatusCreated, do(1, http.MethodPost, "/conversations", fmt.Sprintf(about, 3)).Code)
+
+	// Conversations can be filtered by context
+	assert.Contains(t, do(1, http.MethodGet, "/conversations", "").Body.String(), `"total":5`)
+	assert.Contains(t, do(1, http.MethodGet, "/conversations?context_type=startup", "").Body.String(), `"total":2`)
+	assert.Contains(t, do(2, http.MethodGet, fmt.Sprintf("/conversations?context_type=startup&context_id=%d", startup.ID), "").Body.String(), `"total":1`)
+	assert.Contains(t, do(1, http.MethodGet, "/conversations?context_type=opportunity", "").Body.String(), `"total":0`)
+	assert.Equal(t, http.StatusBadRequest, do(1, http.MethodGet, "/conversations?context_type=event", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do(1, http.MethodGet, "/conversations?context_id=1", "").Code)
+}
+
+func TestDirectKey(t *testing.T) {
+	startup, id := models.ConversationContextStartup, uint64(12)
+	assert.Equal(t, "2:7", models.DirectKey(7, 2, nil, nil))
+	assert.Equal(t, models.DirectKey(2, 7, &startup, &id), models.DirectKey(7, 2, &startup, &id))
+	assert.Equal(t, "2:7:startup:12", models.DirectKey(2, 7, &startup, &id))
+}
@@ -0,0 +1,455 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// membershipChange is the outcome of a change to a group: the system messages it produced
+// and the users who are no longer participants but should still hear about it
+type membershipChange struct {
+	messages []models.Message
+	notify   []uint64
+}
+
+// UpdateConversation godoc
+// @Summary      Rename conversation
+// @Description  Renames a group conversation (owner only). A system message records the change.
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        id      path int true "Conversation ID"
+// @Param        payload body requests.ConversationUpdateRequest true "New title" Example({"title":"Seed round"})
+// @Success      200 {object} response.ConversationObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /conversations/{id} [patch]
+func (h *ConversationsHandler) UpdateConversation(c *gin.Context) {
+	var req struct {
+		Title string `json:"title" binding:"required,max=255"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "title cannot be empty", nil)
+		return
+	}
+	conversation, me, ok := h.groupMembership(c, true)
+	if !ok {
+		return
+	}
+	if conversation.Title != nil && *conversation.Title == title {
+		h.respondConversation(c, conversation.ID)
+		return
+	}
+
+	h.changeMembership(c, conversation, func(tx *gorm.DB, change *membershipChange) error {
+		if err := tx.Model(conversation).Update("title", title).Error; err != nil {
+			return fmt.Errorf("rename conversation: %w", err)
+		}
+		return h.systemMessage(tx, change, conversation.ID, me.UserID, models.SystemEventRenamed, nil,
+			fmt.Sprintf("%s renamed the conversation to \"%s\"", userName(tx, me.UserID), title))
+	})
+}
+
+// AddParticipants godoc
+// @Summary      Add participants
+// @Description  Adds users to a group conversation as members (owner only). Users who left can be added back. Newcomers start with nothing unread, and a system message records each addition.
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        id      path int true "Conversation ID"
+// @Param        payload body requests.ConversationParticipantsAddRequest true "Users to add" Example({"user_ids":[4,5]})
+// @Success      200 {object} response.ConversationObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /conversations/{id}/participants [post]
+func (h *ConversationsHandler) AddParticipants(c *gin.Context) {
+	var req struct {
+		UserIDs []uint64 `json:"user_ids" binding:"required,min=1,max=50"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+	slices.Sort(req.UserIDs)
+	req.UserIDs = slices.Compact(req.UserIDs)
+
+	conversation, me, ok := h.groupMembership(c, true)
+	if !ok {
+		return
+	}
+	var userCount int64
+	if err := h.db.Model(&models.User{}).Where("id IN ?", req.UserIDs).Count(&userCount).Error; err != nil {
+		h.log.WithError(err).Error("failed to validate participants")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to validate participants", nil)
+		return
+	}
+	if int64(len(req.UserIDs)) != userCount {
+		response.JSONError(c, http.StatusBadRequest, "invalid_participants", "some participants do not exist", nil)
+		return
+	}
+	blocked, err := h.blockedBetween(me.UserID, req.UserIDs)
+	if err != nil {
+		h.log.WithError(err).Error("failed to check blocks")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to add participants", nil)
+		return
+	}
+	if blocked {
+		response.JSONError(c, http.StatusForbidden, "blocked", "you cannot add a user you blocked or who blocked you", nil)
+		return
+	}
+
+	h.changeMembership(c, conversation, func(tx *gorm.DB, change *membershipChange) error {
+		actor := userName(tx, me.UserID)
+		for _, userID := range req.UserIDs {
+			var existing models.ConversationParticipant
+			err := tx.Where("conversation_id = ? AND user_id = ?", conversation.ID, userID).First(&existing).Error
+			switch {
+			case err == nil && existing.LeftAt == nil:
+				continue
+			case err == nil:
+				if err := tx.Model(&existing).Updates(map[string]interface{}{
+					"left_at":              nil,
+					"role":                 models.ParticipantRoleMember,
+					"joined_at":            time.Now().UTC(),
+					"last_read_message_id": conversation.LastMessageID,
+				}).Error; err != nil {
+					return fmt.Errorf("re-add participant %d: %w", userID, err)
+				}
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				participant := models.ConversationParticipant{
+					ConversationID:    conversation.ID,
+					UserID:            userID,
+					Role:              models.ParticipantRoleMember,
+					LastReadMessageID: conversation.LastMessageID,
+				}
+				if err := tx.Create(&participant).Error; err != nil {
+					return fmt.Errorf("add participant %d: %w", userID, err)
+				}
+			default:
+				return err
+			}
+			subject := userID
+			if err := h.systemMessage(tx, change, conversation.ID, me.UserID, models.SystemEventParticipantAdded, &subject,
+				fmt.Sprintf("%s added %s", actor, userName(tx, userID))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveParticipant godoc
+// @Summary      Remove participant
+// @Description  Removes a member from a group conversation (owner only); they lose access to it. Owners leave with POST /conversations/{id}/leave instead.
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Produce      json
+// @Param        id  path int true "Conversation ID"
+// @Param        uid path int true "User ID"
+// @Success      200 {object} response.ConversationObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /conversations/{id}/participants/{uid} [delete]
+func (h *ConversationsHandler) RemoveParticipant(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("uid"), 10, 64)
+	if err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_id", "invalid user id", nil)
+		return
+	}
+	conversation, me, ok := h.groupMembership(c, true)
+	if !ok {
+		return
+	}
+	if userID == me.UserID {
+		response.JSONError(c, http.StatusBadRequest, "cannot_remove_self", "use leave to quit the conversation", nil)
+		return
+	}
+	if _, found := h.activeParticipant(c, conversation.ID, userID); !found {
+		return
+	}
+
+	h.changeMembership(c, conversation, func(tx *gorm.DB, change *membershipChange) error {
+		if err := leave(tx, conversation.ID, userID); err != nil {
+			return err
+		}
+		change.notify = append(change.notify, userID)
+		return h.systemMessage(tx, change, conversation.ID, me.UserID, models.SystemEventParticipantRemoved, &userID,
+			fmt.Sprintf("%s removed %s", userName(tx, me.UserID), userName(tx, userID)))
+	})
+}
+
+// TransferOwnership godoc
+// @Summary      Transfer ownership
+// @Description  Makes another participant the owner of a group conversation (owner only); the former owner becomes a member.
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        id      path int true "Conversation ID"
+// @Param        payload body requests.ConversationOwnerRequest true "New owner" Example({"user_id":4})
+// @Success      200 {object} response.ConversationObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /conversations/{id}/owner [post]
+func (h *ConversationsHandler) TransferOwnership(c *gin.Context) {
+	var req struct {
+		UserID uint64 `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+	conversation, me, ok := h.groupMembership(c, true)
+	if !ok {
+		return
+	}
+	if req.UserID == me.UserID {
+		response.JSONError(c, http.StatusBadRequest, "already_owner", "you already own this conversation", nil)
+		return
+	}
+	if _, found := h.activeParticipant(c, conversation.ID, req.UserID); !found {
+		return
+	}
+
+	h.changeMembership(c, conversation, func(tx *gorm.DB, change *membershipChange) error {
+		if err := tx.Model(me).Update("role", models.ParticipantRoleMember).Error; err != nil {
+			return fmt.Errorf("demote owner: %w", err)
+		}
+		return h.promote(tx, change, conversation.ID, me.UserID, req.UserID,
+			fmt.Sprintf("%s made %s the owner", userName(tx, me.UserID), userName(tx, req.UserID)))
+	})
+}
+
+// LeaveConversation godoc
+// @Summary      Leave conversation
+// @Description  Leaves a group conversation, which the user can no longer access afterwards. When the owner leaves, the longest-standing member becomes the owner.
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Produce      json
+// @Param        id path int true "Conversation ID"
+// @Success      200 {object} response.MessageResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /conversations/{id}/leave [post]
+func (h *ConversationsHandler) LeaveConversation(c *gin.Context) {
+	conversation, me, ok := h.groupMembership(c, false)
+	if !ok {
+		return
+	}
+
+	change, err := h.applyMembership(conversation, func(tx *gorm.DB, change *membershipChange) error {
+		if err := leave(tx, conversation.ID, me.UserID); err != nil {
+			return err
+		}
+		change.notify = append(change.notify, me.UserID)
+		name := userName(tx, me.UserID)
+		if err := h.systemMessage(tx, change, conversation.ID, me.UserID, models.SystemEventParticipantLeft, &me.UserID, name+" left"); err != nil {
+			return err
+		}
+		if me.Role != models.ParticipantRoleOwner {
+			return nil
+		}
+		var successor models.ConversationParticipant
+		err := tx.Where("conversation_id = ? AND left_at IS NULL", conversation.ID).Order("joined_at ASC, id ASC").First(&successor).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("find new owner: %w", err)
+		}
+		return h.promote(tx, change, conversation.ID, me.UserID, successor.UserID,
+			fmt.Sprintf("%s is now the owner", userName(tx, successor.UserID)))
+	})
+	if err != nil {
+		h.log.WithError(err).WithField("conversation_id", conversation.ID).Error("failed to leave conversation")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to leave conversation", nil)
+		return
+	}
+	h.publishMembership(c, conversation.ID, change)
+	response.JSON(c, http.StatusOK, gin.H{"message": "left conversation"})
+}
+
+// groupMembership loads the group conversation :id and the membership of the authenticated user, who must own it when owner is set
+func (h *ConversationsHandler) groupMembership(c *gin.Context, owner bool) (*models.Conversation, *models.ConversationParticipant, bool) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return nil, nil, false
+	}
+	var conversation models.Conversation
+	if err := h.db.First(&conversation, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusNotFound, "not_found", "conversation not found", nil)
+			return nil, nil, false
+		}
+		h.log.WithError(err).Error("failed to fetch conversation")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve conversation", nil)
+		return nil, nil, false
+	}
+	var me models.ConversationParticipant
+	if err := h.db.Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", conversation.ID, claims.UserID).First(&me).Error; err != nil {
+		response.JSONError(c, http.StatusForbidden, "forbidden", "not a participant", nil)
+		return nil, nil, false
+	}
+	if !conversation.IsGroup {
+		response.JSONError(c, http.StatusBadRequest, "not_a_group", "only group conversations can be managed", nil)
+		return nil, nil, false
+	}
+	if owner && me.Role != models.ParticipantRoleOwner {
+		response.JSONError(c, http.StatusForbidden, "not_owner", "only the owner can manage the conversation", nil)
+		return nil, nil, false
+	}
+	return &conversation, &me, true
+}
+
+// activeParticipant loads the current membership of userID, answering 404 when there is none
+func (h *ConversationsHandler) activeParticipant(c *gin.Context, conversationID, userID uint64) (*models.ConversationParticipant, bool) {
+	var participant models.ConversationParticipant
+	err := h.db.Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", conversationID, userID).First(&participant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusNotFound, "not_found", "user is not a participant", nil)
+			return nil, false
+		}
+		h.log.WithError(err).Error("failed to fetch participant")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve participant", nil)
+		return nil, false
+	}
+	return &participant, true
+}
+
+// changeMembership applies fn in a transaction, then notifies the participants and answers with the updated conversation
+func (h *ConversationsHandler) changeMembership(c *gin.Context, conversation *models.Conversation, fn func(tx *gorm.DB, change *membershipChange) error) {
+	change, err := h.applyMembership(conversation, fn)
+	if err != nil {
+		h.log.WithError(err).WithField("conversation_id", conversation.ID).Error("failed to update conversation")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to update conversation", nil)
+		return
+	}
+	h.publishMembership(c, conversation.ID, change)
+	h.respondConversation(c, conversation.ID)
+}
+
+func (h *ConversationsHandler) applyMembership(conversation *models.Conversation, fn func(tx *gorm.DB, change *membershipChange) error) (*membershipChange, error) {
+	change := &membershipChange{}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx, change); err != nil {
+			return err
+		}
+		if len(change.messages) == 0 {
+			return nil
+		}
+		last := change.messages[len(change.messages)-1].ID
+		return tx.Model(conversation).Updates(map[string]interface{}{"last_message_id": last, "updated_at": time.Now().UTC()}).Error
+	})
+	return change, err
+}
+
+// publishMembership pushes the system messages and the new state of the conversation
+func (h *ConversationsHandler) publishMembership(c *gin.Context, conversationID uint64, change *membershipChange) {
+	ctx := c.Request.Context()
+	recipients := h.participantIDs(conversationID, 0)
+	for _, message := range change.messages {
+		h.publish(ctx, realtime.EventMessageCreated, conversationID, recipients, message)
+	}
+	var conversation models.Conversation
+	if err := h.db.Preload("Participants", "left_at IS NULL").Preload("Participants.User").First(&conversation, conversationID).Error; err != nil {
+		h.log.WithError(err).Error("failed to reload conversation")
+		return
+	}
+	h.publish(ctx, realtime.EventConversationUpdated, conversationID, append(recipients, change.notify...), conversation)
+	h.publishUnread(ctx, conversationID, append(recipients, change.notify...)...)
+}
+
+func (h *ConversationsHandler) respondConversation(c *gin.Context, conversationID uint64) {
+	var conversation models.Conversation
+	if err := h.db.
+		Preload("Participants", "left_at IS NULL").
+		Preload("Participants.User").
+		First(&conversation, conversationID).Error; err != nil {
+		h.log.WithError(err).Error("failed to reload conversation")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve conversation", nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"data": conversation})
+}
+
+// systemMessage records a change of the conversation in its history, on behalf of actorID
+func (h *ConversationsHandler) systemMessage(tx *gorm.DB, change *membershipChange, conversationID, actorID uint64, event string, subject *uint64, content string) error {
+	message := models.Message{
+		ConversationID: conversationID,
+		SenderID:       actorID,
+		Content:        content,
+		Kind:           models.MessageKindSystem,
+		Event:          &event,
+		SubjectUserID:  subject,
+	}
+	if err := tx.Create(&message).Error; err != nil {
+		return fmt.Errorf("record %s: %w", event, err)
+	}
+	change.messages = append(change.messages, message)
+	return nil
+}
+
+// promote makes userID the owner of the conversation, on behalf of actorID
+func (h *ConversationsHandler) promote(tx *gorm.DB, change *membershipChange, conversationID, actorID, userID uint64, content string) error {
+	if err := tx.Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Update("role", models.ParticipantRoleOwner).Error; err != nil {
+		return fmt.Errorf("promote owner: %w", err)
+	}
+	return h.systemMessage(tx, change, conversationID, actorID, models.SystemEventOwnerChanged, &userID, content)
+}
+
+// leave ends the membership of userID
+func leave(tx *gorm.DB, conversationID, userID uint64) error {
+	if err := tx.Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", conversationID, userID).
+		Updates(map[string]interface{}{"left_at": time.Now().UTC(), "role": models.ParticipantRoleMember}).Error; err != nil {
+		return fmt.Errorf("remove participant %d: %w", userID, err)
+	}
+	return nil
+}
+
+// userName is the display name of userID in system messages
+func userName(tx *gorm.DB, userID uint64) string {
+	var user models.User
+	if err := tx.Select("id", "name").First(&user, userID).Error; err != nil || user.Name == "" {
+		return "Someone"
+	}
+	return user.Name
+}
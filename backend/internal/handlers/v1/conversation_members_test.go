@@ -0,0 +1,149 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversationsHandler_Membership(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupConversationsDB(t)
+	cfg := &config.Config{Auth: config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret"}}}
+	broker := realtime.NewMemoryBroker(64)
+	h := v1.NewConversationsHandler(cfg, db, logrus.New(), broker, nil)
+	r := gin.New()
+	r.Use(middleware.AuthRequired(cfg))
+	r.GET("/conversations", h.GetConversations)
+	r.GET("/conversations/:id", h.GetConversation)
+	r.PATCH("/conversations/:id", h.UpdateConversation)
+	r.GET("/conversations/:id/messages", h.GetMessages)
+	r.POST("/conversations/:id/messages", h.SendMessage)
+	r.PATCH("/conversations/:id/messages/:mid", h.EditMessage)
+	r.POST("/conversations/:id/participants", h.AddParticipants)
+	r.DELETE("/conversations/:id/participants/:uid", h.RemoveParticipant)
+	r.POST("/conversations/:id/owner", h.TransferOwnership)
+	r.POST("/conversations/:id/leave", h.LeaveConversation)
+
+	for _, u := range []models.User{
+		{ID: 1, Email: "alice@test.com", Name: "Alice", Role: "founder"},
+		{ID: 2, Email: "bob@test.com", Name: "Bob", Role: "investor"},
+		{ID: 3, Email: "carol@test.com", Name: "Carol", Role: "investor"},
+		{ID: 4, Email: "dave@test.com", Name: "Dave", Role: "investor"},
+	} {
+		require.NoError(t, db.Create(&u).Error)
+	}
+	group := models.Conversation{IsGroup: true}
+	require.NoError(t, db.Create(&group).Error)
+	require.NoError(t, db.Create(&models.ConversationParticipant{ConversationID: group.ID, UserID: 1, Role: models.ParticipantRoleOwner}).Error)
+	require.NoError(t, db.Create(&models.ConversationParticipant{ConversationID: group.ID, UserID: 2, Role: models.ParticipantRoleMember}).Error)
+	direct := models.Conversation{}
+	require.NoError(t, db.Create(&direct).Error)
+	require.NoError(t, db.Create(&models.ConversationParticipant{ConversationID: direct.ID, UserID: 1, Role: models.ParticipantRoleOwner}).Error)
+	hello := models.Message{ConversationID: group.ID, SenderID: 1, Content: "Hello"}
+	require.NoError(t, db.Create(&hello).Error)
+	require.NoError(t, db.Model(&group).Update("last_message_id", hello.ID).Error)
+
+	do := func(userID uint64, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(userID, fmt.Sprintf("user%d@test.com", userID), "founder")})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	base := fmt.Sprintf("/conversations/%d", group.ID)
+	history := func() []models.Message {
+		var messages []models.Message
+		require.NoError(t, db.Where("conversation_id = ? AND kind = ?", group.ID, models.MessageKindSystem).Order("id ASC").Find(&messages).Error)
+		return messages
+	}
+	role := func(userID uint64) string {
+		var p models.ConversationParticipant
+		require.NoError(t, db.Where("conversation_id = ? AND user_id = ?", group.ID, userID).First(&p).Error)
+		return p.Role
+	}
+	bob := broker.Subscribe(2)
+	defer bob.Close()
+
+	// Only the owner manages the group, and only groups are managed
+	assert.Equal(t, http.StatusForbidden, do(2, http.MethodPatch, base, `{"title":"Mine"}`).Code)
+	assert.Equal(t, http.StatusForbidden, do(3, http.MethodPost, base+"/leave", "").Code)
+	w := do(1, http.MethodPost, fmt.Sprintf("/conversations/%d/participants", direct.ID), `{"user_ids":[3]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "not_a_group")
+
+	w = do(1, http.MethodPatch, base, `{"title":"Seed round"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"title":"Seed round"`)
+	ev := <-bob.Events()
+	assert.Equal(t, realtime.EventMessageCreated, ev.Type)
+	assert.Contains(t, string(ev.Data), `Alice renamed the conversation to \"Seed round\"`)
+	assert.Equal(t, realtime.EventConversationUpdated, (<-bob.Events()).Type)
+
+	// Newcomers are added as members without a backlog of unread messages
+	assert.Equal(t, http.StatusBadRequest, do(1, http.MethodPost, base+"/participants", `{"user_ids":[99]}`).Code)
+	w = do(1, http.MethodPost, base+"/participants", `{"user_ids":[3,4,2]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, models.ParticipantRoleMember, role(3))
+	w = do(3, http.MethodGet, "/conversations", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data []struct {
+			UnreadCount int `json:"unread_count"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, 2, list.Data[0].UnreadCount, "only the additions are unread")
+
+	// System messages cannot be edited
+	messages := history()
+	require.Len(t, messages, 3)
+	assert.Equal(t, "Alice added Carol", messages[1].Content)
+	require.NotNil(t, messages[1].SubjectUserID)
+	assert.Equal(t, uint64(3), *messages[1].SubjectUserID)
+	assert.Equal(t, http.StatusBadRequest, do(1, http.MethodPatch, fmt.Sprintf("%s/messages/%d", base, messages[1].ID), `{"content":"x"}`).Code)
+
+	// Removed participants lose access
+	assert.Equal(t, http.StatusBadRequest, do(1, http.MethodDelete, base+"/participants/1", "").Code)
+	require.Equal(t, http.StatusOK, do(1, http.MethodDelete, base+"/participants/4", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(1, http.MethodDelete, base+"/participants/4", "").Code)
+	assert.Equal(t, http.StatusForbidden, do(4, http.MethodGet, base+"/messages", "").Code)
+	assert.Equal(t, http.StatusForbidden, do(4, http.MethodPost, base+"/messages", `{"content":"still here?"}`).Code)
+	w = do(4, http.MethodGet, "/conversations", "")
+	assert.Contains(t, w.Body.String(), `"total":0`)
+	w = do(2, http.MethodGet, base, "")
+	assert.NotContains(t, w.Body.String(), `"user_id":4`)
+
+	// Ownership moves explicitly, or to the longest-standing member when the owner leaves
+	assert.Equal(t, http.StatusNotFound, do(1, http.MethodPost, base+"/owner", `{"user_id":4}`).Code)
+	require.Equal(t, http.StatusOK, do(1, http.MethodPost, base+"/owner", `{"user_id":3}`).Code)
+	assert.Equal(t, models.ParticipantRoleMember, role(1))
+	assert.Equal(t, models.ParticipantRoleOwner, role(3))
+
+	require.Equal(t, http.StatusOK, do(3, http.MethodPost, base+"/leave", "").Code)
+	assert.Equal(t, models.ParticipantRoleOwner, role(1), "Alice joined first")
+	messages = history()
+	assert.Equal(t, "Carol left", messages[len(messages)-2].Content)
+	assert.Equal(t, "Alice is now the owner", messages[len(messages)-1].Content)
+	require.NoError(t, db.First(&group, group.ID).Error)
+	assert.Equal(t, messages[len(messages)-1].ID, *group.LastMessageID)
+
+	// People who left can be added back
+	require.Equal(t, http.StatusOK, do(1, http.MethodPost, base+"/participants", `{"user_ids":[4]}`).Code)
+	assert.Equal(t, http.StatusOK, do(4, http.MethodGet, base+"/messages", "").Code)
+}
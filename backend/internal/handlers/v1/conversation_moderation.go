@@ -0,0 +1,610 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/http/pagination"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultEditWindow   = 15 * time.Minute
+	defaultDeleteWindow = time.Hour
+)
+
+var validReportReasons = []string{
+	models.ReportReasonSpam,
+	models.ReportReasonHarassment,
+	models.ReportReasonInappropriate,
+	models.ReportReasonOther,
+}
+
+// EditMessage godoc
+// @Summary      Edit message
+// @Description  Replaces the content of a message. Only its sender can edit it, within messaging.edit_window of sending it. Previous versions are kept for moderation.
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        id      path int true "Conversation ID"
+// @Param        mid     path int true "Message ID"
+// @Param        payload body requests.MessageEditRequest true "New content" Example({"content":"Hello, how are you doing?"})
+// @Success      200 {object} response.MessageObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /conversations/{id}/messages/{mid} [patch]
+func (h *ConversationsHandler) EditMessage(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	message, ok := h.ownMessage(c, claims.UserID, h.editWindow(), "edit")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Content string `json:"content" binding:"required,max=2000"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+	if req.Content == message.Content {
+		response.JSON(c, http.StatusOK, gin.H{"data": message})
+		return
+	}
+
+	now := time.Now().UTC()
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		previous := models.MessageEdit{MessageID: message.ID, Content: message.Content, EditedBy: &claims.UserID, EditedAt: now}
+		if err := tx.Create(&previous).Error; err != nil {
+			return fmt.Errorf("save previous version: %w", err)
+		}
+		return tx.Model(message).Updates(map[string]interface{}{"content": req.Content, "edited_at": now}).Error
+	})
+	if err != nil {
+		h.log.WithError(err).WithField("message_id", message.ID).Error("failed to edit message")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to edit message", nil)
+		return
+	}
+	message.Content = req.Content
+	message.EditedAt = &now
+
+	h.publish(c.Request.Context(), realtime.EventMessageUpdated, message.ConversationID, h.participantIDs(message.ConversationID, 0), message)
+	response.JSON(c, http.StatusOK, gin.H{"data": message})
+}
+
+// DeleteMessage godoc
+// @Summary      Delete message
+// @Description  Deletes a message for every participant. Only its sender can delete it, within messaging.delete_window of sending it.
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Produce      json
+// @Param        id  path int true "Conversation ID"
+// @Param        mid path int true "Message ID"
+// @Success      200 {object} response.MessageResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /conversations/{id}/messages/{mid} [delete]
+func (h *ConversationsHandler) DeleteMessage(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	message, ok := h.ownMessage(c, claims.UserID, h.deleteWindow(), "delete")
+	if !ok {
+		return
+	}
+	if err := h.removeMessage(message, claims.UserID, nil); err != nil {
+		h.log.WithError(err).WithField("message_id", message.ID).Error("failed to delete message")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to delete message", nil)
+		return
+	}
+	h.publishRemoval(c, message)
+	response.JSON(c, http.StatusOK, gin.H{"message": "message deleted"})
+}
+
+// ReportMessage godoc
+// @Summary      Report message
+// @Description  Reports a message of the conversation to the moderators. Each participant can report a message once.
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        id      path int true "Conversation ID"
+// @Param        mid     path int true "Message ID"
+// @Param        payload body requests.MessageReportRequest true "Report" Example({"reason":"spam","details":"Unsolicited advertising"})
+// @Success      201 {object} response.MessageReportObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /conversations/{id}/messages/{mid}/report [post]
+func (h *ConversationsHandler) ReportMessage(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	if !h.isUserParticipant(claims.UserID, c.Param("id")) {
+		response.JSONError(c, http.StatusForbidden, "forbidden", "not a participant", nil)
+		return
+	}
+	message, ok := h.conversationMessage(c)
+	if !ok {
+		return
+	}
+	if message.SenderID == claims.UserID {
+		response.JSONError(c, http.StatusBadRequest, "own_message", "you cannot report your own message", nil)
+		return
+	}
+
+	var req struct {
+		Reason  string  `json:"reason" binding:"required"`
+		Details *string `json:"details,omitempty" binding:"omitempty,max=1000"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+	if !slices.Contains(validReportReasons, req.Reason) {
+		response.JSONError(c, http.StatusBadRequest, "invalid_reason",
+			fmt.Sprintf("invalid reason '%s'. Allowed: %v", req.Reason, validReportReasons), nil)
+		return
+	}
+
+	var existing int64
+	h.db.Model(&models.MessageReport{}).Where("message_id = ? AND reporter_id = ?", message.ID, claims.UserID).Count(&existing)
+	if existing > 0 {
+		response.JSONError(c, http.StatusConflict, "already_reported", "you already reported this message", nil)
+		return
+	}
+	report := models.MessageReport{
+		MessageID:  message.ID,
+		ReporterID: claims.UserID,
+		Reason:     req.Reason,
+		Details:    req.Details,
+		Status:     models.ReportStatusOpen,
+	}
+	if err := h.db.Create(&report).Error; err != nil {
+		h.log.WithError(err).Error("failed to create message report")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to report message", nil)
+		return
+	}
+	response.JSON(c, http.StatusCreated, gin.H{"data": report})
+}
+
+// ListMessageReports godoc
+// @Summary      List message reports
+// @Description  Moderation queue of message reports, oldest first (messages.moderate required).
+// @Tags         Moderation
+// @Security     CookieAuth
+// @Produce      json
+// @Param        status    query string false "Report status" Enums(open,dismissed,actioned) default(open)
+// @Param        page      query int    false "Page" default(1)
+// @Param        per_page  query int    false "Page size" default(20)
+// @Success      200 {object} response.MessageReportListResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/message-reports [get]
+func (h *ConversationsHandler) ListMessageReports(c *gin.Context) {
+	params := pagination.Parse(c)
+	status := c.DefaultQuery("status", models.ReportStatusOpen)
+	if !slices.Contains([]string{models.ReportStatusOpen, models.ReportStatusDismissed, models.ReportStatusActioned}, status) {
+		response.JSONError(c, http.StatusBadRequest, "invalid_params", "invalid status", nil)
+		return
+	}
+
+	query := h.db.Model(&models.MessageReport{}).Where("status = ?", status)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		h.log.WithError(err).Error("failed to count message reports")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve reports count", nil)
+		return
+	}
+	var reports []models.MessageReport
+	if err := query.
+		Preload("Message.Sender").
+		Preload("Reporter").
+		Order("created_at ASC, id ASC").
+		Offset((params.Page - 1) * params.PerPage).
+		Limit(params.PerPage).
+		Find(&reports).Error; err != nil {
+		h.log.WithError(err).Error("failed to fetch message reports")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve reports", nil)
+		return
+	}
+
+	totalPages := (int(total) + params.PerPage - 1) / params.PerPage
+	response.JSON(c, http.StatusOK, gin.H{
+		"data": reports,
+		"pagination": gin.H{
+			"page":     params.Page,
+			"per_page": params.PerPage,
+			"total":    total,
+			"has_next": params.Page < totalPages,
+			"has_prev": params.Page > 1,
+		},
+	})
+}
+
+// ResolveMessageReport godoc
+// @Summary      Resolve message report
+// @Description  Closes an open report: "dismiss" keeps the message, "hide" hides it and closes every open report on it (messages.moderate required).
+// @Tags         Moderation
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        id      path int true "Report ID"
+// @Param        payload body requests.MessageReportResolveRequest true "Decision" Example({"action":"hide","note":"Harassment"})
+// @Success      200 {object} response.MessageReportObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/message-reports/{id}/resolve [post]
+func (h *ConversationsHandler) ResolveMessageReport(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	var req struct {
+		Action string  `json:"action" binding:"required,oneof=dismiss hide"`
+		Note   *string `json:"note,omitempty" binding:"omitempty,max=500"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+
+	var report models.MessageReport
+	if err := h.db.First(&report, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusNotFound, "not_found", "report not found", nil)
+			return
+		}
+		h.log.WithError(err).Error("failed to fetch message report")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve report", nil)
+		return
+	}
+	if report.Status != models.ReportStatusOpen {
+		response.JSONError(c, http.StatusConflict, "already_resolved", "report is already "+report.Status, nil)
+		return
+	}
+
+	switch req.Action {
+	case "dismiss":
+		now := time.Now().UTC()
+		if err := h.db.Model(&report).Updates(map[string]interface{}{
+			"status":          models.ReportStatusDismissed,
+			"reviewed_by":     claims.UserID,
+			"reviewed_at":     now,
+			"resolution_note": req.Note,
+		}).Error; err != nil {
+			h.log.WithError(err).Error("failed to dismiss message report")
+			response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to resolve report", nil)
+			return
+		}
+	case "hide":
+		var message models.Message
+		if err := h.db.First(&message, report.MessageID).Error; err != nil {
+			h.log.WithError(err).Error("failed to fetch reported message")
+			response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to resolve report", nil)
+			return
+		}
+		reason := "Reported as " + report.Reason
+		if req.Note != nil && strings.TrimSpace(*req.Note) != "" {
+			reason = strings.TrimSpace(*req.Note)
+		}
+		if message.DeletedAt == nil {
+			if err := h.removeMessage(&message, claims.UserID, &reason); err != nil {
+				h.log.WithError(err).Error("failed to hide reported message")
+				response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to resolve report", nil)
+				return
+			}
+			h.publishRemoval(c, &message)
+		} else if err := h.closeReports(h.db, message.ID, claims.UserID, &reason); err != nil {
+			h.log.WithError(err).Error("failed to close message reports")
+			response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to resolve report", nil)
+			return
+		}
+	}
+
+	if err := h.db.First(&report, report.ID).Error; err != nil {
+		h.log.WithError(err).Error("failed to reload message report")
+	}
+	response.JSON(c, http.StatusOK, gin.H{"data": report})
+}
+
+// GetMessageModeration godoc
+// @Summary      Inspect message
+// @Description  Returns a message, including deleted or hidden ones, with its previous versions and reports (messages.moderate required).
+// @Tags         Moderation
+// @Security     CookieAuth
+// @Produce      json
+// @Param        id path int true "Message ID"
+// @Success      200 {object} response.MessageModerationResponse
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/messages/{id} [get]
+func (h *ConversationsHandler) GetMessageModeration(c *gin.Context) {
+	var message models.Message
+	if err := h.db.Preload("Sender").First(&message, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusNotFound, "not_found", "message not found", nil)
+			return
+		}
+		h.log.WithError(err).Error("failed to fetch message")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve message", nil)
+		return
+	}
+	edits := make([]models.MessageEdit, 0)
+	reports := make([]models.MessageReport, 0)
+	if err := h.db.Where("message_id = ?", message.ID).Order("edited_at ASC, id ASC").Find(&edits).Error; err != nil {
+		h.log.WithError(err).Error("failed to fetch message edits")
+	}
+	if err := h.db.Preload("Reporter").Where("message_id = ?", message.ID).Order("created_at ASC").Find(&reports).Error; err != nil {
+		h.log.WithError(err).Error("failed to fetch message reports")
+	}
+	response.JSON(c, http.StatusOK, gin.H{"data": gin.H{"message": message, "edits": edits, "reports": reports}})
+}
+
+// HideMessage godoc
+// @Summary      Hide message
+// @Description  Hides a message from the conversation and closes its open reports (messages.moderate required).
+// @Tags         Moderation
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        id      path int true "Message ID"
+// @Param        payload body requests.MessageHideRequest true "Reason" Example({"reason":"Harassment"})
+// @Success      200 {object} response.MessageObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/messages/{id}/hide [post]
+func (h *ConversationsHandler) HideMessage(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	var req struct {
+		Reason string `json:"reason" binding:"required,max=500"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+	message, ok := h.messageByID(c)
+	if !ok {
+		return
+	}
+	if message.DeletedAt != nil {
+		response.JSONError(c, http.StatusConflict, "already_deleted", "message is already deleted or hidden", nil)
+		return
+	}
+	if err := h.removeMessage(message, claims.UserID, &req.Reason); err != nil {
+		h.log.WithError(err).WithField("message_id", message.ID).Error("failed to hide message")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to hide message", nil)
+		return
+	}
+	h.publishRemoval(c, message)
+	response.JSON(c, http.StatusOK, gin.H{"data": message})
+}
+
+// RestoreMessage godoc
+// @Summary      Restore message
+// @Description  Makes a message hidden by a moderator visible again. Messages deleted by their sender cannot be restored (messages.moderate required).
+// @Tags         Moderation
+// @Security     CookieAuth
+// @Produce      json
+// @Param        id path int true "Message ID"
+// @Success      200 {object} response.MessageObjectResponse
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/messages/{id}/restore [post]
+func (h *ConversationsHandler) RestoreMessage(c *gin.Context) {
+	message, ok := h.messageByID(c)
+	if !ok {
+		return
+	}
+	if !message.Moderated() {
+		response.JSONError(c, http.StatusConflict, "not_hidden", "only messages hidden by a moderator can be restored", nil)
+		return
+	}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(message).Updates(map[string]interface{}{
+			"deleted_at": nil, "deleted_by": nil, "moderation_reason": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return refreshLastMessage(tx, message.ConversationID)
+	})
+	if err != nil {
+		h.log.WithError(err).WithField("message_id", message.ID).Error("failed to restore message")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to restore message", nil)
+		return
+	}
+	message.DeletedAt, message.DeletedBy, message.ModerationReason = nil, nil, nil
+
+	h.publish(c.Request.Context(), realtime.EventMessageUpdated, message.ConversationID, h.participantIDs(message.ConversationID, 0), message)
+	h.publishUnread(c.Request.Context(), message.ConversationID, h.participantIDs(message.ConversationID, message.SenderID)...)
+	response.JSON(c, http.StatusOK, gin.H{"data": message})
+}
+
+// conversationMessage loads the visible user message :mid of conversation :id
+func (h *ConversationsHandler) conversationMessage(c *gin.Context) (*models.Message, bool) {
+	var message models.Message
+	err := h.db.Where("id = ? AND conversation_id = ? AND deleted_at IS NULL", c.Param("mid"), c.Param("id")).First(&message).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusNotFound, "not_found", "message not found", nil)
+			return nil, false
+		}
+		h.log.WithError(err).Error("failed to fetch message")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve message", nil)
+		return nil, false
+	}
+	if message.Kind == models.MessageKindSystem {
+		response.JSONError(c, http.StatusBadRequest, "system_message", "system messages cannot be changed or reported", nil)
+		return nil, false
+	}
+	return &message, true
+}
+
+// ownMessage loads the message :mid that userID sent less than window ago, for the named action. Senders who left
+// the conversation can no longer change what they sent
+func (h *ConversationsHandler) ownMessage(c *gin.Context, userID uint64, window time.Duration, action string) (*models.Message, bool) {
+	if !h.isUserParticipant(userID, c.Param("id")) {
+		response.JSONError(c, http.StatusForbidden, "forbidden", "not a participant", nil)
+		return nil, false
+	}
+	message, ok := h.conversationMessage(c)
+	if !ok {
+		return nil, false
+	}
+	if message.SenderID != userID {
+		response.JSONError(c, http.StatusForbidden, "forbidden", "only the sender can "+action+" this message", nil)
+		return nil, false
+	}
+	if time.Since(message.CreatedAt) > window {
+		response.JSONError(c, http.StatusForbidden, action+"_window_expired",
+			fmt.Sprintf("the %s window of %s after sending has expired", action, window), nil)
+		return nil, false
+	}
+	return message, true
+}
+
+// messageByID loads the message :id, whatever its state
+func (h *ConversationsHandler) messageByID(c *gin.Context) (*models.Message, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_id", "invalid message id", nil)
+		return nil, false
+	}
+	var message models.Message
+	if err := h.db.First(&message, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusNotFound, "not_found", "message not found", nil)
+			return nil, false
+		}
+		h.log.WithError(err).Error("failed to fetch message")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve message", nil)
+		return nil, false
+	}
+	return &message, true
+}
+
+// removeMessage soft-deletes message on behalf of by. A reason marks a moderation, which also closes the open reports
+func (h *ConversationsHandler) removeMessage(message *models.Message, by uint64, reason *string) error {
+	now := time.Now().UTC()
+	return h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(message).Updates(map[string]interface{}{
+			"deleted_at": now, "deleted_by": by, "moderation_reason": reason,
+		}).Error; err != nil {
+			return fmt.Errorf("delete message: %w", err)
+		}
+		message.DeletedAt, message.DeletedBy, message.ModerationReason = &now, &by, reason
+		if reason != nil {
+			if err := h.closeReports(tx, message.ID, by, reason); err != nil {
+				return err
+			}
+		}
+		return refreshLastMessage(tx, message.ConversationID)
+	})
+}
+
+// closeReports marks the open reports of a hidden message as actioned
+func (h *ConversationsHandler) closeReports(tx *gorm.DB, messageID, by uint64, note *string) error {
+	if err := tx.Model(&models.MessageReport{}).
+		Where("message_id = ? AND status = ?", messageID, models.ReportStatusOpen).
+		Updates(map[string]interface{}{
+			"status":          models.ReportStatusActioned,
+			"reviewed_by":     by,
+			"reviewed_at":     time.Now().UTC(),
+			"resolution_note": note,
+		}).Error; err != nil {
+		return fmt.Errorf("close reports: %w", err)
+	}
+	return nil
+}
+
+// publishRemoval tells the participants that a message disappeared and refreshes their unread counts
+func (h *ConversationsHandler) publishRemoval(c *gin.Context, message *models.Message) {
+	ctx := c.Request.Context()
+	h.publish(ctx, realtime.EventMessageDeleted, message.ConversationID, h.participantIDs(message.ConversationID, 0), gin.H{
+		"conversation_id": message.ConversationID,
+		"message_id":      message.ID,
+		"moderated":       message.Moderated(),
+	})
+	h.publishUnread(ctx, message.ConversationID, h.participantIDs(message.ConversationID, message.SenderID)...)
+}
+
+// refreshLastMessage points the conversation at its latest visible message
+func refreshLastMessage(tx *gorm.DB, conversationID uint64) error {
+	var ids []uint64
+	if err := tx.Model(&models.Message{}).
+		Where("conversation_id = ? AND deleted_at IS NULL", conversationID).
+		Order("id DESC").Limit(1).
+		Pluck("id", &ids).Error; err != nil {
+		return fmt.Errorf("find last message: %w", err)
+	}
+	var last *uint64
+	if len(ids) > 0 {
+		last = &ids[0]
+	}
+	if err := tx.Model(&models.Conversation{}).Where("id = ?", conversationID).Update("last_message_id", last).Error; err != nil {
+		return fmt.Errorf("update last message: %w", err)
+	}
+	return nil
+}
+
+func (h *ConversationsHandler) editWindow() time.Duration {
+	if h.cfg.Messaging.EditWindow > 0 {
+		return h.cfg.Messaging.EditWindow
+	}
+	return defaultEditWindow
+}
+
+func (h *ConversationsHandler) deleteWindow() time.Duration {
+	if h.cfg.Messaging.DeleteWindow > 0 {
+		return h.cfg.Messaging.DeleteWindow
+	}
+	return defaultDeleteWindow
+}
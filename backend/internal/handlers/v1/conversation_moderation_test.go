@@ -0,0 +1,157 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversationsHandler_Moderation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupConversationsDB(t)
+	require.NoError(t, db.AutoMigrate(&models.MessageEdit{}, &models.MessageReport{}))
+	seedRoles(t, db)
+
+	cfg := &config.Config{
+		Auth:      config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret"}},
+		Messaging: config.MessagingConfig{EditWindow: 15 * time.Minute, DeleteWindow: time.Hour},
+	}
+	broker := realtime.NewMemoryBroker(16)
+	h := v1.NewConversationsHandler(cfg, db, logrus.New(), broker, nil)
+	r := gin.New()
+	r.Use(middleware.AuthRequired(cfg))
+	r.GET("/conversations/:id", h.GetConversation)
+	r.GET("/conversations/:id/messages", h.GetMessages)
+	r.PATCH("/conversations/:id/messages/:mid", h.EditMessage)
+	r.DELETE("/conversations/:id/messages/:mid", h.DeleteMessage)
+	r.POST("/conversations/:id/messages/:mid/report", h.ReportMessage)
+	admin := r.Group("/admin", middleware.RequirePermission(db, auth.PermMessagesModerate))
+	admin.GET("/message-reports", h.ListMessageReports)
+	admin.POST("/message-reports/:id/resolve", h.ResolveMessageReport)
+	admin.GET("/messages/:id", h.GetMessageModeration)
+	admin.POST("/messages/:id/hide", h.HideMessage)
+	admin.POST("/messages/:id/restore", h.RestoreMessage)
+
+	for _, u := range []models.User{
+		{ID: 1, Email: "user1@test.com", Name: "User 1", Role: "founder"},
+		{ID: 2, Email: "user2@test.com", Name: "User 2", Role: "investor"},
+		{ID: 3, Email: "admin@test.com", Name: "Admin", Role: "admin"},
+	} {
+		require.NoError(t, db.Create(&u).Error)
+	}
+	conv := models.Conversation{}
+	require.NoError(t, db.Create(&conv).Error)
+	for _, id := range []uint64{1, 2} {
+		require.NoError(t, db.Create(&models.ConversationParticipant{ConversationID: conv.ID, UserID: id, Role: "member"}).Error)
+	}
+	old := models.Message{ConversationID: conv.ID, SenderID: 1, Content: "Sent yesterday", CreatedAt: time.Now().Add(-24 * time.Hour)}
+	require.NoError(t, db.Create(&old).Error)
+	msg := models.Message{ConversationID: conv.ID, SenderID: 1, Content: "Helo Bob"}
+	require.NoError(t, db.Create(&msg).Error)
+	require.NoError(t, db.Model(&conv).Update("last_message_id", msg.ID).Error)
+
+	do := func(userID uint64, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(userID, fmt.Sprintf("user%d@test.com", userID), "founder")})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	msgPath := fmt.Sprintf("/conversations/%d/messages/%d", conv.ID, msg.ID)
+	bob := broker.Subscribe(2)
+	defer bob.Close()
+
+	// Only the sender edits, within the window, and the previous version is kept
+	assert.Equal(t, http.StatusForbidden, do(2, http.MethodPatch, msgPath, `{"content":"Hacked"}`).Code)
+	w := do(1, http.MethodPatch, fmt.Sprintf("/conversations/%d/messages/%d", conv.ID, old.ID), `{"content":"Too late"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "edit_window_expired")
+	w = do(1, http.MethodPatch, msgPath, `{"content":"Hello Bob"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"edited_at"`)
+	ev := <-bob.Events()
+	assert.Equal(t, realtime.EventMessageUpdated, ev.Type)
+	var edits []models.MessageEdit
+	require.NoError(t, db.Where("message_id = ?", msg.ID).Find(&edits).Error)
+	require.Len(t, edits, 1)
+	assert.Equal(t, "Helo Bob", edits[0].Content)
+
+	// Participants report messages of others, once
+	reportPath := msgPath + "/report"
+	assert.Equal(t, http.StatusBadRequest, do(1, http.MethodPost, reportPath, `{"reason":"spam"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do(2, http.MethodPost, reportPath, `{"reason":"boring"}`).Code)
+	assert.Equal(t, http.StatusForbidden, do(3, http.MethodPost, reportPath, `{"reason":"spam"}`).Code)
+	w = do(2, http.MethodPost, reportPath, `{"reason":"harassment","details":"Keeps insisting"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusConflict, do(2, http.MethodPost, reportPath, `{"reason":"spam"}`).Code)
+
+	// The review queue is reserved to moderators
+	assert.Equal(t, http.StatusForbidden, do(2, http.MethodGet, "/admin/message-reports", "").Code)
+	w = do(3, http.MethodGet, "/admin/message-reports", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var queue struct {
+		Data []models.MessageReport `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &queue))
+	require.Len(t, queue.Data, 1)
+	require.NotNil(t, queue.Data[0].Message)
+	assert.Equal(t, "Hello Bob", queue.Data[0].Message.Content)
+
+	// Hiding through the report closes it and removes the message for participants
+	resolvePath := fmt.Sprintf("/admin/message-reports/%d/resolve", queue.Data[0].ID)
+	w = do(3, http.MethodPost, resolvePath, `{"action":"hide"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"status":"actioned"`)
+	assert.Equal(t, http.StatusConflict, do(3, http.MethodPost, resolvePath, `{"action":"dismiss"}`).Code)
+	ev = <-bob.Events()
+	assert.Equal(t, realtime.EventMessageDeleted, ev.Type)
+	assert.Contains(t, string(ev.Data), `"moderated":true`)
+
+	w = do(2, http.MethodGet, fmt.Sprintf("/conversations/%d/messages", conv.ID), "")
+	assert.NotContains(t, w.Body.String(), "Hello Bob")
+	w = do(2, http.MethodGet, fmt.Sprintf("/conversations/%d", conv.ID), "")
+	assert.NotContains(t, w.Body.String(), "Hello Bob")
+	require.NoError(t, db.First(&conv, conv.ID).Error)
+	require.NotNil(t, conv.LastMessageID)
+	assert.Equal(t, old.ID, *conv.LastMessageID)
+
+	// Moderators still see the message with its history, and can restore it
+	w = do(3, http.MethodGet, fmt.Sprintf("/admin/messages/%d", msg.ID), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Helo Bob")
+	assert.Contains(t, w.Body.String(), "Reported as harassment")
+	require.Equal(t, http.StatusOK, do(3, http.MethodPost, fmt.Sprintf("/admin/messages/%d/restore", msg.ID), "").Code)
+	require.NoError(t, db.First(&conv, conv.ID).Error)
+	assert.Equal(t, msg.ID, *conv.LastMessageID)
+
+	// Senders who left the conversation can neither edit nor delete
+	require.NoError(t, db.Model(&models.ConversationParticipant{}).Where("conversation_id = ? AND user_id = ?", conv.ID, 1).Update("left_at", time.Now().UTC()).Error)
+	assert.Equal(t, http.StatusForbidden, do(1, http.MethodPatch, msgPath, `{"content":"Bye"}`).Code)
+	assert.Equal(t, http.StatusForbidden, do(1, http.MethodDelete, msgPath, "").Code)
+	require.NoError(t, db.Model(&models.ConversationParticipant{}).Where("conversation_id = ? AND user_id = ?", conv.ID, 1).Update("left_at", nil).Error)
+
+	// Messages deleted by their sender are not the moderators' to restore
+	require.Equal(t, http.StatusOK, do(1, http.MethodDelete, msgPath, "").Code)
+	assert.Equal(t, http.StatusNotFound, do(1, http.MethodDelete, msgPath, "").Code)
+	assert.Equal(t, http.StatusConflict, do(3, http.MethodPost, fmt.Sprintf("/admin/messages/%d/restore", msg.ID), "").Code)
+	assert.Equal(t, http.StatusConflict, do(3, http.MethodPost, fmt.Sprintf("/admin/messages/%d/hide", msg.ID), `{"reason":"Spam"}`).Code)
+	w = do(1, http.MethodDelete, fmt.Sprintf("/conversations/%d/messages/%d", conv.ID, old.ID), "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "delete_window_expired")
+}
@@ -0,0 +1,187 @@
+package v1_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type messagePage struct {
+	Data    []models.Message `json:"data"`
+	Cursors struct {
+		Before    *uint64 `json:"before"`
+		After     *uint64 `json:"after"`
+		HasBefore bool    `json:"has_before"`
+		HasAfter  bool    `json:"has_after"`
+	} `json:"cursors"`
+}
+
+func TestConversationsHandler_MessageCursors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupConversationsDB(t)
+	cfg := &config.Config{Auth: config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret"}}}
+	h := v1.NewConversationsHandler(cfg, db, logrus.New(), nil, nil)
+	r := gin.New()
+	r.Use(middleware.AuthRequired(cfg))
+	r.GET("/conversations/:id/messages", h.GetMessages)
+
+	require.NoError(t, db.Create(&models.User{ID: 1, Email: "user1@test.com", Name: "User 1", Role: "founder"}).Error)
+	conv := models.Conversation{}
+	require.NoError(t, db.Create(&conv).Error)
+	require.NoError(t, db.Create(&models.ConversationParticipant{ConversationID: conv.ID, UserID: 1}).Error)
+	ids := make([]uint64, 0, 7)
+	for i := 1; i <= 7; i++ {
+		m := models.Message{ConversationID: conv.ID, SenderID: 1, Content: fmt.Sprintf("m%d", i), CreatedAt: time.Now().Add(time.Duration(i) * time.Second)}
+		require.NoError(t, db.Create(&m).Error)
+		ids = append(ids, m.ID)
+	}
+	// Deleted messages are skipped without breaking the cursors
+	require.NoError(t, db.Model(&models.Message{}).Where("id = ?", ids[3]).Update("deleted_at", time.Now()).Error)
+
+	get := func(query string) messagePage {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/conversations/%d/messages?%s", conv.ID, query), nil)
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(1, "user1@test.com", "founder")})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var page messagePage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		return page
+	}
+	contents := func(page messagePage) []string {
+		out := make([]string, len(page.Data))
+		for i, m := range page.Data {
+			out[i] = m.Content
+		}
+		return out
+	}
+
+	// The newest messages come first, in chronological order, then scroll back
+	page := get("latest=true&per_page=2")
+	assert.Equal(t, []string{"m6", "m7"}, contents(page))
+	assert.True(t, page.Cursors.HasBefore)
+	assert.False(t, page.Cursors.HasAfter)
+
+	page = get(fmt.Sprintf("per_page=2&before=%d", *page.Cursors.Before))
+	assert.Equal(t, []string{"m3", "m5"}, contents(page))
+	assert.True(t, page.Cursors.HasBefore)
+	assert.True(t, page.Cursors.HasAfter)
+
+	page = get(fmt.Sprintf("per_page=2&before=%d", *page.Cursors.Before))
+	assert.Equal(t, []string{"m1", "m2"}, contents(page))
+	assert.False(t, page.Cursors.HasBefore)
+
+	// Catching up from a known message
+	page = get(fmt.Sprintf("per_page=3&after=%d", ids[1]))
+	assert.Equal(t, []string{"m3", "m5", "m6"}, contents(page))
+	assert.True(t, page.Cursors.HasAfter)
+	assert.True(t, page.Cursors.HasBefore)
+	page = get(fmt.Sprintf("per_page=3&after=%d", ids[6]))
+	assert.Empty(t, page.Data)
+	assert.Nil(t, page.Cursors.After)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/conversations/%d/messages?before=1&after=2", conv.ID), nil)
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(1, "user1@test.com", "founder")})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// The deprecated offset pagination still works, and stays the default
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/conversations/%d/messages?page=2&per_page=4", conv.ID), nil)
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(1, "user1@test.com", "founder")})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total":6`)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/conversations/%d/messages?per_page=2", conv.ID), nil)
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(1, "user1@test.com", "founder")})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var legacy struct {
+		Data       []models.Message       `json:"data"`
+		Pagination map[string]interface{} `json:"pagination"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &legacy))
+	require.Len(t, legacy.Data, 2)
+	assert.Equal(t, "m1", legacy.Data[0].Content)
+	assert.Equal(t, true, legacy.Pagination["has_next"])
+	assert.NotContains(t, w.Body.String(), `"cursors"`)
+}
+
+// statementCounter is a gorm logger counting the executed statements
+type statementCounter struct {
+	logger.Interface
+	n *int
+}
+
+func (s *statementCounter) LogMode(logger.LogLevel) logger.Interface { return s }
+
+func (s *statementCounter) Trace(context.Context, time.Time, func() (string, int64), error) { *s.n++ }
+
+func TestConversationsHandler_UnreadCountsBatched(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupConversationsDB(t)
+	cfg := &config.Config{Auth: config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret"}}}
+	r := gin.New()
+	r.Use(middleware.AuthRequired(cfg))
+
+	require.NoError(t, db.Create(&models.User{ID: 1, Email: "user1@test.com", Name: "User 1", Role: "founder"}).Error)
+	require.NoError(t, db.Create(&models.User{ID: 2, Email: "user2@test.com", Name: "User 2", Role: "investor"}).Error)
+	for n := 0; n < 5; n++ {
+		conv := models.Conversation{}
+		require.NoError(t, db.Create(&conv).Error)
+		var lastRead *uint64
+		for i := 0; i < 3; i++ {
+			m := models.Message{ConversationID: conv.ID, SenderID: 2, Content: "hi"}
+			require.NoError(t, db.Create(&m).Error)
+			if i < n%3 {
+				lastRead = &m.ID
+			}
+		}
+		// The user's own messages are never unread for them
+		require.NoError(t, db.Create(&models.Message{ConversationID: conv.ID, SenderID: 1, Content: "hello"}).Error)
+		require.NoError(t, db.Create(&models.ConversationParticipant{ConversationID: conv.ID, UserID: 1, LastReadMessageID: lastRead}).Error)
+	}
+
+	// Count the statements of one request
+	var statements int
+	counting := db.Session(&gorm.Session{Logger: &statementCounter{Interface: logger.Discard, n: &statements}})
+	h := v1.NewConversationsHandler(cfg, counting, logrus.New(), nil, nil)
+	r.GET("/conversations", h.GetConversations)
+
+	req := httptest.NewRequest(http.MethodGet, "/conversations?sort=id&order=asc", nil)
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(1, "user1@test.com", "founder")})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Data []struct {
+			UnreadCount int `json:"unread_count"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	got := make([]int, len(body.Data))
+	for i, c := range body.Data {
+		got[i] = c.UnreadCount
+	}
+	assert.Equal(t, []int{3, 2, 1, 3, 2}, got)
+	assert.LessOrEqual(t, statements, 7, "the number of queries does not grow with the page size")
+}
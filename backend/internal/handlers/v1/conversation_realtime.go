@@ -0,0 +1,231 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultPingInterval = 30 * time.Second
+	wsWriteTimeout      = 10 * time.Second
+	wsReadLimit         = 4096
+	// Minimum delay between two typing events of a user in a conversation
+	typingThrottle = 2 * time.Second
+)
+
+// clientFrame is a message sent by the client over the WebSocket
+type clientFrame struct {
+	Type           string `json:"type"`
+	ConversationID uint64 `json:"conversation_id"`
+	MessageID      uint64 `json:"message_id,omitempty"`
+}
+
+// Stream godoc
+// @Summary      Real-time conversation events
+// @Description  Upgrades to a WebSocket authenticated by the access-token cookie and pushes the events of the user's conversations as JSON frames: message.created and message.updated (data: the message), message.deleted (data: conversation_id, message_id, moderated), message.read (data: conversation_id, user_id, message_id) and typing (data: conversation_id, user_id), along with the events of /notifications/stream.
+// @Description  Clients send {"type":"typing","conversation_id":1} and {"type":"read","conversation_id":1,"message_id":42}. An event with truncated=true carries no data and the resource must be refetched.
+// @Description  The connection is closed when the access token expires or when the client falls behind; reconnect (after refreshing the session) and resynchronize with the REST endpoints.
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Success      101 "Switching Protocols"
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 "Origin not allowed"
+// @Failure      503 {object} response.ErrorBody
+// @Router       /conversations/ws [get]
+func (h *ConversationsHandler) Stream(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	if h.broker == nil {
+		response.JSONError(c, http.StatusServiceUnavailable, "realtime_unavailable", "real-time events are not available", nil)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.log.WithError(err).Debug("websocket upgrade failed")
+		return
+	}
+	defer conn.Close()
+
+	sub := h.broker.Subscribe(claims.UserID)
+	defer sub.Close()
+
+	ping := h.cfg.Realtime.PingInterval
+	if ping <= 0 {
+		ping = defaultPingInterval
+	}
+	conn.SetReadLimit(wsReadLimit)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * ping))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * ping))
+	})
+
+	ctx := c.Request.Context()
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		h.readFrames(ctx, conn, claims)
+	}()
+
+	expiry := time.Hour
+	if claims.ExpiresAt != nil {
+		expiry = time.Until(claims.ExpiresAt.Time)
+	}
+	expired := time.NewTimer(expiry)
+	defer expired.Stop()
+	ticker := time.NewTicker(ping)
+	defer ticker.Stop()
+
+	closeWith := func(code int, reason string) {
+		msg := websocket.FormatCloseMessage(code, reason)
+		_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsWriteTimeout))
+	}
+	for {
+		select {
+		case ev, ok := <-sub.Events():
+			if !ok {
+				closeWith(websocket.CloseTryAgainLater, "too many pending events")
+				return
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(ev); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		case <-expired.C:
+			closeWith(websocket.ClosePolicyViolation, "access token expired")
+			return
+		case <-readDone:
+			return
+		}
+	}
+}
+
+// readFrames handles client frames until the connection fails or is closed.
+// Frames that write are dropped during a read-only impersonation
+func (h *ConversationsHandler) readFrames(ctx context.Context, conn *websocket.Conn, claims *auth.Claims) {
+	userID := claims.UserID
+	readOnly := claims.Impersonating() && h.cfg.Auth.Impersonation.ReadOnly
+	lastTyping := make(map[uint64]time.Time)
+	for {
+		var frame clientFrame
+		if err := conn.ReadJSON(&frame); err != nil {
+			if _, ok := err.(*websocket.CloseError); !ok {
+				h.log.WithError(err).Debug("websocket read failed")
+			}
+			return
+		}
+		if frame.ConversationID == 0 || !h.isUserParticipant(userID, strconv.FormatUint(frame.ConversationID, 10)) {
+			continue
+		}
+		switch frame.Type {
+		case "typing":
+			if time.Since(lastTyping[frame.ConversationID]) < typingThrottle {
+				continue
+			}
+			lastTyping[frame.ConversationID] = time.Now()
+			h.publish(ctx, realtime.EventTyping, frame.ConversationID, h.participantIDs(frame.ConversationID, userID),
+				gin.H{"conversation_id": frame.ConversationID, "user_id": userID})
+		case "read":
+			if frame.MessageID == 0 || readOnly {
+				continue
+			}
+			if err := h.markRead(ctx, userID, frame.ConversationID, frame.MessageID); errors.Is(err, errMessageNotInConversation) {
+				h.log.WithError(err).Debug("ignored read frame")
+			} else if err != nil {
+				h.log.WithError(err).Error("failed to update last read message")
+			}
+		}
+	}
+}
+
+// errMessageNotInConversation is returned when marking as read a message of another conversation
+var errMessageNotInConversation = errors.New("message not in conversation")
+
+// markRead records that userID read conversationID up to messageID and notifies the participants
+func (h *ConversationsHandler) markRead(ctx context.Context, userID, conversationID, messageID uint64) error {
+	var found int64
+	if err := h.db.Model(&models.Message{}).
+		Where("id = ? AND conversation_id = ?", messageID, conversationID).
+		Count(&found).Error; err != nil {
+		return fmt.Errorf("check message: %w", err)
+	}
+	if found == 0 {
+		return errMessageNotInConversation
+	}
+	if err := h.db.Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Update("last_read_message_id", messageID).Error; err != nil {
+		return fmt.Errorf("update last_read_message_id: %w", err)
+	}
+
+	messageRead := models.MessageRead{
+		MessageID: messageID,
+		UserID:    userID,
+	}
+	if err := h.db.Create(&messageRead).Error; err != nil {
+		h.log.WithError(err).Debug("failed to create message read record (might already exist)")
+	}
+
+	h.publish(ctx, realtime.EventMessageRead, conversationID, h.participantIDs(conversationID, 0),
+		gin.H{"conversation_id": conversationID, "user_id": userID, "message_id": messageID})
+	h.publishUnread(ctx, conversationID, userID)
+	return nil
+}
+
+// publishUnread sends each user their unread count in conversationID and across all their conversations
+func (h *ConversationsHandler) publishUnread(ctx context.Context, conversationID uint64, userIDs ...uint64) {
+	if h.broker == nil {
+		return
+	}
+	for _, userID := range userIDs {
+		h.publish(ctx, realtime.EventUnreadUpdated, conversationID, []uint64{userID}, gin.H{
+			"conversation_id": conversationID,
+			"unread_count":    h.getUnreadCount(userID, conversationID),
+			"total_unread":    h.getTotalUnreadCount(userID),
+		})
+	}
+}
+
+// participantIDs lists the current users of a conversation, leaving out except
+func (h *ConversationsHandler) participantIDs(conversationID, except uint64) []uint64 {
+	var ids []uint64
+	if err := h.db.Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id <> ? AND left_at IS NULL", conversationID, except).
+		Pluck("user_id", &ids).Error; err != nil {
+		h.log.WithError(err).Error("failed to list conversation participants")
+	}
+	return ids
+}
+
+// publish sends an event to recipients; failures are logged since clients can always resynchronize over REST
+func (h *ConversationsHandler) publish(ctx context.Context, typ string, conversationID uint64, recipients []uint64, data interface{}) {
+	if h.broker == nil || len(recipients) == 0 {
+		return
+	}
+	ev, err := realtime.NewEvent(typ, conversationID, recipients, data)
+	if err == nil {
+		err = h.broker.Publish(ctx, ev)
+	}
+	if err != nil {
+		h.log.WithError(err).WithField("type", typ).Warn("failed to publish realtime event")
+	}
+}
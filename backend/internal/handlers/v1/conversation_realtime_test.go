@@ -0,0 +1,167 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversationsHandler_Stream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupConversationsDB(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// Every connection to ":memory:" opens a separate database
+	sqlDB.SetMaxOpenConns(1)
+
+	cfg := &config.Config{
+		Auth: config.AuthConfig{
+			JWT:           config.JWTConfig{Secret: "test-secret"},
+			Impersonation: config.ImpersonationConfig{ReadOnly: true},
+		},
+		Security: config.SecurityConfig{CORS: config.CORSConfig{AllowedOrigins: []string{"http://app.local"}}},
+	}
+	broker := realtime.NewMemoryBroker(0)
+	h := v1.NewConversationsHandler(cfg, db, logrus.New(), broker, nil)
+	r := gin.New()
+	r.Use(middleware.AuthRequired(cfg))
+	r.GET("/conversations/ws", h.Stream)
+	r.POST("/conversations/:id/messages", h.SendMessage)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	for _, u := range []models.User{
+		{ID: 1, Email: "user1@test.com", Name: "User 1", Role: "founder"},
+		{ID: 2, Email: "user2@test.com", Name: "User 2", Role: "investor"},
+		{ID: 3, Email: "user3@test.com", Name: "User 3", Role: "investor"},
+	} {
+		require.NoError(t, db.Create(&u).Error)
+	}
+	conv := models.Conversation{}
+	require.NoError(t, db.Create(&conv).Error)
+	for _, id := range []uint64{1, 2} {
+		require.NoError(t, db.Create(&models.ConversationParticipant{ConversationID: conv.ID, UserID: id, Role: "member"}).Error)
+	}
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/conversations/ws"
+	dial := func(userID uint64, origin string) (*websocket.Conn, *http.Response, error) {
+		header := http.Header{}
+		header.Set("Cookie", "access_token="+createTestToken(userID, fmt.Sprintf("user%d@test.com", userID), "founder"))
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		return websocket.DefaultDialer.Dial(wsURL, header)
+	}
+	next := func(conn *websocket.Conn) realtime.Event {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		var ev realtime.Event
+		require.NoError(t, conn.ReadJSON(&ev))
+		return ev
+	}
+
+	// Cross-site pages cannot open a socket with the user's cookie
+	_, resp, err := dial(2, "http://evil.local")
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	_, resp, err = websocket.DefaultDialer.Dial(wsURL, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	alice, _, err := dial(1, "http://app.local")
+	require.NoError(t, err)
+	defer alice.Close()
+	bob, _, err := dial(2, "")
+	require.NoError(t, err)
+	defer bob.Close()
+	require.Eventually(t, func() bool { return broker.Connections() == 2 }, time.Second, 10*time.Millisecond)
+
+	// Messages sent over REST are pushed to every participant
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/conversations/%d/messages", srv.URL, conv.ID), strings.NewReader(`{"content":"Hello Bob"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(1, "user1@test.com", "founder")})
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	ev := next(bob)
+	assert.Equal(t, realtime.EventMessageCreated, ev.Type)
+	assert.Equal(t, conv.ID, ev.ConversationID)
+	var msg models.Message
+	require.NoError(t, json.Unmarshal(ev.Data, &msg))
+	assert.Equal(t, "Hello Bob", msg.Content)
+	ev = next(bob)
+	assert.Equal(t, realtime.EventUnreadUpdated, ev.Type)
+	assert.JSONEq(t, fmt.Sprintf(`{"conversation_id":%d,"unread_count":1,"total_unread":1}`, conv.ID), string(ev.Data))
+	assert.Equal(t, realtime.EventMessageCreated, next(alice).Type)
+
+	// Typing goes to the other participants only, read receipts to everyone
+	require.NoError(t, bob.WriteJSON(map[string]interface{}{"type": "typing", "conversation_id": conv.ID}))
+	ev = next(alice)
+	assert.Equal(t, realtime.EventTyping, ev.Type)
+	assert.JSONEq(t, fmt.Sprintf(`{"conversation_id":%d,"user_id":2}`, conv.ID), string(ev.Data))
+
+	require.NoError(t, bob.WriteJSON(map[string]interface{}{"type": "read", "conversation_id": conv.ID, "message_id": msg.ID}))
+	ev = next(alice)
+	assert.Equal(t, realtime.EventMessageRead, ev.Type)
+	assert.Equal(t, realtime.EventMessageRead, next(bob).Type)
+	ev = next(bob)
+	assert.Equal(t, realtime.EventUnreadUpdated, ev.Type)
+	assert.Contains(t, string(ev.Data), `"total_unread":0`)
+	var participant models.ConversationParticipant
+	require.NoError(t, db.Where("conversation_id = ? AND user_id = ?", conv.ID, 2).First(&participant).Error)
+	require.NotNil(t, participant.LastReadMessageID)
+	assert.Equal(t, msg.ID, *participant.LastReadMessageID)
+
+	// Read receipts are not written during a read-only impersonation
+	later := models.Message{ConversationID: conv.ID, SenderID: 1, Content: "Still there?"}
+	require.NoError(t, db.Create(&later).Error)
+	token, _, err := auth.GenerateImpersonationToken(cfg, 2, "user2@test.com", "investor", 1, "user1@test.com", time.Minute)
+	require.NoError(t, err)
+	header := http.Header{}
+	header.Set("Cookie", "access_token="+token)
+	impersonated, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	require.NoError(t, err)
+	defer impersonated.Close()
+	require.NoError(t, impersonated.WriteJSON(map[string]interface{}{"type": "read", "conversation_id": conv.ID, "message_id": later.ID}))
+	require.NoError(t, impersonated.WriteJSON(map[string]interface{}{"type": "typing", "conversation_id": conv.ID}))
+	assert.Equal(t, realtime.EventTyping, next(alice).Type)
+	require.NoError(t, db.Where("conversation_id = ? AND user_id = ?", conv.ID, 2).First(&participant).Error)
+	assert.Equal(t, msg.ID, *participant.LastReadMessageID)
+
+	// Only messages of the conversation can be marked as read
+	other := models.Conversation{}
+	require.NoError(t, db.Create(&other).Error)
+	foreign := models.Message{ConversationID: other.ID, SenderID: 3, Content: "elsewhere"}
+	require.NoError(t, db.Create(&foreign).Error)
+	require.NoError(t, bob.WriteJSON(map[string]interface{}{"type": "read", "conversation_id": conv.ID, "message_id": foreign.ID}))
+	require.NoError(t, bob.WriteJSON(map[string]interface{}{"type": "read", "conversation_id": conv.ID, "message_id": later.ID}))
+	ev = next(alice)
+	assert.Equal(t, realtime.EventMessageRead, ev.Type)
+	assert.Contains(t, string(ev.Data), fmt.Sprintf(`"message_id":%d`, later.ID))
+
+	// Frames about conversations the user is not part of are ignored
+	carol, _, err := dial(3, "")
+	require.NoError(t, err)
+	defer carol.Close()
+	require.NoError(t, carol.WriteJSON(map[string]interface{}{"type": "typing", "conversation_id": conv.ID}))
+	require.NoError(t, alice.SetReadDeadline(time.Now().Add(200*time.Millisecond)))
+	_, _, err = alice.ReadMessage()
+	assert.Error(t, err, "no event expected")
+}
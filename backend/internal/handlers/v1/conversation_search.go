@@ -0,0 +1,136 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/http/pagination"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/search"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// messageHit is a row of the message search query
+type messageHit struct {
+	ID             uint64
+	ConversationID uint64
+	Rank           float64
+	Snippet        string
+}
+
+// SearchMessages godoc
+// @Summary      Search messages
+// @Description  Full-text search over the messages of the conversations the user takes part in, best matches first. q accepts web-search syntax: "quoted phrases", OR, and -excluded words.
+// @Description  Messages sent by users the caller blocked are left out.
+// @Description  Each hit carries the message, its conversation, a snippet where matches are wrapped in <mark> (the rest is HTML-escaped) and its rank.
+// @Tags         Conversations
+// @Security     CookieAuth
+// @Produce      json
+// @Param        q                query string true  "Search terms" minlength(2) maxlength(200)
+// @Param        conversation_id  query int    false "Only search this conversation"
+// @Param        page             query int    false "Page" default(1)
+// @Param        per_page         query int    false "Page size" default(20)
+// @Success      200 {object} response.MessageSearchResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /conversations/search [get]
+func (h *ConversationsHandler) SearchMessages(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	q := strings.TrimSpace(c.Query("q"))
+	if !search.ValidQuery(q) {
+		response.JSONError(c, http.StatusBadRequest, "invalid_query", "q must be between 2 and 200 characters", nil)
+		return
+	}
+	conversationID, err := optionalID(c.Query("conversation_id"))
+	if err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_params", "invalid conversation_id", nil)
+		return
+	}
+	params := pagination.Parse(c)
+
+	base := h.db.Table("messages m").
+		Joins("JOIN conversation_participants cp ON cp.conversation_id = m.conversation_id AND cp.user_id = ? AND cp.left_at IS NULL", claims.UserID).
+		Joins("CROSS JOIN websearch_to_tsquery('simple', ?) query", q).
+		Where("m.deleted_at IS NULL AND m.kind = ? AND m.search_vector @@ query", models.MessageKindText).
+		Where("m.sender_id NOT IN (SELECT blocked_id FROM user_blocks WHERE blocker_id = cp.user_id)")
+	if conversationID > 0 {
+		base = base.Where("m.conversation_id = ?", conversationID)
+	}
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		h.log.WithError(err).Error("failed to count message search results")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to search messages", nil)
+		return
+	}
+	var hits []messageHit
+	if err := base.Session(&gorm.Session{}).
+		Select("m.id, m.conversation_id, ts_rank(m.search_vector, query) AS rank, ts_headline('simple', m.content, query, ?) AS snippet", search.HeadlineOptions).
+		Order("rank DESC, m.id DESC").
+		Offset((params.Page - 1) * params.PerPage).
+		Limit(params.PerPage).
+		Scan(&hits).Error; err != nil {
+		h.log.WithError(err).Error("failed to search messages")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to search messages", nil)
+		return
+	}
+
+	messageIDs := make([]uint64, len(hits))
+	conversationIDs := make([]uint64, 0, len(hits))
+	for i, hit := range hits {
+		messageIDs[i] = hit.ID
+		conversationIDs = append(conversationIDs, hit.ConversationID)
+	}
+	messages := make(map[uint64]models.Message, len(hits))
+	conversations := make(map[uint64]models.Conversation)
+	if len(hits) > 0 {
+		var ms []models.Message
+		if err := h.db.Preload("Sender").Preload("Attachments").Where("id IN ?", messageIDs).Find(&ms).Error; err != nil {
+			h.log.WithError(err).Error("failed to load searched messages")
+			response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to search messages", nil)
+			return
+		}
+		for _, m := range ms {
+			messages[m.ID] = m
+		}
+		var cs []models.Conversation
+		if err := h.db.Where("id IN ?", conversationIDs).Find(&cs).Error; err != nil {
+			h.log.WithError(err).Error("failed to load searched conversations")
+			response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to search messages", nil)
+			return
+		}
+		for _, conv := range cs {
+			conversations[conv.ID] = conv
+		}
+	}
+
+	results := make([]response.MessageSearchHit, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, response.MessageSearchHit{
+			Conversation: conversations[hit.ConversationID],
+			Message:      messages[hit.ID],
+			Snippet:      search.Highlight(hit.Snippet),
+			Rank:         hit.Rank,
+		})
+	}
+
+	totalPages := (int(total) + params.PerPage - 1) / params.PerPage
+	response.JSON(c, http.StatusOK, gin.H{
+		"data": results,
+		"pagination": gin.H{
+			"page":     params.Page,
+			"per_page": params.PerPage,
+			"total":    total,
+			"has_next": params.Page < totalPages,
+			"has_prev": params.Page > 1,
+		},
+	})
+}
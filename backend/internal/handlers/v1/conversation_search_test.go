@@ -0,0 +1,42 @@
+package v1_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// The ranking itself relies on Postgres full-text search, so only the
+// validation is exercised here
+func TestConversationsHandler_SearchMessagesValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupConversationsDB(t)
+	cfg := &config.Config{Auth: config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret"}}}
+	h := v1.NewConversationsHandler(cfg, db, logrus.New(), nil, nil)
+	r := gin.New()
+	r.Use(middleware.AuthRequired(cfg))
+	r.GET("/conversations/search", h.SearchMessages)
+
+	for query, want := range map[string]int{
+		"":                           http.StatusBadRequest,
+		"?q=%20a%20":                 http.StatusBadRequest,
+		"?q=pitch&conversation_id=x": http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/conversations/search"+query, nil)
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(1, "user1@test.com", "founder")})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, want, w.Code, query)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/conversations/search?q=pitch", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -28,7 +28,7 @@ func setupConversationsDB(t *testing.T) *gorm.DB {
 	}
 
 	err = db.AutoMigrate(&models.User{}, &models.Conversation{},
-		&models.ConversationParticipant{}, &models.Message{}, &models.MessageRead{})
+		&models.ConversationParticipant{}, &models.Message{}, &models.MessageRead{}, &models.MessageAttachment{}, &models.UserBlock{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -62,13 +62,13 @@ func createTestToken(userID uint64, email, role string) string {
 		},
 	}
 
-	pair, _ := auth.GenerateTokenPair(cfg, userID, email, role)
+	pair, _ := auth.GenerateTokenPair(cfg, userID, email, role, 0)
 	return pair.AccessToken
 }
 
 func TestConversationsHandler_FullCoverage(t *testing.T) {
 	db := setupConversationsDB(t)
-	h := v1.NewConversationsHandler(db, logrus.New())
+	h := v1.NewConversationsHandler(&config.Config{}, db, logrus.New(), nil, nil)
 	r := setupConversationsRouter(h)
 
 	user1 := models.User{ID: 1, Email: "user1@test.com", Name: "User 1", Role: "founder"}
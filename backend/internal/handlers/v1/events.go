@@ -105,6 +105,15 @@ func (h *EventsHandler) GetEvents(c *gin.Context) {
 		return
 	}
 
+	ids := make([]uint64, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	favorited := favoritedIDs(c, h.db, h.log, models.FavoriteTargetEvent, ids)
+	for i := range events {
+		events[i].Favorited = favorited[events[i].ID]
+	}
+
 	totalPages := (int(total) + params.pagination.PerPage - 1) / params.pagination.PerPage
 	response.JSON(c, http.StatusOK, gin.H{
 		"data": events,
@@ -148,6 +157,8 @@ func (h *EventsHandler) GetEvent(c *gin.Context) {
 		return
 	}
 
+	event.Favorited = favoritedIDs(c, h.db, h.log, models.FavoriteTargetEvent, []uint64{event.ID})[event.ID]
+
 	response.JSON(c, http.StatusOK, gin.H{
 		"data": event,
 	})
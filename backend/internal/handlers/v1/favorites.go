@@ -0,0 +1,289 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/http/pagination"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type FavoritesHandler struct {
+	db  *gorm.DB
+	log *logrus.Logger
+}
+
+func NewFavoritesHandler(db *gorm.DB, log *logrus.Logger) *FavoritesHandler {
+	return &FavoritesHandler{
+		db:  db,
+		log: log,
+	}
+}
+
+// ListFavorites godoc
+// @Summary      List favorites
+// @Description  Returns the startups, events and opportunities saved by the authenticated user, most recent first.
+// @Description  Favorites whose entity was deleted in the meantime have no target.
+// @Tags         Favorites
+// @Security     CookieAuth
+// @Produce      json
+// @Param        type     query string false "Only this kind of entity" Enums(startup,event,opportunity)
+// @Param        page     query int    false "Page" default(1)
+// @Param        per_page query int    false "Page size" default(20)
+// @Success      200 {object} response.FavoriteListResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /me/favorites [get]
+func (h *FavoritesHandler) ListFavorites(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	params := pagination.Parse(c)
+	query := h.db.Model(&models.Favorite{}).Where("user_id = ?", claims.UserID)
+	if targetType := c.Query("type"); targetType != "" {
+		if !slices.Contains(models.FavoriteTargets, targetType) {
+			response.JSONError(c, http.StatusBadRequest, "invalid_params", "type must be one of startup, event, opportunity", nil)
+			return
+		}
+		query = query.Where("target_type = ?", targetType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		h.log.WithError(err).Error("failed to count favorites")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve favorites", nil)
+		return
+	}
+	favorites := make([]models.Favorite, 0)
+	if err := query.Order("created_at DESC, id DESC").
+		Offset((params.Page - 1) * params.PerPage).
+		Limit(params.PerPage).
+		Find(&favorites).Error; err != nil {
+		h.log.WithError(err).Error("failed to list favorites")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve favorites", nil)
+		return
+	}
+	if err := h.loadTargets(favorites); err != nil {
+		h.log.WithError(err).Error("failed to load favorite targets")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve favorites", nil)
+		return
+	}
+
+	totalPages := (int(total) + params.PerPage - 1) / params.PerPage
+	response.JSON(c, http.StatusOK, gin.H{
+		"data": favorites,
+		"pagination": gin.H{
+			"page":     params.Page,
+			"per_page": params.PerPage,
+			"total":    total,
+			"has_next": params.Page < totalPages,
+			"has_prev": params.Page > 1,
+		},
+	})
+}
+
+// AddFavorite godoc
+// @Summary      Add a favorite
+// @Description  Saves a startup, an event or an opportunity for the authenticated user.
+// @Description  Saving an entity already saved returns the existing favorite with 200.
+// @Tags         Favorites
+// @Security     CookieAuth
+// @Produce      json
+// @Param        type path string true "Kind of entity" Enums(startup,event,opportunity)
+// @Param        id   path int    true "Entity ID"
+// @Success      200 {object} response.FavoriteObjectResponse
+// @Success      201 {object} response.FavoriteObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /me/favorites/{type}/{id} [post]
+func (h *FavoritesHandler) AddFavorite(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	targetType, targetID, ok := favoriteTargetParams(c)
+	if !ok {
+		return
+	}
+	target := newFavoriteTarget(targetType)
+	if err := h.db.First(target, targetID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusNotFound, "not_found", targetType+" not found", nil)
+			return
+		}
+		h.log.WithError(err).Errorf("failed to fetch %s", targetType)
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to add favorite", nil)
+		return
+	}
+
+	favorite := models.Favorite{UserID: claims.UserID, TargetType: targetType, TargetID: targetID}
+	result := h.db.Where(&favorite).FirstOrCreate(&favorite)
+	if result.Error != nil {
+		h.log.WithError(result.Error).Error("failed to add favorite")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to add favorite", nil)
+		return
+	}
+	setFavorited(target)
+	favorite.Target = target
+	status := http.StatusOK
+	if result.RowsAffected > 0 {
+		status = http.StatusCreated
+	}
+	response.JSON(c, status, gin.H{"data": favorite})
+}
+
+// RemoveFavorite godoc
+// @Summary      Remove a favorite
+// @Tags         Favorites
+// @Security     CookieAuth
+// @Produce      json
+// @Param        type path string true "Kind of entity" Enums(startup,event,opportunity)
+// @Param        id   path int    true "Entity ID"
+// @Success      200 {object} response.MessageResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /me/favorites/{type}/{id} [delete]
+func (h *FavoritesHandler) RemoveFavorite(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	targetType, targetID, ok := favoriteTargetParams(c)
+	if !ok {
+		return
+	}
+	result := h.db.Where("user_id = ? AND target_type = ? AND target_id = ?", claims.UserID, targetType, targetID).Delete(&models.Favorite{})
+	if result.Error != nil {
+		h.log.WithError(result.Error).Error("failed to remove favorite")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to remove favorite", nil)
+		return
+	}
+	if result.RowsAffected == 0 {
+		response.JSONError(c, http.StatusNotFound, "not_found", targetType+" is not a favorite", nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"message": "favorite removed"})
+}
+
+// favoriteTargetParams reads the type and id path parameters
+func favoriteTargetParams(c *gin.Context) (string, uint64, bool) {
+	targetType := c.Param("type")
+	if !slices.Contains(models.FavoriteTargets, targetType) {
+		response.JSONError(c, http.StatusBadRequest, "invalid_params", "type must be one of startup, event, opportunity", nil)
+		return "", 0, false
+	}
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_id", "invalid "+targetType+" id", nil)
+		return "", 0, false
+	}
+	return targetType, targetID, true
+}
+
+func newFavoriteTarget(targetType string) interface{} {
+	switch targetType {
+	case models.FavoriteTargetStartup:
+		return &models.Startup{}
+	case models.FavoriteTargetEvent:
+		return &models.Event{}
+	default:
+		return &models.Opportunity{}
+	}
+}
+
+func setFavorited(target interface{}) {
+	switch t := target.(type) {
+	case *models.Startup:
+		t.Favorited = true
+	case *models.Event:
+		t.Favorited = true
+	case *models.Opportunity:
+		t.Favorited = true
+	}
+}
+
+// loadTargets attaches the saved entities to favorites with one query per kind
+func (h *FavoritesHandler) loadTargets(favorites []models.Favorite) error {
+	ids := make(map[string][]uint64)
+	for _, f := range favorites {
+		ids[f.TargetType] = append(ids[f.TargetType], f.TargetID)
+	}
+	targets := make(map[string]map[uint64]interface{})
+	if len(ids[models.FavoriteTargetStartup]) > 0 {
+		var startups []models.Startup
+		if err := h.db.Where("id IN ?", ids[models.FavoriteTargetStartup]).Find(&startups).Error; err != nil {
+			return err
+		}
+		targets[models.FavoriteTargetStartup] = make(map[uint64]interface{}, len(startups))
+		for i := range startups {
+			startups[i].Favorited = true
+			targets[models.FavoriteTargetStartup][startups[i].ID] = &startups[i]
+		}
+	}
+	if len(ids[models.FavoriteTargetEvent]) > 0 {
+		var events []models.Event
+		if err := h.db.Where("id IN ?", ids[models.FavoriteTargetEvent]).Find(&events).Error; err != nil {
+			return err
+		}
+		targets[models.FavoriteTargetEvent] = make(map[uint64]interface{}, len(events))
+		for i := range events {
+			events[i].Favorited = true
+			targets[models.FavoriteTargetEvent][events[i].ID] = &events[i]
+		}
+	}
+	if len(ids[models.FavoriteTargetOpportunity]) > 0 {
+		var opportunities []models.Opportunity
+		if err := h.db.Where("id IN ?", ids[models.FavoriteTargetOpportunity]).Find(&opportunities).Error; err != nil {
+			return err
+		}
+		targets[models.FavoriteTargetOpportunity] = make(map[uint64]interface{}, len(opportunities))
+		for i := range opportunities {
+			opportunities[i].Favorited = true
+			targets[models.FavoriteTargetOpportunity][uint64(opportunities[i].ID)] = &opportunities[i]
+		}
+	}
+	for i := range favorites {
+		if target, ok := targets[favorites[i].TargetType][favorites[i].TargetID]; ok {
+			favorites[i].Target = target
+		}
+	}
+	return nil
+}
+
+// favoritedIDs returns which of ids the authenticated user saved. Anonymous callers have no favorites,
+// and a failed lookup is logged rather than failing the listing it decorates
+func favoritedIDs(c *gin.Context, db *gorm.DB, log *logrus.Logger, targetType string, ids []uint64) map[uint64]bool {
+	favorited := make(map[uint64]bool)
+	claims := middleware.GetClaims(c)
+	if claims == nil || len(ids) == 0 {
+		return favorited
+	}
+	var saved []uint64
+	if err := db.Model(&models.Favorite{}).
+		Where("user_id = ? AND target_type = ? AND target_id IN ?", claims.UserID, targetType, ids).
+		Pluck("target_id", &saved).Error; err != nil {
+		log.WithError(err).Warnf("failed to load favorite %ss", targetType)
+		return favorited
+	}
+	for _, id := range saved {
+		favorited[id] = true
+	}
+	return favorited
+}
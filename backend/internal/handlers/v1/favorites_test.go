@@ -0,0 +1,148 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFavoritesHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupUsersDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Startup{}, &models.Event{}, &models.Opportunity{}, &models.Favorite{}))
+	cfg := &config.Config{Auth: config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret"}}}
+	log := logrus.New()
+	h := v1.NewFavoritesHandler(db, log)
+	startups := v1.NewStartupsHandler(db, log)
+	events := v1.NewEventsHandler(log, db, nil)
+	opportunities := v1.NewOpportunityHandler(log, db)
+	stats := v1.NewStatisticsHandler(db, log)
+	r := gin.New()
+	r.GET("/startups", middleware.AuthOptional(cfg), startups.ListStartups)
+	r.GET("/startups/:id", middleware.AuthOptional(cfg), startups.GetStartup)
+	r.GET("/events/:id", middleware.AuthOptional(cfg), events.GetEvent)
+	r.GET("/opportunities", middleware.AuthOptional(cfg), opportunities.GetOpportunities)
+	me := r.Group("/me/favorites", middleware.AuthRequired(cfg))
+	me.GET("", h.ListFavorites)
+	me.POST("/:type/:id", h.AddFavorite)
+	me.DELETE("/:type/:id", h.RemoveFavorite)
+	r.GET("/admin/statistics", stats.GetStatistics)
+	r.GET("/admin/statistics/top", stats.GetTopProjects)
+
+	for _, u := range []models.User{
+		{ID: 1, Email: "alice@test.com", Name: "Alice", Role: "investor"},
+		{ID: 2, Email: "bob@test.com", Name: "Bob", Role: "founder"},
+	} {
+		require.NoError(t, db.Create(&u).Error)
+	}
+	acme := models.Startup{Name: "Acme", ViewsCount: 10}
+	globex := models.Startup{Name: "Globex", ViewsCount: 5}
+	require.NoError(t, db.Create(&acme).Error)
+	require.NoError(t, db.Create(&globex).Error)
+	conf := models.Event{Name: "Conf"}
+	require.NoError(t, db.Create(&conf).Error)
+	grant := models.Opportunity{Title: "AI Grant", Type: "grant", Organism: "EU"}
+	require.NoError(t, db.Create(&grant).Error)
+
+	do := func(userID uint64, method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if userID != 0 {
+			req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(userID, fmt.Sprintf("user%d@test.com", userID), "investor")})
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Only existing entities of a known kind can be saved, once
+	assert.Equal(t, http.StatusUnauthorized, do(0, http.MethodPost, fmt.Sprintf("/me/favorites/startup/%d", acme.ID)).Code)
+	assert.Equal(t, http.StatusBadRequest, do(1, http.MethodPost, "/me/favorites/news/1").Code)
+	assert.Equal(t, http.StatusBadRequest, do(1, http.MethodPost, "/me/favorites/startup/abc").Code)
+	assert.Equal(t, http.StatusNotFound, do(1, http.MethodPost, "/me/favorites/event/99").Code)
+	w := do(1, http.MethodPost, fmt.Sprintf("/me/favorites/startup/%d", acme.ID))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"favorited":true`)
+	assert.Equal(t, http.StatusOK, do(1, http.MethodPost, fmt.Sprintf("/me/favorites/startup/%d", acme.ID)).Code)
+	require.Equal(t, http.StatusCreated, do(1, http.MethodPost, fmt.Sprintf("/me/favorites/event/%d", conf.ID)).Code)
+	require.Equal(t, http.StatusCreated, do(1, http.MethodPost, fmt.Sprintf("/me/favorites/opportunity/%d", grant.ID)).Code)
+	require.Equal(t, http.StatusCreated, do(2, http.MethodPost, fmt.Sprintf("/me/favorites/startup/%d", acme.ID)).Code)
+
+	// Lists and details tell each user what they saved
+	var list struct {
+		Data []models.Startup `json:"data"`
+	}
+	w = do(1, http.MethodGet, "/startups?sort=id&order=asc")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Data, 2)
+	assert.True(t, list.Data[0].Favorited)
+	assert.False(t, list.Data[1].Favorited)
+	assert.NotContains(t, do(0, http.MethodGet, "/startups").Body.String(), `"favorited":true`)
+	assert.Contains(t, do(1, http.MethodGet, fmt.Sprintf("/startups/%d", acme.ID)).Body.String(), `"favorited":true`)
+	assert.Contains(t, do(2, http.MethodGet, fmt.Sprintf("/events/%d", conf.ID)).Body.String(), `"favorited":false`)
+	assert.Contains(t, do(1, http.MethodGet, fmt.Sprintf("/events/%d", conf.ID)).Body.String(), `"favorited":true`)
+	assert.Contains(t, do(1, http.MethodGet, "/opportunities").Body.String(), `"favorited":true`)
+
+	// The favorites list embeds the saved entities and can be narrowed to a kind
+	var favorites struct {
+		Data []struct {
+			TargetType string                 `json:"target_type"`
+			Target     map[string]interface{} `json:"target"`
+		} `json:"data"`
+	}
+	w = do(1, http.MethodGet, "/me/favorites")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &favorites))
+	require.Len(t, favorites.Data, 3)
+	assert.Equal(t, models.FavoriteTargetOpportunity, favorites.Data[0].TargetType)
+	assert.Equal(t, "AI Grant", favorites.Data[0].Target["title"])
+	assert.Equal(t, "Acme", favorites.Data[2].Target["name"])
+	w = do(1, http.MethodGet, "/me/favorites?type=event")
+	assert.Contains(t, w.Body.String(), `"total":1`)
+	assert.Equal(t, http.StatusBadRequest, do(1, http.MethodGet, "/me/favorites?type=news").Code)
+
+	// Favorite counts feed the statistics
+	w = do(0, http.MethodGet, "/admin/statistics")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"total_favorites":4`)
+	assert.Contains(t, w.Body.String(), `"favorites_by_type":{"event":1,"opportunity":1,"startup":2}`)
+	var top struct {
+		TopProjects []struct {
+			Title string `json:"title"`
+			Likes int64  `json:"likes"`
+		} `json:"top_projects"`
+	}
+	w = do(0, http.MethodGet, "/admin/statistics/top")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &top))
+	require.Len(t, top.TopProjects, 2)
+	assert.Equal(t, int64(2), top.TopProjects[0].Likes)
+	assert.Equal(t, int64(0), top.TopProjects[1].Likes)
+
+	// Removing a favorite
+	require.Equal(t, http.StatusOK, do(1, http.MethodDelete, fmt.Sprintf("/me/favorites/startup/%d", acme.ID)).Code)
+	assert.Equal(t, http.StatusNotFound, do(1, http.MethodDelete, fmt.Sprintf("/me/favorites/startup/%d", acme.ID)).Code)
+	assert.Contains(t, do(1, http.MethodGet, fmt.Sprintf("/startups/%d", acme.ID)).Body.String(), `"favorited":false`)
+
+	// The statistics fall back to no favorites when they cannot be counted
+	require.NoError(t, db.Migrator().DropTable(&models.Favorite{}))
+	w = do(0, http.MethodGet, "/admin/statistics")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"total_favorites":0`)
+	w = do(0, http.MethodGet, "/admin/statistics/top")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &top))
+	require.Len(t, top.TopProjects, 2)
+	assert.Equal(t, int64(0), top.TopProjects[0].Likes)
+}
@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/features"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type FeaturesHandler struct {
+	log   *logrus.Logger
+	flags *features.Store
+}
+
+func NewFeaturesHandler(log *logrus.Logger, flags *features.Store) *FeaturesHandler {
+	return &FeaturesHandler{log: log, flags: flags}
+}
+
+// GetFeatures godoc
+// @Summary      List enabled features
+// @Description  Returns whether each feature is on, so that the frontend can hide what is disabled. The routes of a disabled feature answer 404 feature_disabled.
+// @Tags         Features
+// @Produce      json
+// @Success      200 {object} response.FeaturesResponse
+// @Router       /features [get]
+func (h *FeaturesHandler) GetFeatures(c *gin.Context) {
+	enabled := make(map[string]bool, len(features.Names))
+	for _, name := range features.Names {
+		enabled[name] = h.flags.Enabled(c.Request.Context(), name)
+	}
+	response.JSON(c, http.StatusOK, gin.H{"data": enabled})
+}
+
+// ListFeatureFlags godoc
+// @Summary      List feature flags
+// @Description  Returns every flag with its configured default and the runtime override, if any.
+// @Tags         Features
+// @Security     CookieAuth
+// @Produce      json
+// @Success      200 {object} response.FeatureFlagListResponse
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/features [get]
+func (h *FeaturesHandler) ListFeatureFlags(c *gin.Context) {
+	flags, err := h.flags.All(c.Request.Context())
+	if err != nil {
+		h.log.WithError(err).Error("failed to list feature flags")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve feature flags", nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"data": flags})
+}
+
+// SetFeatureFlag godoc
+// @Summary      Override a feature flag
+// @Description  Turns a feature on or off at runtime, whatever the configuration says. Other replicas apply it within seconds.
+// @Tags         Features
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        id      path string true "Flag name" Enums(messaging,opportunities,favorites)
+// @Param        payload body requests.FeatureFlagRequest true "State" Example({"enabled":false})
+// @Success      200 {object} response.FeatureFlagObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/features/{id} [put]
+func (h *FeaturesHandler) SetFeatureFlag(c *gin.Context) {
+	var req struct {
+		Enabled *bool `json:"enabled" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+	h.setFlag(c, req.Enabled)
+}
+
+// ResetFeatureFlag godoc
+// @Summary      Reset a feature flag
+// @Description  Removes the runtime override, so that the configuration applies again.
+// @Tags         Features
+// @Security     CookieAuth
+// @Produce      json
+// @Param        id path string true "Flag name" Enums(messaging,opportunities,favorites)
+// @Success      200 {object} response.FeatureFlagObjectResponse
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/features/{id} [delete]
+func (h *FeaturesHandler) ResetFeatureFlag(c *gin.Context) {
+	h.setFlag(c, nil)
+}
+
+func (h *FeaturesHandler) setFlag(c *gin.Context, enabled *bool) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	flag, err := h.flags.Set(c.Request.Context(), c.Param("id"), enabled, claims.UserID)
+	if errors.Is(err, features.ErrUnknownFlag) {
+		response.JSONError(c, http.StatusNotFound, "not_found", "unknown feature flag", nil)
+		return
+	}
+	if err != nil {
+		h.log.WithError(err).Error("failed to update feature flag")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to update feature flag", nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"data": flag})
+}
@@ -0,0 +1,77 @@
+package v1_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/features"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeaturesHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupUsersDB(t)
+	seedRoles(t, db)
+	require.NoError(t, db.AutoMigrate(&models.FeatureFlag{}))
+	require.NoError(t, db.Create(&models.User{ID: 1, Email: "admin@test.com", Name: "Admin", Role: "admin"}).Error)
+	require.NoError(t, db.Create(&models.User{ID: 2, Email: "user@test.com", Name: "User", Role: "founder"}).Error)
+
+	cfg := &config.Config{
+		Auth:     config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret"}},
+		Features: config.FeaturesConfig{EnableMessaging: true, EnableOpportunities: true},
+	}
+	flags := features.New(cfg.Features, db)
+	h := v1.NewFeaturesHandler(logrus.New(), flags)
+	r := gin.New()
+	r.GET("/features", h.GetFeatures)
+	admin := r.Group("/admin/features", middleware.AuthRequired(cfg), middleware.RequirePermission(db, auth.PermFeaturesManage))
+	admin.GET("", h.ListFeatureFlags)
+	admin.PUT("/:id", h.SetFeatureFlag)
+	admin.DELETE("/:id", h.ResetFeatureFlag)
+	messaging := r.Group("", middleware.RequireFeature(flags, features.Messaging))
+	messaging.GET("/conversations", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	do := func(userID uint64, role, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if userID != 0 {
+			req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(userID, "user@test.com", role)})
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(0, "", http.MethodGet, "/features", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":{"messaging":true,"opportunities":true,"favorites":false}}`, w.Body.String())
+	assert.Equal(t, http.StatusNoContent, do(0, "", http.MethodGet, "/conversations", "").Code)
+
+	// Only administrators turn features off, and the gated routes disappear at once
+	assert.Equal(t, http.StatusForbidden, do(2, "founder", http.MethodPut, "/admin/features/messaging", `{"enabled":false}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do(1, "admin", http.MethodPut, "/admin/features/messaging", `{}`).Code)
+	assert.Equal(t, http.StatusNotFound, do(1, "admin", http.MethodPut, "/admin/features/chat", `{"enabled":false}`).Code)
+	w = do(1, "admin", http.MethodPut, "/admin/features/messaging", `{"enabled":false}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"overridden":true`)
+
+	w = do(0, "", http.MethodGet, "/conversations", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "feature_disabled")
+	assert.Contains(t, do(0, "", http.MethodGet, "/features", "").Body.String(), `"messaging":false`)
+	w = do(1, "admin", http.MethodGet, "/admin/features", "")
+	assert.Contains(t, w.Body.String(), `"name":"messaging","enabled":false,"default":true,"overridden":true`)
+
+	require.Equal(t, http.StatusOK, do(1, "admin", http.MethodDelete, "/admin/features/messaging", "").Code)
+	assert.Equal(t, http.StatusNoContent, do(0, "", http.MethodGet, "/conversations", "").Code)
+}
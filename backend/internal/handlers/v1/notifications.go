@@ -0,0 +1,123 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// sseRetry is the reconnection delay suggested to EventSource clients, in milliseconds
+const sseRetry = 3000
+
+type NotificationsHandler struct {
+	cfg    *config.Config
+	db     *gorm.DB
+	log    *logrus.Logger
+	broker realtime.Broker
+}
+
+func NewNotificationsHandler(cfg *config.Config, db *gorm.DB, log *logrus.Logger, broker realtime.Broker) *NotificationsHandler {
+	return &NotificationsHandler{cfg: cfg, db: db, log: log, broker: broker}
+}
+
+// Stream godoc
+// @Summary      Notification event stream
+// @Description  Server-Sent Events stream of the authenticated user. Each event has an id, a type (the SSE event name) and a JSON payload: unread.updated (conversation_id, unread_count, total_unread), conversation.created and conversation.updated (the conversation), message.created, message.read and notification (kind, title, body, link...).
+// @Description  After a disconnection, EventSource resumes with the Last-Event-ID header and receives the events it missed if they are still retained (realtime.retention); otherwise a resync event is sent first and the client should reload its state.
+// @Tags         Notifications
+// @Security     CookieAuth
+// @Produce      text/event-stream
+// @Param        Last-Event-ID header string false "ID of the last event received"
+// @Success      200 {string} string "event stream"
+// @Failure      401 {object} response.ErrorBody
+// @Failure      503 {object} response.ErrorBody
+// @Router       /notifications/stream [get]
+func (h *NotificationsHandler) Stream(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	if h.broker == nil {
+		response.JSONError(c, http.StatusServiceUnavailable, "realtime_unavailable", "real-time events are not available", nil)
+		return
+	}
+
+	// Subscribe before reading the history so that nothing published in between is lost
+	sub := h.broker.Subscribe(claims.UserID)
+	defer sub.Close()
+
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintf(w, "retry: %d\n\n", sseRetry)
+
+	replayed := make(map[string]struct{})
+	if lastID := c.GetHeader("Last-Event-ID"); lastID != "" {
+		missed, ok := h.broker.Since(claims.UserID, lastID)
+		if !ok {
+			_, _ = fmt.Fprint(w, "event: resync\ndata: {}\n\n")
+		}
+		for _, ev := range missed {
+			replayed[ev.ID] = struct{}{}
+			writeSSE(w, ev)
+		}
+	}
+	w.Flush()
+
+	ping := h.cfg.Realtime.PingInterval
+	if ping <= 0 {
+		ping = defaultPingInterval
+	}
+	ticker := time.NewTicker(ping)
+	defer ticker.Stop()
+	expiry := time.Hour
+	if claims.ExpiresAt != nil {
+		expiry = time.Until(claims.ExpiresAt.Time)
+	}
+	expired := time.NewTimer(expiry)
+	defer expired.Stop()
+
+	ctx := c.Request.Context()
+	for {
+		select {
+		case ev, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if _, dup := replayed[ev.ID]; dup {
+				continue
+			}
+			writeSSE(w, ev)
+			w.Flush()
+		case <-ticker.C:
+			_, _ = fmt.Fprint(w, ": ping\n\n")
+			w.Flush()
+		case <-expired.C:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// writeSSE writes ev as a Server-Sent Event; the payload is the same JSON object as on the WebSocket
+func writeSSE(w gin.ResponseWriter, ev realtime.Event) {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, payload)
+}
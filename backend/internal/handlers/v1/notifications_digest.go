@@ -0,0 +1,121 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/notifications/digest"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var validMessageDigests = []string{models.MessageDigestImmediate, models.MessageDigestDaily, models.MessageDigestNever}
+
+// GetPreferences godoc
+// @Summary      Get notification preferences
+// @Description  Returns how often the current user is emailed a digest of their unread messages.
+// @Tags         Notifications
+// @Security     CookieAuth
+// @Produce      json
+// @Success      200 {object} response.NotificationPreferencesResponse
+// @Failure      401 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /notifications/preferences [get]
+func (h *NotificationsHandler) GetPreferences(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	var user models.User
+	if !h.loadUser(c, claims.UserID, &user) {
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"data": response.NotificationPreferences{MessageDigest: user.MessageDigest}})
+}
+
+// UpdatePreferences godoc
+// @Summary      Update notification preferences
+// @Description  Sets how often the current user is emailed a digest of the messages they leave unread: immediate (shortly after they arrive), daily (at most once a day) or never.
+// @Tags         Notifications
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        payload body requests.NotificationPreferencesRequest true "Preferences" Example({"message_digest":"daily"})
+// @Success      200 {object} response.NotificationPreferencesResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /notifications/preferences [patch]
+func (h *NotificationsHandler) UpdatePreferences(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+	var req struct {
+		MessageDigest string `json:"message_digest" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+	if !slices.Contains(validMessageDigests, req.MessageDigest) {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "message_digest must be immediate, daily or never", nil)
+		return
+	}
+	var user models.User
+	if !h.loadUser(c, claims.UserID, &user) {
+		return
+	}
+	if err := h.db.Model(&user).Update("message_digest", req.MessageDigest).Error; err != nil {
+		h.log.WithError(err).Error("failed to update notification preferences")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to update preferences", nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"data": response.NotificationPreferences{MessageDigest: user.MessageDigest}})
+}
+
+// Unsubscribe godoc
+// @Summary      Unsubscribe from message digests
+// @Description  One-click unsubscribe link of the digest emails: stops them by setting the preference to never. It needs no session, the token identifies the user.
+// @Tags         Notifications
+// @Produce      json
+// @Param        token query string true "Unsubscribe token from the email"
+// @Success      200 {object} response.MessageResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /notifications/unsubscribe [get]
+// @Router       /notifications/unsubscribe [post]
+func (h *NotificationsHandler) Unsubscribe(c *gin.Context) {
+	userID, ok := digest.ParseUnsubscribeToken(h.cfg.Auth.JWT.Secret, c.Query("token"))
+	if !ok {
+		response.JSONError(c, http.StatusBadRequest, "invalid_token", "invalid unsubscribe link", nil)
+		return
+	}
+	if err := h.db.Model(&models.User{}).Where("id = ?", userID).Update("message_digest", models.MessageDigestNever).Error; err != nil {
+		h.log.WithError(err).Error("failed to unsubscribe from digests")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to unsubscribe", nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"message": "you will no longer receive emails about unread messages"})
+}
+
+func (h *NotificationsHandler) loadUser(c *gin.Context, userID uint64, user *models.User) bool {
+	if err := h.db.First(user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusNotFound, "not_found", "user not found", nil)
+			return false
+		}
+		h.log.WithError(err).Error("failed to fetch current user")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve preferences", nil)
+		return false
+	}
+	return true
+}
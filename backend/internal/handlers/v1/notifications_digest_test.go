@@ -0,0 +1,56 @@
+package v1_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/notifications/digest"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationsHandler_DigestPreferences(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupConversationsDB(t)
+	cfg := &config.Config{Auth: config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret"}}}
+	h := v1.NewNotificationsHandler(cfg, db, logrus.New(), nil)
+	r := gin.New()
+	r.GET("/notifications/preferences", middleware.AuthRequired(cfg), h.GetPreferences)
+	r.PATCH("/notifications/preferences", middleware.AuthRequired(cfg), h.UpdatePreferences)
+	r.GET("/notifications/unsubscribe", h.Unsubscribe)
+
+	require.NoError(t, db.Create(&models.User{ID: 1, Email: "user1@test.com", Name: "User 1", Role: "founder"}).Error)
+	do := func(method, path, body string, auth bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth {
+			req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(1, "user1@test.com", "founder")})
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "/notifications/preferences", "", true)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"message_digest":"daily"`)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPatch, "/notifications/preferences", `{"message_digest":"hourly"}`, true).Code)
+	w = do(http.MethodPatch, "/notifications/preferences", `{"message_digest":"immediate"}`, true)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"message_digest":"immediate"`)
+
+	// The link of the emails works without a session
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodGet, "/notifications/unsubscribe?token="+digest.UnsubscribeToken("other-secret", 1), "", false).Code)
+	require.Equal(t, http.StatusOK, do(http.MethodGet, "/notifications/unsubscribe?token="+digest.UnsubscribeToken("test-secret", 1), "", false).Code)
+	var user models.User
+	require.NoError(t, db.First(&user, 1).Error)
+	assert.Equal(t, models.MessageDigestNever, user.MessageDigest)
+}
@@ -0,0 +1,108 @@
+package v1_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/notifications/inapp"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sseEvent struct {
+	ID, Type, Data string
+}
+
+func TestNotificationsHandler_Stream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{Auth: config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret"}}}
+	broker := realtime.NewMemoryBroker(0)
+	broker.KeepHistory(100, time.Minute)
+	h := v1.NewNotificationsHandler(cfg, nil, logrus.New(), broker)
+	r := gin.New()
+	r.GET("/notifications/stream", middleware.AuthRequired(cfg), h.Stream)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	open := func(lastID string) (*bufio.Reader, func()) {
+		ctx, cancel := context.WithCancel(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/notifications/stream", nil)
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: createTestToken(1, "user1@test.com", "founder")})
+		if lastID != "" {
+			req.Header.Set("Last-Event-ID", lastID)
+		}
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+		return bufio.NewReader(res.Body), func() { cancel(); res.Body.Close() }
+	}
+	next := func(rd *bufio.Reader) sseEvent {
+		var ev sseEvent
+		for {
+			line, err := rd.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case line == "" && ev.Type != "":
+				return ev
+			case strings.HasPrefix(line, "id: "):
+				ev.ID = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				ev.Type = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				ev.Data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}
+	publish := func(ev realtime.Event) {
+		require.NoError(t, broker.Publish(context.Background(), ev))
+	}
+
+	stream, closeStream := open("")
+	require.Eventually(t, func() bool { return broker.Connections() == 1 }, time.Second, 10*time.Millisecond)
+	unread, err := realtime.NewEvent(realtime.EventUnreadUpdated, 4, []uint64{1}, gin.H{"conversation_id": 4, "unread_count": 2, "total_unread": 5})
+	require.NoError(t, err)
+	publish(unread)
+	first := next(stream)
+	assert.Equal(t, realtime.EventUnreadUpdated, first.Type)
+	assert.NotEmpty(t, first.ID)
+	assert.Contains(t, first.Data, `"total_unread":5`)
+	closeStream()
+	require.Eventually(t, func() bool { return broker.Connections() == 0 }, time.Second, 10*time.Millisecond)
+
+	// Events published while disconnected are replayed on reconnection, in order and only for this user
+	publish(realtime.Event{Type: realtime.EventUnreadUpdated, Recipients: []uint64{2}})
+	deadline := time.Now().Add(48 * time.Hour)
+	require.NoError(t, inapp.Notify(context.Background(), broker, inapp.Notification{Kind: inapp.KindOpportunityDeadline, Title: "AI Grant", At: &deadline}))
+	publish(realtime.Event{Type: realtime.EventConversationCreated, ConversationID: 9, Recipients: []uint64{1}})
+
+	stream, closeStream = open(first.ID)
+	ev := next(stream)
+	assert.Equal(t, realtime.EventNotification, ev.Type)
+	assert.Contains(t, ev.Data, `"kind":"opportunity_deadline"`)
+	last := next(stream)
+	assert.Equal(t, realtime.EventConversationCreated, last.Type)
+	closeStream()
+
+	// An unknown or expired ID asks the client to reload its state
+	stream, closeStream = open("gone")
+	defer closeStream()
+	assert.Equal(t, "resync", next(stream).Type)
+
+	res, err := http.Get(srv.URL + "/notifications/stream")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
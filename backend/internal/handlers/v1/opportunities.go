@@ -104,6 +104,15 @@ func (h *OpportunityHandler) GetOpportunities(c *gin.Context) {
 		return
 	}
 
+	ids := make([]uint64, len(opportunities))
+	for i, o := range opportunities {
+		ids[i] = uint64(o.ID)
+	}
+	favorited := favoritedIDs(c, h.db, h.log, models.FavoriteTargetOpportunity, ids)
+	for i := range opportunities {
+		opportunities[i].Favorited = favorited[uint64(opportunities[i].ID)]
+	}
+
 	totalPages := (int(total) + params.pagination.PerPage - 1) / params.pagination.PerPage
 	response.JSON(c, http.StatusOK, gin.H{
 		"data": opportunities,
@@ -147,6 +156,8 @@ func (h *OpportunityHandler) GetOpportunity(c *gin.Context) {
 		return
 	}
 
+	opportunity.Favorited = favoritedIDs(c, h.db, h.log, models.FavoriteTargetOpportunity, []uint64{uint64(opportunity.ID)})[uint64(opportunity.ID)]
+
 	response.JSON(c, http.StatusOK, gin.H{
 		"data": opportunity,
 	})
@@ -337,6 +348,8 @@ func (h *OpportunityHandler) UpdateOpportunity(c *gin.Context) {
 	}
 	if req.Deadline != nil {
 		updates["deadline"] = *req.Deadline
+		// A postponed deadline is announced again
+		updates["deadline_reminded_at"] = nil
 	}
 
 	if len(updates) == 0 {
@@ -0,0 +1,363 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// adminRoleName is the built-in role whose permissions cannot be edited, so that the back office cannot be locked out
+const adminRoleName = "admin"
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+type RolesHandler struct {
+	log *logrus.Logger
+	db  *gorm.DB
+}
+
+func NewRolesHandler(log *logrus.Logger, db *gorm.DB) *RolesHandler {
+	return &RolesHandler{
+		log: log,
+		db:  db,
+	}
+}
+
+// ListPermissions godoc
+// @Summary      List permissions
+// @Description  Returns every permission that can be granted to a role.
+// @Tags         Roles
+// @Security     CookieAuth
+// @Produce      json
+// @Success      200 {object} response.PermissionListResponse
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/permissions [get]
+func (h *RolesHandler) ListPermissions(c *gin.Context) {
+	var perms []models.Permission
+	if err := h.db.Order("name ASC").Find(&perms).Error; err != nil {
+		h.log.WithError(err).Error("failed to list permissions")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to list permissions", nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"data": perms})
+}
+
+// ListRoles godoc
+// @Summary      List roles
+// @Description  Returns every role with its granted permissions.
+// @Tags         Roles
+// @Security     CookieAuth
+// @Produce      json
+// @Success      200 {object} response.RoleListResponse
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/roles [get]
+func (h *RolesHandler) ListRoles(c *gin.Context) {
+	var roles []models.Role
+	if err := h.db.Preload("Permissions").Order("name ASC").Find(&roles).Error; err != nil {
+		h.log.WithError(err).Error("failed to list roles")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to list roles", nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"data": roles})
+}
+
+// GetRole godoc
+// @Summary      Get role
+// @Description  Returns a role with its granted permissions.
+// @Tags         Roles
+// @Security     CookieAuth
+// @Produce      json
+// @Param        id path int true "Role ID"
+// @Success      200 {object} response.RoleObjectResponse
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/roles/{id} [get]
+func (h *RolesHandler) GetRole(c *gin.Context) {
+	role, ok := h.loadRole(c)
+	if !ok {
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"data": role})
+}
+
+// CreateRole godoc
+// @Summary      Create role
+// @Description  Creates a custom role and grants it the given permissions.
+// @Tags         Roles
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        payload body requests.RoleCreateRequest true "Role" Example({"name":"moderator","permissions":["news.update","news.delete"]})
+// @Success      201 {object} response.RoleObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/roles [post]
+func (h *RolesHandler) CreateRole(c *gin.Context) {
+	var req struct {
+		Name        string   `json:"name" binding:"required,max=50"`
+		Description *string  `json:"description,omitempty"`
+		Permissions []string `json:"permissions"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if !roleNamePattern.MatchString(name) {
+		response.JSONError(c, http.StatusBadRequest, "invalid_role_name",
+			"role name must be lowercase letters, digits, '_' or '-'", nil)
+		return
+	}
+
+	var count int64
+	if err := h.db.Model(&models.Role{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		h.log.WithError(err).Error("failed to check role name")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to create role", nil)
+		return
+	}
+	if count > 0 {
+		response.JSONError(c, http.StatusConflict, "role_exists", "a role with this name already exists", nil)
+		return
+	}
+
+	perms, ok := h.resolvePermissions(c, req.Permissions)
+	if !ok {
+		return
+	}
+
+	role := models.Role{Name: name, Description: req.Description, Permissions: perms}
+	if err := h.db.Create(&role).Error; err != nil {
+		h.log.WithError(err).Error("failed to create role")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to create role", nil)
+		return
+	}
+
+	h.log.WithField("role", role.Name).Info("role created successfully")
+	response.JSON(c, http.StatusCreated, gin.H{"message": "role created successfully", "data": role})
+}
+
+// UpdateRole godoc
+// @Summary      Update role
+// @Description  Updates a role. Built-in roles cannot be renamed and the admin role's permissions are fixed. Renaming a role also renames it on its users.
+// @Tags         Roles
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        id      path int                        true "Role ID"
+// @Param        payload body requests.RoleUpdateRequest true "Fields to update" Example({"permissions":["news.create","news.update"]})
+// @Success      200 {object} response.RoleObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/roles/{id} [patch]
+func (h *RolesHandler) UpdateRole(c *gin.Context) {
+	role, ok := h.loadRole(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name        *string   `json:"name,omitempty" binding:"omitempty,max=50"`
+		Description *string   `json:"description,omitempty"`
+		Permissions *[]string `json:"permissions,omitempty"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+	if req.Name == nil && req.Description == nil && req.Permissions == nil {
+		response.JSONError(c, http.StatusBadRequest, "no_fields", "no fields provided for update", nil)
+		return
+	}
+
+	oldName := role.Name
+	newName := oldName
+	if req.Name != nil {
+		newName = strings.ToLower(strings.TrimSpace(*req.Name))
+		if newName != oldName {
+			if role.IsSystem {
+				response.JSONError(c, http.StatusConflict, "system_role", "built-in roles cannot be renamed", nil)
+				return
+			}
+			if !roleNamePattern.MatchString(newName) {
+				response.JSONError(c, http.StatusBadRequest, "invalid_role_name",
+					"role name must be lowercase letters, digits, '_' or '-'", nil)
+				return
+			}
+			var count int64
+			if err := h.db.Model(&models.Role{}).Where("name = ?", newName).Count(&count).Error; err != nil {
+				h.log.WithError(err).Error("failed to check role name")
+				response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to update role", nil)
+				return
+			}
+			if count > 0 {
+				response.JSONError(c, http.StatusConflict, "role_exists", "a role with this name already exists", nil)
+				return
+			}
+		}
+	}
+
+	var perms []models.Permission
+	if req.Permissions != nil {
+		if role.Name == adminRoleName {
+			response.JSONError(c, http.StatusConflict, "system_role", "the admin role's permissions cannot be changed", nil)
+			return
+		}
+		if perms, ok = h.resolvePermissions(c, *req.Permissions); !ok {
+			return
+		}
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"name": newName}
+		if req.Description != nil {
+			updates["description"] = *req.Description
+		}
+		if err := tx.Model(role).Updates(updates).Error; err != nil {
+			return err
+		}
+		if newName != oldName {
+			if err := tx.Model(&models.User{}).Where("role = ?", oldName).Update("role", newName).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Invitation{}).Where("role = ?", oldName).Update("role", newName).Error; err != nil {
+				return err
+			}
+		}
+		if req.Permissions != nil {
+			if err := tx.Model(role).Association("Permissions").Replace(perms); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.log.WithError(err).WithField("role", oldName).Error("failed to update role")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to update role", nil)
+		return
+	}
+
+	var updated models.Role
+	if err := h.db.Preload("Permissions").First(&updated, role.ID).Error; err != nil {
+		h.log.WithError(err).Error("failed to fetch updated role")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve updated role", nil)
+		return
+	}
+
+	h.log.WithField("role", updated.Name).Info("role updated successfully")
+	response.JSON(c, http.StatusOK, gin.H{"message": "role updated successfully", "data": updated})
+}
+
+// DeleteRole godoc
+// @Summary      Delete role
+// @Description  Deletes a custom role. Built-in roles and roles still assigned to users cannot be deleted.
+// @Tags         Roles
+// @Security     CookieAuth
+// @Param        id path int true "Role ID"
+// @Success      200 {object} response.MessageResponse
+// @Failure      401 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      409 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /admin/roles/{id} [delete]
+func (h *RolesHandler) DeleteRole(c *gin.Context) {
+	role, ok := h.loadRole(c)
+	if !ok {
+		return
+	}
+	if role.IsSystem {
+		response.JSONError(c, http.StatusConflict, "system_role", "built-in roles cannot be deleted", nil)
+		return
+	}
+
+	var users int64
+	if err := h.db.Model(&models.User{}).Where("role = ?", role.Name).Count(&users).Error; err != nil {
+		h.log.WithError(err).Error("failed to count role users")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to delete role", nil)
+		return
+	}
+	if users > 0 {
+		response.JSONError(c, http.StatusConflict, "role_in_use", "role is still assigned to users", gin.H{"users": users})
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(role).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(role).Error
+	})
+	if err != nil {
+		h.log.WithError(err).WithField("role", role.Name).Error("failed to delete role")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to delete role", nil)
+		return
+	}
+
+	h.log.WithField("role", role.Name).Info("role deleted successfully")
+	response.JSON(c, http.StatusOK, gin.H{"message": "role deleted successfully"})
+}
+
+func (h *RolesHandler) loadRole(c *gin.Context) (*models.Role, bool) {
+	id := c.Param("id")
+	var role models.Role
+	if err := h.db.Preload("Permissions").Where("id = ?", id).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusNotFound, "not_found", "role not found", nil)
+			return nil, false
+		}
+		h.log.WithError(err).WithField("id", id).Error("failed to fetch role")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve role", nil)
+		return nil, false
+	}
+	return &role, true
+}
+
+// resolvePermissions maps permission names to rows and rejects unknown names
+func (h *RolesHandler) resolvePermissions(c *gin.Context, names []string) ([]models.Permission, bool) {
+	perms := make([]models.Permission, 0, len(names))
+	if len(names) == 0 {
+		return perms, true
+	}
+	if err := h.db.Where("name IN ?", names).Find(&perms).Error; err != nil {
+		h.log.WithError(err).Error("failed to resolve permissions")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to resolve permissions", nil)
+		return nil, false
+	}
+
+	known := make(map[string]struct{}, len(perms))
+	for _, p := range perms {
+		known[p.Name] = struct{}{}
+	}
+	var unknown []string
+	for _, n := range names {
+		if _, ok := known[n]; !ok {
+			unknown = append(unknown, n)
+		}
+	}
+	if len(unknown) > 0 {
+		response.JSONError(c, http.StatusBadRequest, "unknown_permission", "unknown permissions", gin.H{"permissions": unknown})
+		return nil, false
+	}
+	return perms, true
+}
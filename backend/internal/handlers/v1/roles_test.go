@@ -0,0 +1,112 @@
+package v1_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// seedRoles mirrors the roles/permissions migration: the permission catalog and the built-in roles
+func seedRoles(t *testing.T, db *gorm.DB) {
+	require.NoError(t, db.AutoMigrate(&models.Role{}, &models.Permission{}))
+	for _, p := range auth.Catalog {
+		desc := p.Description
+		require.NoError(t, db.Create(&models.Permission{Name: p.Name, Description: &desc}).Error)
+	}
+	var all models.Permission
+	require.NoError(t, db.Where("name = ?", auth.PermAll).First(&all).Error)
+	require.NoError(t, db.Create(&models.Role{Name: "admin", IsSystem: true, Permissions: []models.Permission{all}}).Error)
+	for _, name := range []string{"founder", "investor", "user"} {
+		require.NoError(t, db.Create(&models.Role{Name: name, IsSystem: true}).Error)
+	}
+}
+
+func setupRolesRouter(h *v1.RolesHandler) *gin.Engine {
+	r := gin.Default()
+	r.GET("/admin/permissions", h.ListPermissions)
+	r.GET("/admin/roles", h.ListRoles)
+	r.GET("/admin/roles/:id", h.GetRole)
+	r.POST("/admin/roles", h.CreateRole)
+	r.PATCH("/admin/roles/:id", h.UpdateRole)
+	r.DELETE("/admin/roles/:id", h.DeleteRole)
+	return r
+}
+
+func doJSON(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRolesHandler_FullCoverage(t *testing.T) {
+	db := setupUsersDB(t)
+	seedRoles(t, db)
+	require.NoError(t, db.AutoMigrate(&models.Invitation{}))
+	r := setupRolesRouter(v1.NewRolesHandler(logrus.New(), db))
+
+	w := doJSON(r, http.MethodGet, "/admin/permissions", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), auth.PermSyncTrigger)
+
+	assert.Equal(t, http.StatusBadRequest, doJSON(r, http.MethodPost, "/admin/roles", `{}`).Code)
+	assert.Equal(t, http.StatusBadRequest, doJSON(r, http.MethodPost, "/admin/roles", `{"name":"Bad Name!"}`).Code)
+	assert.Equal(t, http.StatusConflict, doJSON(r, http.MethodPost, "/admin/roles", `{"name":"admin"}`).Code)
+
+	w = doJSON(r, http.MethodPost, "/admin/roles", `{"name":"moderator","permissions":["news.update","nope.read"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "nope.read")
+
+	w = doJSON(r, http.MethodPost, "/admin/roles", `{"name":"Moderator","permissions":["news.update","news.delete"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"moderator"`)
+
+	var moderator models.Role
+	require.NoError(t, db.Where("name = ?", "moderator").First(&moderator).Error)
+	ok, err := auth.RoleHasPermission(t.Context(), db, "moderator", auth.PermNewsDelete)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.Equal(t, http.StatusOK, doJSON(r, http.MethodGet, "/admin/roles", "").Code)
+	assert.Equal(t, http.StatusNotFound, doJSON(r, http.MethodGet, "/admin/roles/999", "").Code)
+
+	// Renaming carries the role's users along, and permissions are replaced
+	db.Create(&models.User{Email: "mod@corp.tld", Name: "Mod", Role: "moderator"})
+	path := fmt.Sprintf("/admin/roles/%d", moderator.ID)
+	assert.Equal(t, http.StatusBadRequest, doJSON(r, http.MethodPatch, path, `{}`).Code)
+	w = doJSON(r, http.MethodPatch, path, `{"name":"content_editor","permissions":["news.*"]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var u models.User
+	require.NoError(t, db.Where("email = ?", "mod@corp.tld").First(&u).Error)
+	assert.Equal(t, "content_editor", u.Role)
+	ok, _ = auth.RoleHasPermission(t.Context(), db, "content_editor", auth.PermNewsCreate)
+	assert.True(t, ok)
+	ok, _ = auth.RoleHasPermission(t.Context(), db, "content_editor", auth.PermEventsCreate)
+	assert.False(t, ok)
+
+	// Built-in roles are protected
+	var admin models.Role
+	require.NoError(t, db.Where("name = ?", "admin").First(&admin).Error)
+	adminPath := fmt.Sprintf("/admin/roles/%d", admin.ID)
+	assert.Equal(t, http.StatusConflict, doJSON(r, http.MethodPatch, adminPath, `{"name":"root"}`).Code)
+	assert.Equal(t, http.StatusConflict, doJSON(r, http.MethodPatch, adminPath, `{"permissions":[]}`).Code)
+	assert.Equal(t, http.StatusConflict, doJSON(r, http.MethodDelete, adminPath, "").Code)
+
+	// A role still assigned to users cannot be deleted
+	assert.Equal(t, http.StatusConflict, doJSON(r, http.MethodDelete, path, "").Code)
+	db.Model(&u).Update("role", "user")
+	assert.Equal(t, http.StatusOK, doJSON(r, http.MethodDelete, path, "").Code)
+	assert.Equal(t, http.StatusNotFound, doJSON(r, http.MethodDelete, path, "").Code)
+}
@@ -0,0 +1,200 @@
+package v1
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/features"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/http/pagination"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/search"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SearchHandler struct {
+	db    *gorm.DB
+	log   *logrus.Logger
+	flags *features.Store
+}
+
+func NewSearchHandler(db *gorm.DB, log *logrus.Logger, flags *features.Store) *SearchHandler {
+	return &SearchHandler{
+		db:    db,
+		log:   log,
+		flags: flags,
+	}
+}
+
+// Search godoc
+// @Summary      Search everything
+// @Description  Full-text search over startups, investors, partners, news, events and opportunities at once. q accepts web-search syntax: "quoted phrases", OR, and -excluded words, and names also match despite typos.
+// @Description  Results are grouped by type, the group with the best match first, and each group is paginated on its own: page and per_page apply to every group, so a "more results" link for one type sets types to that type and moves to the next page.
+// @Description  Snippets are HTML-escaped with matches wrapped in <mark>. Opportunities are left out while the opportunities feature is disabled.
+// @Tags         Search
+// @Produce      json
+// @Param        q        query string true  "Search terms" minlength(2) maxlength(200)
+// @Param        types    query string false "Comma-separated kinds of entity, all by default" example(startup,event)
+// @Param        page     query int    false "Page of every group" default(1)
+// @Param        per_page query int    false "Page size of every group" default(20)
+// @Success      200 {object} response.SearchResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if !search.ValidQuery(q) {
+		response.JSONError(c, http.StatusBadRequest, "invalid_query", "q must be between 2 and 200 characters", nil)
+		return
+	}
+	allowed := search.Types
+	if h.flags != nil && !h.flags.Enabled(c.Request.Context(), features.Opportunities) {
+		allowed = slices.DeleteFunc(slices.Clone(allowed), func(t string) bool { return t == search.TypeOpportunity })
+	}
+	types, err := search.ParseTypes(c.Query("types"), allowed)
+	if err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_params", err.Error(), nil)
+		return
+	}
+	params := pagination.Parse(c)
+
+	ctx := c.Request.Context()
+	counts, err := search.Counts(ctx, h.db, q, types)
+	if err != nil {
+		h.log.WithError(err).Error("failed to count search results")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to search", nil)
+		return
+	}
+	hits, err := search.Documents(ctx, h.db, q, types, params.Page, params.PerPage)
+	if err != nil {
+		h.log.WithError(err).Error("failed to search")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to search", nil)
+		return
+	}
+	items, err := h.loadItems(c, hits)
+	if err != nil {
+		h.log.WithError(err).Error("failed to load search results")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to search", nil)
+		return
+	}
+
+	groups := make([]response.SearchGroup, len(types))
+	for i, t := range types {
+		total := int(counts[t])
+		totalPages := (total + params.PerPage - 1) / params.PerPage
+		groups[i] = response.SearchGroup{
+			Type: t,
+			Data: make([]response.SearchHit, 0),
+			Pagination: response.PageMeta{
+				Page:    params.Page,
+				PerPage: params.PerPage,
+				Total:   total,
+				HasNext: params.Page < totalPages,
+				HasPrev: params.Page > 1,
+			},
+		}
+	}
+	// Hits come best first, so each group is in rank order
+	for _, hit := range hits {
+		item, ok := items[hit.EntityType][hit.EntityID]
+		if !ok {
+			continue
+		}
+		i := slices.Index(types, hit.EntityType)
+		groups[i].Data = append(groups[i].Data, response.SearchHit{
+			Type:    hit.EntityType,
+			ID:      hit.EntityID,
+			Title:   hit.Title,
+			Snippet: search.Highlight(hit.Snippet),
+			Rank:    hit.Rank,
+			Item:    item,
+		})
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return bestRank(groups[i]) > bestRank(groups[j])
+	})
+
+	response.JSON(c, http.StatusOK, gin.H{"data": groups})
+}
+
+// bestRank is the rank of the best hit of group, or -1 when it is empty so that empty groups come last
+func bestRank(group response.SearchGroup) float64 {
+	if len(group.Data) == 0 {
+		return -1
+	}
+	return group.Data[0].Rank
+}
+
+// loadItems fetches the entities behind hits with one query per type
+func (h *SearchHandler) loadItems(c *gin.Context, hits []search.Hit) (map[string]map[uint64]interface{}, error) {
+	ids := make(map[string][]uint64)
+	for _, hit := range hits {
+		ids[hit.EntityType] = append(ids[hit.EntityType], hit.EntityID)
+	}
+	items := make(map[string]map[uint64]interface{}, len(ids))
+	for t, typeIDs := range ids {
+		items[t] = make(map[uint64]interface{}, len(typeIDs))
+		switch t {
+		case search.TypeStartup:
+			var startups []models.Startup
+			if err := h.db.Where("id IN ?", typeIDs).Find(&startups).Error; err != nil {
+				return nil, err
+			}
+			favorited := favoritedIDs(c, h.db, h.log, models.FavoriteTargetStartup, typeIDs)
+			for i := range startups {
+				startups[i].Favorited = favorited[startups[i].ID]
+				items[t][startups[i].ID] = startups[i]
+			}
+		case search.TypeInvestor:
+			var investors []models.Investor
+			if err := h.db.Where("id IN ?", typeIDs).Find(&investors).Error; err != nil {
+				return nil, err
+			}
+			for _, investor := range investors {
+				items[t][investor.ID] = investor
+			}
+		case search.TypePartner:
+			var partners []models.Partner
+			if err := h.db.Where("id IN ?", typeIDs).Find(&partners).Error; err != nil {
+				return nil, err
+			}
+			for _, partner := range partners {
+				items[t][partner.ID] = partner
+			}
+		case search.TypeNews:
+			var news []models.News
+			if err := h.db.Where("id IN ?", typeIDs).Find(&news).Error; err != nil {
+				return nil, err
+			}
+			for _, n := range news {
+				items[t][n.ID] = n
+			}
+		case search.TypeEvent:
+			var events []models.Event
+			if err := h.db.Where("id IN ?", typeIDs).Find(&events).Error; err != nil {
+				return nil, err
+			}
+			favorited := favoritedIDs(c, h.db, h.log, models.FavoriteTargetEvent, typeIDs)
+			for i := range events {
+				events[i].Favorited = favorited[events[i].ID]
+				items[t][events[i].ID] = events[i]
+			}
+		case search.TypeOpportunity:
+			var opportunities []models.Opportunity
+			if err := h.db.Where("id IN ?", typeIDs).Find(&opportunities).Error; err != nil {
+				return nil, err
+			}
+			favorited := favoritedIDs(c, h.db, h.log, models.FavoriteTargetOpportunity, typeIDs)
+			for i := range opportunities {
+				id := uint64(opportunities[i].ID)
+				opportunities[i].Favorited = favorited[id]
+				items[t][id] = opportunities[i]
+			}
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,36 @@
+package v1_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/features"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// The search index is maintained by Postgres triggers and queried with
+// full-text search and pg_trgm, so only the validation is exercised here
+func TestSearchHandler_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupUsersDB(t)
+	flags := features.New(config.FeaturesConfig{EnableOpportunities: false}, nil)
+	h := v1.NewSearchHandler(db, logrus.New(), flags)
+	r := gin.New()
+	r.GET("/search", h.Search)
+
+	for _, query := range []string{
+		"",
+		"?q=%20a%20",
+		"?q=acme&types=startup,users",
+		"?q=grant&types=opportunity",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/search"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
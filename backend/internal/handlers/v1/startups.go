@@ -45,7 +45,7 @@ func NewStartupsHandler(db *gorm.DB, log *logrus.Logger) *StartupsHandler {
 // @Param        sector         query string false "Sector filter" Enums(tech,health,finance)
 // @Param        maturity       query string false "Maturity filter" Enums(early,middle,late)
 // @Param        project_status query string false "Project status" Enums(ongoing,completed)
-// @Param        founder        query string false "Founder filter"
+// @Param        founder        query string false "Founder name, partial match"
 // @Param        created_at     query string false "CreatedAt filter"
 // @Success      200 {object} response.StartupListResponse
 // @Failure      400 {object} response.ErrorBody
@@ -73,7 +73,7 @@ func (h *StartupsHandler) ListStartups(ctx *gin.Context) {
 		query = query.Where("project_status = ?", params.ProjectStatus)
 	}
 	if params.Founder != "" {
-		query = query.Where("founder = ?", params.Founder)
+		query = whereFounder(query, params.Founder)
 	}
 	if params.CreatedAt != "" {
 		query = query.Where("created_at = ?", params.CreatedAt)
@@ -91,6 +91,15 @@ func (h *StartupsHandler) ListStartups(ctx *gin.Context) {
 		return
 	}
 
+	ids := make([]uint64, len(result))
+	for i, s := range result {
+		ids[i] = s.ID
+	}
+	favorited := favoritedIDs(ctx, h.db, h.log, models.FavoriteTargetStartup, ids)
+	for i := range result {
+		result[i].Favorited = favorited[result[i].ID]
+	}
+
 	var total int64
 	if err := h.db.Model(&models.Startup{}).Count(&total).Error; err != nil {
 		h.log.WithError(err).Errorf("h.db.Model().Count().Error")
@@ -143,6 +152,8 @@ func (h *StartupsHandler) GetStartup(ctx *gin.Context) {
 		return
 	}
 
+	result.Favorited = favoritedIDs(ctx, h.db, h.log, models.FavoriteTargetStartup, []uint64{result.ID})[result.ID]
+
 	ctx.JSON(http.StatusOK, gin.H{
 		"data": result,
 	})
@@ -0,0 +1,188 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/http/pagination"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/search"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type searchStartupsParams struct {
+	Q             string `form:"q"`
+	Sector        string `form:"sector" binding:"omitempty,oneof=tech health finance"`
+	Maturity      string `form:"maturity" binding:"omitempty,oneof=early middle late"`
+	ProjectStatus string `form:"project_status" binding:"omitempty,oneof=ongoing completed"`
+	Founder       string `form:"founder" binding:"omitempty"`
+}
+
+// startupFacets lists the columns the search results are counted by
+var startupFacets = []string{"sector", "maturity", "project_status"}
+
+// startupHit is a row of the startup search query
+type startupHit struct {
+	ID      uint64
+	Rank    float64
+	Snippet string
+}
+
+// SearchStartups godoc
+// @Summary      Search startups
+// @Description  Full-text search over the name, description and needs of the startups, best matches first. q accepts web-search syntax: "quoted phrases", OR, and -excluded words, and names also match despite typos.
+// @Description  Without q, every startup matching the filters is returned, most recent first.
+// @Description  Facets count the matches per sector, maturity and project status; each facet ignores its own filter so that the alternatives stay visible.
+// @Tags         Startups
+// @Produce      json
+// @Param        q              query string false "Search terms" minlength(2) maxlength(200)
+// @Param        sector         query string false "Sector filter" Enums(tech,health,finance)
+// @Param        maturity       query string false "Maturity filter" Enums(early,middle,late)
+// @Param        project_status query string false "Project status" Enums(ongoing,completed)
+// @Param        founder        query string false "Founder name, partial match"
+// @Param        page           query int    false "Page" default(1)
+// @Param        per_page       query int    false "Page size" default(20)
+// @Success      200 {object} response.StartupSearchResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /startups/search [get]
+func (h *StartupsHandler) SearchStartups(c *gin.Context) {
+	var params searchStartupsParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_params", err.Error(), nil)
+		return
+	}
+	q := strings.TrimSpace(params.Q)
+	if q != "" && !search.ValidQuery(q) {
+		response.JSONError(c, http.StatusBadRequest, "invalid_query", "q must be between 2 and 200 characters", nil)
+		return
+	}
+	page := pagination.Parse(c)
+	filters := map[string]string{
+		"sector":         params.Sector,
+		"maturity":       params.Maturity,
+		"project_status": params.ProjectStatus,
+	}
+
+	// matching returns the startups matching the search, leaving out the filter on the skip facet
+	matching := func(skip string) *gorm.DB {
+		query := h.db.Table("startups")
+		if q != "" {
+			query = query.Joins("CROSS JOIN websearch_to_tsquery('simple', ?) query", q).
+				Where("(startups.search_vector @@ query OR startups.name % ?)", q)
+		}
+		for _, facet := range startupFacets {
+			if facet != skip && filters[facet] != "" {
+				query = query.Where("startups."+facet+" = ?", filters[facet])
+			}
+		}
+		if params.Founder != "" {
+			query = whereFounder(query, params.Founder)
+		}
+		return query
+	}
+
+	var total int64
+	if err := matching("").Count(&total).Error; err != nil {
+		h.log.WithError(err).Error("failed to count startup search results")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to search startups", nil)
+		return
+	}
+	var hits []startupHit
+	query := matching("")
+	if q != "" {
+		query = query.
+			Select("startups.id, ts_rank(startups.search_vector, query) + similarity(startups.name, ?) AS rank, ts_headline('simple', coalesce(startups.description, startups.name), query, ?) AS snippet", q, search.HeadlineOptions).
+			Order("rank DESC, startups.id DESC")
+	} else {
+		query = query.Select("startups.id").Order("startups.created_at DESC, startups.id DESC")
+	}
+	if err := query.
+		Offset((page.Page - 1) * page.PerPage).
+		Limit(page.PerPage).
+		Scan(&hits).Error; err != nil {
+		h.log.WithError(err).Error("failed to search startups")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to search startups", nil)
+		return
+	}
+
+	facets := make(map[string]map[string]int64, len(startupFacets))
+	for _, facet := range startupFacets {
+		var rows []struct {
+			Value string
+			Count int64
+		}
+		if err := matching(facet).
+			Select("startups." + facet + " AS value, COUNT(*) AS count").
+			Where("startups." + facet + " IS NOT NULL").
+			Group("startups." + facet).
+			Scan(&rows).Error; err != nil {
+			h.log.WithError(err).Errorf("failed to count startups per %s", facet)
+			response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to search startups", nil)
+			return
+		}
+		facets[facet] = make(map[string]int64, len(rows))
+		for _, row := range rows {
+			facets[facet][row.Value] = row.Count
+		}
+	}
+
+	ids := make([]uint64, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	startups := make(map[uint64]models.Startup, len(hits))
+	if len(hits) > 0 {
+		var ss []models.Startup
+		if err := h.db.Where("id IN ?", ids).Find(&ss).Error; err != nil {
+			h.log.WithError(err).Error("failed to load searched startups")
+			response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to search startups", nil)
+			return
+		}
+		for _, s := range ss {
+			startups[s.ID] = s
+		}
+	}
+	favorited := favoritedIDs(c, h.db, h.log, models.FavoriteTargetStartup, ids)
+
+	results := make([]response.StartupSearchHit, 0, len(hits))
+	for _, hit := range hits {
+		startup := startups[hit.ID]
+		startup.Favorited = favorited[hit.ID]
+		results = append(results, response.StartupSearchHit{
+			Startup: startup,
+			Snippet: search.Highlight(hit.Snippet),
+			Rank:    hit.Rank,
+		})
+	}
+
+	totalPages := (int(total) + page.PerPage - 1) / page.PerPage
+	response.JSON(c, http.StatusOK, gin.H{
+		"data": results,
+		"facets": gin.H{
+			"sector":         facets["sector"],
+			"maturity":       facets["maturity"],
+			"project_status": facets["project_status"],
+		},
+		"pagination": gin.H{
+			"page":     page.Page,
+			"per_page": page.PerPage,
+			"total":    total,
+			"has_next": page.Page < totalPages,
+			"has_prev": page.Page > 1,
+		},
+	})
+}
+
+// whereFounder keeps the startups with a founder whose name contains name, either in the synced founders
+// data or among the accounts linked to the startup
+func whereFounder(query *gorm.DB, name string) *gorm.DB {
+	pattern := "%" + likeEscaper.Replace(name) + "%"
+	return query.Where(`(EXISTS (SELECT 1 FROM jsonb_array_elements(startups.founders) f WHERE f->>'name' ILIKE ? ESCAPE '\')
+		OR EXISTS (SELECT 1 FROM founders fo JOIN users u ON u.id = fo.user_id WHERE fo.startup_id = startups.id AND u.name ILIKE ? ESCAPE '\'))`, pattern, pattern)
+}
+
+// likeEscaper escapes the LIKE wildcards so that user input only matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
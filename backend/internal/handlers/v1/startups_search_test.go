@@ -0,0 +1,35 @@
+package v1_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// The matching, facets and founder filter rely on Postgres full-text search,
+// pg_trgm and JSONB, so only the validation is exercised here
+func TestStartupsHandler_SearchStartupsValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupUsersDB(t)
+	_ = db.AutoMigrate(&models.Startup{})
+	h := v1.NewStartupsHandler(db, logrus.New())
+	r := gin.New()
+	r.GET("/startups/search", h.SearchStartups)
+
+	for _, query := range []string{
+		"?q=%20a%20",
+		"?q=acme&sector=space",
+		"?maturity=seed",
+		"?project_status=paused",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/startups/search"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
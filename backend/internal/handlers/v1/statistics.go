@@ -26,7 +26,7 @@ func NewStatisticsHandler(db *gorm.DB, log *logrus.Logger) *StatisticsHandler {
 
 // GetStatistics godoc
 // @Summary      Get general statistics
-// @Description  Retrieves various statistics about projects and engagement.
+// @Description  Retrieves various statistics about projects and engagement, including how many startups, events and opportunities users saved.
 // @Tags         Admin/Statistics
 // @Security     CookieAuth
 // @Param        period  query string false "Time period for growth calculation" Enums(weekly,monthly) default(weekly)
@@ -65,6 +65,31 @@ func (h *StatisticsHandler) GetStatistics(c *gin.Context) {
 		h.log.WithError(err).Error("failed to count startups growth")
 	}
 
+	type favoriteCount struct {
+		TargetType string
+		Total      int64
+		Recent     int64
+	}
+	var favoriteCounts []favoriteCount
+	if err := h.db.Model(&models.Favorite{}).
+		Select("target_type, COUNT(*) AS total, SUM(CASE WHEN created_at >= ? THEN 1 ELSE 0 END) AS recent", since).
+		Group("target_type").
+		Scan(&favoriteCounts).Error; err != nil {
+		// Favorites are reported as zero rather than failing the whole dashboard
+		h.log.WithError(err).Error("failed to count favorites")
+		favoriteCounts = nil
+	}
+	var totalFavorites, favoritesGrowth int64
+	favoritesByType := make(map[string]int64, len(models.FavoriteTargets))
+	for _, t := range models.FavoriteTargets {
+		favoritesByType[t] = 0
+	}
+	for _, fc := range favoriteCounts {
+		favoritesByType[fc.TargetType] = fc.Total
+		totalFavorites += fc.Total
+		favoritesGrowth += fc.Recent
+	}
+
 	avgViewsPerProject := float64(0)
 	if totalProjects > 0 {
 		avgViewsPerProject = float64(totalViews) / float64(totalProjects)
@@ -81,6 +106,9 @@ func (h *StatisticsHandler) GetStatistics(c *gin.Context) {
 		"total_views":             totalViews,
 		"views_growth_percent":    avgViewsPerProject,
 		"engagement_rate_percent": engagementRate,
+		"total_favorites":         totalFavorites,
+		"favorites_growth":        favoritesGrowth,
+		"favorites_by_type":       favoritesByType,
 		"period":                  period,
 	}
 	response.JSON(c, http.StatusOK, stats)
@@ -88,7 +116,7 @@ func (h *StatisticsHandler) GetStatistics(c *gin.Context) {
 
 // GetTopProjects godoc
 // @Summary      Get top projects
-// @Description  Returns a list of top projects based on views count for a given period.
+// @Description  Returns a list of top projects based on views count for a given period. Likes are the number of users who saved the project.
 // @Tags         Admin/Statistics
 // @Security     CookieAuth
 // @Param        limit   query int    false "Number of top projects to return" default(10)
@@ -115,13 +143,37 @@ func (h *StatisticsHandler) GetTopProjects(c *gin.Context) {
 		return
 	}
 
+	ids := make([]uint64, len(startups))
+	for i, s := range startups {
+		ids[i] = s.ID
+	}
+	var favoriteCounts []struct {
+		TargetID uint64
+		Total    int64
+	}
+	if len(ids) > 0 {
+		if err := h.db.Model(&models.Favorite{}).
+			Select("target_id, COUNT(*) AS total").
+			Where("target_type = ? AND target_id IN ?", models.FavoriteTargetStartup, ids).
+			Group("target_id").
+			Scan(&favoriteCounts).Error; err != nil {
+			// Likes are reported as zero rather than failing the ranking
+			h.log.WithError(err).Error("failed to count favorites for top projects")
+			favoriteCounts = nil
+		}
+	}
+	likes := make(map[uint64]int64, len(favoriteCounts))
+	for _, fc := range favoriteCounts {
+		likes[fc.TargetID] = fc.Total
+	}
+
 	var topProjects []gin.H
 	for _, s := range startups {
 		topProjects = append(topProjects, gin.H{
 			"project_id":              s.ID,
 			"title":                   s.Name,
 			"views":                   s.ViewsCount,
-			"likes":                   0,
+			"likes":                   likes[s.ID],
 			"comments":                0,
 			"engagement_rate_percent": float64(s.ViewsCount) / float64(1+s.ViewsCount) * 100,
 		})
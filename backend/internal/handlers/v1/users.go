@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"slices"
 
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/password"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/http/pagination"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
@@ -16,14 +18,14 @@ import (
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/storage/s3"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type UsersHandler struct {
-	db       *gorm.DB
-	log      *logrus.Logger
-	uploader s3.Uploader
+	db        *gorm.DB
+	log       *logrus.Logger
+	uploader  s3.Uploader
+	passwords *password.Policy
 }
 
 var validUserSortFields = []string{
@@ -35,35 +37,46 @@ var validUserSortFields = []string{
 	"updated_at",
 }
 
+// publicUserSortFields are the sort fields of the directory for viewers without users.read
+var publicUserSortFields = []string{
+	"id",
+	"name",
+	"created_at",
+}
+
 type listUsersParams struct {
 	pagination pagination.Params
-	Role       string `form:"role" binding:"omitempty,oneof=admin user investor founder"`
+	Role       string `form:"role" binding:"omitempty,max=50"`
 	Email      string `form:"email" binding:"omitempty,email"`
 	Name       string `form:"name" binding:"omitempty"`
 }
 
 // NewUsersHandler returns a new UsersHandler
-func NewUsersHandler(db *gorm.DB, log *logrus.Logger, uploader s3.Uploader) *UsersHandler {
+func NewUsersHandler(db *gorm.DB, log *logrus.Logger, uploader s3.Uploader, passwords *password.Policy) *UsersHandler {
 	return &UsersHandler{
-		db:       db,
-		log:      log,
-		uploader: uploader,
+		db:        db,
+		log:       log,
+		uploader:  uploader,
+		passwords: passwords,
 	}
 }
 
 // GetUsers godoc
 // @Summary      List users
-// @Description  Returns a paginated list of users with optional filters.
+// @Description  Returns a paginated user directory. Viewers holding users.read get every user with all fields and filters.
+// @Description  Other viewers only see discoverable users as profiles: signed-in users get the role, profile links and the email of users who show it,
+// @Description  anonymous viewers get the id, name and avatar. Filtering by role requires signing in; filtering by email only matches users who show their email.
 // @Tags         Users
 // @Param        page      query int    false "Page" default(1)
 // @Param        per_page  query int    false "Page size" default(20)
-// @Param        sort      query string false "Sort field" Enums(id,email,name,role,created_at,updated_at) default(created_at)
+// @Param        sort      query string false "Sort field (email, role and updated_at require users.read)" Enums(id,email,name,role,created_at,updated_at) default(created_at)
 // @Param        order     query string false "Sort order" Enums(asc,desc) default(desc)
-// @Param        role      query string false "Filter by role" Enums(admin,user,investor,founder)
+// @Param        role      query string false "Filter by role name"
 // @Param        email     query string false "Filter by email (contains)"
 // @Param        name      query string false "Filter by name (contains)"
-// @Success      200 {object} response.UserListResponse
+// @Success      200 {object} response.UserDirectoryResponse
 // @Failure      400 {object} response.ErrorBody
+// @Failure      403 {object} response.ErrorBody
 // @Failure      500 {object} response.ErrorBody
 // @Router       /users [get]
 func (h *UsersHandler) GetUsers(c *gin.Context) {
@@ -76,19 +89,40 @@ func (h *UsersHandler) GetUsers(c *gin.Context) {
 		return
 	}
 
-	if !slices.Contains(validUserSortFields, params.pagination.Sort) {
+	viewer, err := h.directoryViewer(c)
+	if err != nil {
+		h.log.WithError(err).Error("failed to resolve directory viewer")
+		response.JSON(c, http.StatusInternalServerError, gin.H{"code": "internal_error", "message": "failed to check permissions"})
+		return
+	}
+
+	sortFields := validUserSortFields
+	if viewer != viewerAdmin {
+		sortFields = publicUserSortFields
+	}
+	if !slices.Contains(sortFields, params.pagination.Sort) {
 		response.JSON(c, http.StatusBadRequest, gin.H{
 			"code":    2117,
-			"message": fmt.Sprintf("invalid sort field '%s'. Allowed fields: %v", params.pagination.Sort, validUserSortFields),
+			"message": fmt.Sprintf("invalid sort field '%s'. Allowed fields: %v", params.pagination.Sort, sortFields),
 		})
 		return
 	}
+	if viewer == viewerAnonymous && (params.Role != "" || params.Email != "") {
+		response.JSON(c, http.StatusForbidden, gin.H{"code": "filter_not_allowed", "message": "sign in to filter users by role or email"})
+		return
+	}
 
 	query := h.db.Model(&models.User{})
+	if viewer != viewerAdmin {
+		query = query.Where("discoverable = ? AND anonymized_at IS NULL", true)
+	}
 	if params.Role != "" {
 		query = query.Where("role = ?", params.Role)
 	}
 	if params.Email != "" {
+		if viewer != viewerAdmin {
+			query = query.Where("show_email = ?", true)
+		}
 		query = query.Where("email ILIKE ?", "%"+params.Email+"%")
 	}
 	if params.Name != "" {
@@ -113,7 +147,7 @@ func (h *UsersHandler) GetUsers(c *gin.Context) {
 
 	totalPages := (int(total) + params.pagination.PerPage - 1) / params.pagination.PerPage
 	response.JSON(c, http.StatusOK, gin.H{
-		"data": users,
+		"data": projectUsers(users, viewer),
 		"pagination": gin.H{
 			"page":     params.pagination.Page,
 			"per_page": params.pagination.PerPage,
@@ -200,7 +234,7 @@ func (h *UsersHandler) GetUserByEmail(c *gin.Context) {
 
 // GetMe godoc
 // @Summary      My profile
-// @Description  Returns the current authenticated user's profile.
+// @Description  Returns the current authenticated user's profile. During an impersonation, an "impersonation" object identifies the acting admin.
 // @Tags         Users
 // @Security     CookieAuth
 // @Success      200 {object} response.UserObjectResponse
@@ -224,6 +258,17 @@ func (h *UsersHandler) GetMe(c *gin.Context) {
 		response.JSON(c, http.StatusInternalServerError, gin.H{"code": "internal_error", "message": "failed to retrieve profile"})
 		return
 	}
+	if claims.Impersonating() {
+		response.JSON(c, http.StatusOK, gin.H{
+			"data": user,
+			"impersonation": gin.H{
+				"impersonator_id":    claims.ImpersonatorID,
+				"impersonator_email": claims.ImpersonatorEmail,
+				"expires_at":         claims.ExpiresAt,
+			},
+		})
+		return
+	}
 	response.JSON(c, http.StatusOK, gin.H{"data": user})
 }
 
@@ -245,15 +290,21 @@ func (h *UsersHandler) CreateUser(c *gin.Context) {
 		Email    string `form:"email" binding:"required,email"`
 		Name     string `form:"name" binding:"required"`
 		Role     string `form:"role" binding:"required"`
-		Password string `form:"password" binding:"required,min=6"`
+		Password string `form:"password" binding:"required"`
 	}
 
 	if err := c.ShouldBind(&req); err != nil {
 		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
 		return
 	}
+	if !h.ensureRoleExists(c, req.Role) {
+		return
+	}
+	if !acceptablePassword(c, h.passwords, req.Password, req.Email, req.Name) {
+		return
+	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hash, err := h.passwords.Hash(req.Password)
 	if err != nil {
 		h.log.WithError(err).Error("failed to hash password")
 		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to process password", nil)
@@ -264,7 +315,7 @@ func (h *UsersHandler) CreateUser(c *gin.Context) {
 		Email:        req.Email,
 		Name:         req.Name,
 		Role:         req.Role,
-		PasswordHash: string(hash),
+		PasswordHash: hash,
 	}
 
 	file, err := c.FormFile("image")
@@ -345,13 +396,24 @@ func (h *UsersHandler) UpdateUser(c *gin.Context) {
 		user.Name = *req.Name
 	}
 	if req.Role != nil {
+		if !h.ensureRoleExists(c, *req.Role) {
+			return
+		}
 		updates["role"] = *req.Role
 		user.Role = *req.Role
 	}
 	if req.Password != nil {
-		hash, _ := bcrypt.GenerateFromPassword([]byte(*req.Password), bcrypt.DefaultCost)
-		updates["password_hash"] = string(hash)
-		user.PasswordHash = string(hash)
+		if !acceptablePassword(c, h.passwords, *req.Password, user.Email, user.Name) {
+			return
+		}
+		hash, err := h.passwords.Hash(*req.Password)
+		if err != nil {
+			h.log.WithError(err).Error("failed to hash password")
+			response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to process password", nil)
+			return
+		}
+		updates["password_hash"] = hash
+		user.PasswordHash = hash
 	}
 
 	if req.Role != nil && *req.Role == "founder" {
@@ -367,33 +429,14 @@ func (h *UsersHandler) UpdateUser(c *gin.Context) {
 			return
 		}
 
-		founder := models.Founder{
-			UserID:    user.ID,
-			StartupID: *req.StartupID,
-		}
-		if err := h.db.Create(&founder).Error; err != nil {
-			h.log.WithError(err).Error("h.db.Create(&founder).Error")
+		founder, err := assignFounder(h.db, &user, &startup)
+		if err != nil {
+			h.log.WithError(err).Error("assignFounder")
 			response.JSONError(c, http.StatusInternalServerError,
 				"internal_error", "failed to assign founder role", nil)
 			return
 		}
 
-		var founders []map[string]interface{}
-		if len(startup.Founders) > 0 {
-			_ = json.Unmarshal(startup.Founders, &founders)
-		}
-		founders = append(founders, map[string]interface{}{
-			"id":         founder.ID,
-			"name":       user.Name,
-			"startup_id": *req.StartupID,
-		})
-		updatedFounders, _ := json.Marshal(founders)
-		if err := h.db.Model(&startup).Update("founders", updatedFounders).Error; err != nil {
-			response.JSONError(c, http.StatusInternalServerError,
-				"internal_error", "failed to update startup founders", nil)
-			return
-		}
-
 		updates["founder_id"] = founder.ID
 		user.FounderID = &founder.ID
 	}
@@ -460,3 +503,45 @@ func (h *UsersHandler) DeleteUser(c *gin.Context) {
 		"message": "user deleted successfully",
 	})
 }
+
+// assignFounder makes user a founder of startup: it creates the founders row and adds the user to the startup's
+// founders list. Setting users.founder_id is left to the caller
+func assignFounder(db *gorm.DB, user *models.User, startup *models.Startup) (*models.Founder, error) {
+	founder := models.Founder{
+		UserID:    user.ID,
+		StartupID: startup.ID,
+	}
+	if err := db.Create(&founder).Error; err != nil {
+		return nil, fmt.Errorf("create founder: %w", err)
+	}
+
+	var founders []map[string]interface{}
+	if len(startup.Founders) > 0 {
+		_ = json.Unmarshal(startup.Founders, &founders)
+	}
+	founders = append(founders, map[string]interface{}{
+		"id":         founder.ID,
+		"name":       user.Name,
+		"startup_id": startup.ID,
+	})
+	updatedFounders, _ := json.Marshal(founders)
+	if err := db.Model(startup).Update("founders", updatedFounders).Error; err != nil {
+		return nil, fmt.Errorf("update startup founders: %w", err)
+	}
+	return &founder, nil
+}
+
+// ensureRoleExists rejects roles that are not defined in the roles table
+func (h *UsersHandler) ensureRoleExists(c *gin.Context, role string) bool {
+	ok, err := auth.RoleExists(c.Request.Context(), h.db, role)
+	if err != nil {
+		h.log.WithError(err).Error("failed to check role")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to check role", nil)
+		return false
+	}
+	if !ok {
+		response.JSONError(c, http.StatusBadRequest, "unknown_role", "unknown role", gin.H{"role": role})
+		return false
+	}
+	return true
+}
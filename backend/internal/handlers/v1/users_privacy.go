@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/http/requests"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// viewerLevel decides which projection of a user a viewer receives
+type viewerLevel int
+
+const (
+	viewerAnonymous viewerLevel = iota
+	viewerMember
+	viewerAdmin
+)
+
+// directoryViewer classifies the caller of a directory endpoint; routes use AuthOptional so claims may be missing
+func (h *UsersHandler) directoryViewer(c *gin.Context) (viewerLevel, error) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		return viewerAnonymous, nil
+	}
+	ok, err := auth.UserHasPermission(c.Request.Context(), h.db, claims.UserID, auth.PermUsersRead)
+	if err != nil {
+		return viewerAnonymous, err
+	}
+	if ok {
+		return viewerAdmin, nil
+	}
+	return viewerMember, nil
+}
+
+// projectUsers returns the users as seen by viewer: full records for admins, profiles otherwise
+func projectUsers(users []models.User, viewer viewerLevel) interface{} {
+	if viewer == viewerAdmin {
+		return users
+	}
+	profiles := make([]models.UserProfile, 0, len(users))
+	for _, u := range users {
+		if viewer == viewerMember {
+			profiles = append(profiles, u.MemberProfile())
+		} else {
+			profiles = append(profiles, u.PublicProfile())
+		}
+	}
+	return profiles
+}
+
+// UpdatePrivacy godoc
+// @Summary      Update privacy settings
+// @Description  Sets whether the current user appears in the user directory and whether signed-in users can see their email.
+// @Tags         Users
+// @Security     CookieAuth
+// @Accept       json
+// @Produce      json
+// @Param        payload body requests.UserPrivacyRequest true "Privacy settings" Example({"discoverable":false,"show_email":true})
+// @Success      200 {object} response.UserObjectResponse
+// @Failure      400 {object} response.ErrorBody
+// @Failure      401 {object} response.ErrorBody
+// @Failure      404 {object} response.ErrorBody
+// @Failure      500 {object} response.ErrorBody
+// @Router       /users/me/privacy [patch]
+func (h *UsersHandler) UpdatePrivacy(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing auth", nil)
+		return
+	}
+
+	var req requests.UserPrivacyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, http.StatusBadRequest, "invalid_payload", "invalid request payload", err.Error())
+		return
+	}
+	updates := map[string]interface{}{}
+	if req.Discoverable != nil {
+		updates["discoverable"] = *req.Discoverable
+	}
+	if req.ShowEmail != nil {
+		updates["show_email"] = *req.ShowEmail
+	}
+	if len(updates) == 0 {
+		response.JSONError(c, http.StatusBadRequest, "no_fields", "no fields provided for update", nil)
+		return
+	}
+
+	var user models.User
+	if err := h.db.First(&user, claims.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.JSONError(c, http.StatusNotFound, "not_found", "user not found", nil)
+			return
+		}
+		h.log.WithError(err).Error("failed to fetch current user")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to retrieve profile", nil)
+		return
+	}
+	if err := h.db.Model(&user).Updates(updates).Error; err != nil {
+		h.log.WithError(err).Error("failed to update privacy settings")
+		response.JSONError(c, http.StatusInternalServerError, "internal_error", "failed to update privacy settings", nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, gin.H{"data": user})
+}
@@ -0,0 +1,92 @@
+package v1_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/password"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	v1 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsersHandler_DirectoryPrivacy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupUsersDB(t)
+	seedRoles(t, db)
+
+	cfg := &config.Config{Auth: config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret", AccessTokenTTL: time.Hour, RefreshTokenTTL: time.Hour}}}
+	admin := models.User{Email: "admin@corp.tld", Name: "Admin", Role: "admin"}
+	jane := models.User{Email: "jane@corp.tld", Name: "Jane", Role: "founder"}
+	hidden := models.User{Email: "hidden@corp.tld", Name: "Hidden", Role: "investor"}
+	require.NoError(t, db.Create(&[]*models.User{&admin, &jane, &hidden}).Error)
+	access := func(u models.User) *http.Cookie {
+		pair, err := auth.GenerateTokenPair(cfg, u.ID, u.Email, u.Role, u.TokenVersion)
+		require.NoError(t, err)
+		return &http.Cookie{Name: "access_token", Value: pair.AccessToken}
+	}
+
+	h := v1.NewUsersHandler(db, logrus.New(), nil, password.NewPolicy(config.PasswordConfig{}, nil))
+	r := gin.New()
+	r.GET("/users", middleware.AuthOptional(cfg), h.GetUsers)
+	r.PATCH("/users/me/privacy", middleware.AuthRequired(cfg), h.UpdatePrivacy)
+	call := func(method, path, body string, ck *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if ck != nil {
+			req.AddCookie(ck)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	list := func(path string, ck *http.Cookie) []map[string]interface{} {
+		w := call(http.MethodGet, path, "", ck)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var out struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &out))
+		return out.Data
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodPatch, "/users/me/privacy", `{"discoverable":false}`, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPatch, "/users/me/privacy", `{}`, access(jane)).Code)
+	require.Equal(t, http.StatusOK, call(http.MethodPatch, "/users/me/privacy", `{"discoverable":false}`, access(hidden)).Code)
+	w := call(http.MethodPatch, "/users/me/privacy", `{"show_email":true}`, access(jane))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"show_email":true`)
+
+	// Anonymous viewers get public fields of discoverable users only
+	anon := list("/users?sort=id&order=asc", nil)
+	require.Len(t, anon, 2)
+	assert.Equal(t, "Jane", anon[1]["name"])
+	assert.NotContains(t, anon[1], "email")
+	assert.NotContains(t, anon[1], "role")
+	assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/users?role=founder", "", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodGet, "/users?sort=email", "", nil).Code)
+
+	// Signed-in viewers also get roles and the emails users chose to show
+	member := list("/users?sort=id&order=asc", access(hidden))
+	require.Len(t, member, 2)
+	assert.NotContains(t, member[0], "email")
+	assert.Equal(t, "jane@corp.tld", member[1]["email"])
+	assert.Equal(t, "founder", member[1]["role"])
+	assert.Len(t, list("/users?role=founder", access(hidden)), 1)
+
+	// Admins see everyone with every field
+	all := list("/users?sort=email&order=asc", access(admin))
+	require.Len(t, all, 3)
+	assert.Equal(t, "hidden@corp.tld", all[1]["email"])
+	assert.Equal(t, false, all[1]["discoverable"])
+}
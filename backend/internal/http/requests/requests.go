@@ -12,16 +12,10 @@ type AuthRegisterRequest struct {
 	Email string `json:"email" example:"john@doe.tld" format:"email"`
 	// Full name to display
 	Name string `json:"name" example:"John Doe"`
-	// Account role
-	Role string `json:"role" enums:"investor,founder" example:"founder"`
-	// Plain password (min 6 chars)
+	// Plain password, checked against the password policy
 	Password string `json:"password" example:"secret123"`
 	// Optional profile image URL
 	ImageURL *string `json:"image_url,omitempty" example:"https://cdn.example.com/avatars/john.png" format:"uri"`
-	// Optional associated founder profile ID
-	FounderID *uint64 `json:"founder_id,omitempty" example:"1"`
-	// Optional associated investor profile ID
-	InvestorID *uint64 `json:"investor_id,omitempty" example:"2"`
 }
 
 type AuthLoginRequest struct {
@@ -62,7 +56,7 @@ type UserCreateRequest struct {
 	// Role to assign
 	Role string `form:"role" json:"role" binding:"required" enums:"admin,user,investor,founder" example:"admin"`
 	// Initial password
-	Password string `form:"password" json:"password" binding:"required,min=6" example:"secret123"`
+	Password string `form:"password" json:"password" binding:"required" example:"secret123"`
 	// Avatar image file (binary upload)
 	Image string `form:"image" json:"image,omitempty" format:"binary" swagger:"desc(Avatar image file to upload)"`
 }
@@ -339,14 +333,143 @@ type ConversationCreateRequest struct {
 	Title *string `json:"title,omitempty" example:"Project Discussion"`
 	// Whether this is a group conversation
 	IsGroup bool `json:"is_group" example:"false"`
+	// What the conversation is about, given with context_id
+	ContextType *string `json:"context_type,omitempty" enums:"startup,opportunity" example:"startup"`
+	// ID of the startup or opportunity the conversation is about
+	ContextID *uint64 `json:"context_id,omitempty" example:"12"`
+}
+
+type ConversationUpdateRequest struct {
+	// New title
+	Title string `json:"title" binding:"required,max=255" example:"Seed round"`
+}
+
+type ConversationParticipantsAddRequest struct {
+	// Users to add as members
+	UserIDs []uint64 `json:"user_ids" binding:"required,min=1,max=50" example:"4,5"`
+}
+
+type ConversationOwnerRequest struct {
+	// Participant who becomes the owner
+	UserID uint64 `json:"user_id" binding:"required" example:"4"`
+}
+
+type UserBlockRequest struct {
+	// User to block
+	UserID uint64 `json:"user_id" binding:"required" example:"2"`
 }
 
 type MessageSendRequest struct {
-	// Message content
-	Content string `json:"content" binding:"required,max=2000" example:"Hello, how are you?"`
+	// Message content, optional when attachments are sent
+	Content string `json:"content" binding:"max=2000" example:"Here is our deck"`
+	// IDs of unsent uploads of the sender to attach
+	AttachmentIDs []uint64 `json:"attachment_ids,omitempty" example:"12"`
 }
 
 type MessageMarkReadRequest struct {
 	// Message ID to mark as read
 	MessageID uint64 `json:"message_id" binding:"required" example:"1"`
 }
+
+type MessageEditRequest struct {
+	// New message content
+	Content string `json:"content" binding:"required,max=2000" example:"Hello, how are you doing?"`
+}
+
+type MessageReportRequest struct {
+	// Why the message is reported
+	Reason string `json:"reason" binding:"required" enums:"spam,harassment,inappropriate,other" example:"spam"`
+	// Optional context for the moderators
+	Details *string `json:"details,omitempty" binding:"omitempty,max=1000" example:"Unsolicited advertising"`
+}
+
+type MessageReportResolveRequest struct {
+	// dismiss keeps the message, hide hides it
+	Action string `json:"action" binding:"required,oneof=dismiss hide" enums:"dismiss,hide" example:"hide"`
+	// Optional note, used as the moderation reason when hiding
+	Note *string `json:"note,omitempty" binding:"omitempty,max=500" example:"Harassment"`
+}
+
+type MessageHideRequest struct {
+	// Reason shown to moderators
+	Reason string `json:"reason" binding:"required,max=500" example:"Harassment"`
+}
+
+type RoleCreateRequest struct {
+	// Role name (lowercase letters, digits, '_' and '-')
+	Name string `json:"name" binding:"required,max=50" example:"moderator"`
+	// Optional description
+	Description *string `json:"description,omitempty" example:"Moderates news and events"`
+	// Granted permission names
+	Permissions []string `json:"permissions" example:"news.update,news.delete,events.update"`
+}
+
+type RoleUpdateRequest struct {
+	// New role name (custom roles only)
+	Name *string `json:"name,omitempty" example:"content_editor"`
+	// New description
+	Description *string `json:"description,omitempty" example:"Edits news and events"`
+	// Replaces the granted permissions when present
+	Permissions *[]string `json:"permissions,omitempty" example:"news.create,news.update"`
+}
+
+type EmailChangeRequest struct {
+	// New email address
+	NewEmail string `json:"new_email" binding:"required,email" example:"jane@new.tld"`
+	// Current password (required when the account has one)
+	Password string `json:"password,omitempty" example:"secret123"`
+}
+
+type AccountDeleteRequest struct {
+	// Current password (required when the account has one)
+	Password string `json:"password,omitempty" example:"secret123"`
+}
+
+type FeatureFlagRequest struct {
+	// Whether the feature is on
+	Enabled *bool `json:"enabled" binding:"required" example:"false"`
+}
+
+type NotificationPreferencesRequest struct {
+	// How often unread messages are emailed
+	MessageDigest string `json:"message_digest" binding:"required" enums:"immediate,daily,never" example:"daily"`
+}
+
+type UserPrivacyRequest struct {
+	// Whether the user appears in the user directory
+	Discoverable *bool `json:"discoverable,omitempty" example:"true"`
+	// Whether signed-in users can see the email address
+	ShowEmail *bool `json:"show_email,omitempty" example:"false"`
+}
+
+type PasswordChangeRequest struct {
+	// Current password (required when the account has one)
+	CurrentPassword string `json:"current_password,omitempty" example:"secret123"`
+	// New password, checked against the password policy
+	NewPassword string `json:"new_password" example:"c0rrect-Horse-battery"`
+}
+
+type MagicLinkRequest struct {
+	// Email of the account to sign in to
+	Email string `json:"email" binding:"required,email" example:"jane@doe.tld"`
+}
+
+type InvitationCreateRequest struct {
+	// Email address to invite
+	Email string `json:"email" binding:"required,email" example:"jane@startup.tld"`
+	// Role granted on acceptance (founders can only invite founders)
+	Role string `json:"role" binding:"required,max=50" example:"founder"`
+	// Startup the invitee joins as a founder (required for the founder role)
+	StartupID *uint64 `json:"startup_id,omitempty" example:"3"`
+	// Investor profile to link (investor role only)
+	InvestorID *uint64 `json:"investor_id,omitempty" example:"2"`
+}
+
+type InvitationAcceptRequest struct {
+	// Invitation token from the email
+	Token string `json:"token" binding:"required" example:"<invitation-token>"`
+	// Display name, required when no account exists for the invited email
+	Name string `json:"name,omitempty" example:"Jane Doe"`
+	// Password, required when no account exists for the invited email
+	Password string `json:"password,omitempty" example:"c0rrect-Horse-battery"`
+}
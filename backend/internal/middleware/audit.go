@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/audit"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// maxCapturedBody bounds the response bytes kept to read the identifier of created resources
+const maxCapturedBody = 64 << 10
+
+// bodyCaptureWriter tees the response body so the created resource ID can be read after the handler ran
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	if w.buf.Len()+len(b) <= maxCapturedBody {
+		w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Audit records successful create/update/delete requests on a resource when security.audit_log is enabled.
+// The target is identified by the :id path param, or by data.id in the response for creations.
+// When snap is set, the target is loaded before and after the handler and the changed fields are stored
+func Audit(cfg *config.Config, db *gorm.DB, log *logrus.Logger, targetType string, snap audit.Snapshot) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		verb := auditVerb(c.Request.Method)
+		if !cfg.Security.AuditLog || verb == "" {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		id := c.Param("id")
+		var before map[string]interface{}
+		if snap != nil && id != "" {
+			var err error
+			if before, err = snap(ctx, db, id); err != nil && log != nil {
+				log.WithError(err).WithField("target_type", targetType).Warn("audit snapshot failed")
+			}
+		}
+
+		var capture *bodyCaptureWriter
+		if id == "" {
+			capture = &bodyCaptureWriter{ResponseWriter: c.Writer}
+			c.Writer = capture
+		}
+
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		if capture != nil {
+			id = createdID(capture.buf.Bytes())
+		}
+
+		var changes map[string]models.AuditChange
+		if snap != nil && id != "" {
+			after, err := snap(ctx, db, id)
+			if err != nil && log != nil {
+				log.WithError(err).WithField("target_type", targetType).Warn("audit snapshot failed")
+			}
+			changes = audit.Diff(before, after)
+		}
+		recordAudit(c, db, log, targetType+"."+verb, targetType, id, changes)
+	}
+}
+
+// AuditAction records a successful request under a fixed action name, without snapshots
+func AuditAction(cfg *config.Config, db *gorm.DB, log *logrus.Logger, action, targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if !cfg.Security.AuditLog || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		recordAudit(c, db, log, action, targetType, c.Param("id"), nil)
+	}
+}
+
+func recordAudit(c *gin.Context, db *gorm.DB, log *logrus.Logger, action, targetType, targetID string, changes map[string]models.AuditChange) {
+	ev := models.AuditEvent{
+		Action:     action,
+		TargetType: targetType,
+		Status:     c.Writer.Status(),
+	}
+	if len(changes) > 0 {
+		ev.Changes = changes
+	}
+	if targetID != "" {
+		ev.TargetID = &targetID
+	}
+	if claims := GetClaims(c); claims != nil {
+		uid, email := claims.UserID, claims.Email
+		ev.ActorID, ev.ActorEmail = &uid, &email
+		if claims.Impersonating() {
+			imp := claims.ImpersonatorID
+			ev.ImpersonatorID = &imp
+		}
+	}
+	if ip := c.ClientIP(); ip != "" {
+		ev.IP = &ip
+	}
+	if rid := c.GetString(RequestIDHeader); rid != "" {
+		ev.RequestID = &rid
+	} else if rid := c.Writer.Header().Get(RequestIDHeader); rid != "" {
+		ev.RequestID = &rid
+	}
+
+	// The request context may already be cancelled once the response is written
+	if err := audit.Record(context.WithoutCancel(c.Request.Context()), db, &ev); err != nil && log != nil {
+		log.WithError(err).WithField("action", action).Error("failed to record audit event")
+	}
+}
+
+func auditVerb(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut, http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return ""
+}
+
+// createdID extracts data.id from a JSON response body
+func createdID(body []byte) string {
+	var payload struct {
+		Data struct {
+			ID json.Number `json:"id"`
+		} `json:"data"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&payload); err != nil || payload.Data.ID == "" {
+		return ""
+	}
+	return payload.Data.ID.String()
+}
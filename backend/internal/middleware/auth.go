@@ -16,8 +16,18 @@ const (
 	ctxClaimsKey = "auth_claims"
 )
 
-// AuthRequired validates the access token from HttpOnly cookies and attaches claims to context
+// AuthRequired validates the access token from HttpOnly cookies and attaches claims to context.
+// When auth.impersonation.read_only is set, write requests made with an impersonation token are rejected
 func AuthRequired(cfg *config.Config) gin.HandlerFunc {
+	return authRequired(cfg, false)
+}
+
+// ImpersonationAuth is AuthRequired without the read-only restriction, for the endpoint that ends an impersonation
+func ImpersonationAuth(cfg *config.Config) gin.HandlerFunc {
+	return authRequired(cfg, true)
+}
+
+func authRequired(cfg *config.Config, allowImpersonatedWrites bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Strict cookie-only authentication
 		var token string
@@ -33,11 +43,32 @@ func AuthRequired(cfg *config.Config) gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"code": "invalid_token", "message": "invalid access token"})
 			return
 		}
+		if claims.Impersonating() && cfg.Auth.Impersonation.ReadOnly && !allowImpersonatedWrites && !isSafeMethod(c.Request.Method) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"code": "impersonation_read_only", "message": "write actions are disabled while impersonating"})
+			return
+		}
 		c.Set(ctxClaimsKey, claims)
 		c.Next()
 	}
 }
 
+// AuthOptional attaches claims to context when a valid access token is present and lets anonymous requests through.
+// An invalid or expired token is treated as anonymous rather than rejected
+func AuthOptional(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token, err := c.Cookie("access_token"); err == nil && token != "" {
+			if claims, err := auth.ParseClaims(cfg, token); err == nil && claims != nil && claims.Type == "access" {
+				c.Set(ctxClaimsKey, claims)
+			}
+		}
+		c.Next()
+	}
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
 // GetClaims retrieves auth claims from context
 func GetClaims(c *gin.Context) *auth.Claims {
 	v, ok := c.Get(ctxClaimsKey)
@@ -50,12 +81,21 @@ func GetClaims(c *gin.Context) *auth.Claims {
 	return nil
 }
 
-// RequireAdmin ensures the authenticated user has the admin role
-func RequireAdmin() gin.HandlerFunc {
+// RequirePermission ensures the authenticated user's role is granted the named permission
+func RequirePermission(db *gorm.DB, perm string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		claims := GetClaims(c)
-		if claims == nil || strings.ToLower(claims.Role) != "admin" {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"code": "forbidden", "message": "admin only"})
+		if claims == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"code": "unauthorized", "message": "missing auth"})
+			return
+		}
+		ok, err := auth.UserHasPermission(c.Request.Context(), db, claims.UserID, perm)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"code": "internal_error", "message": "failed to check permissions"})
+			return
+		}
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"code": "forbidden", "message": "missing permission " + perm})
 			return
 		}
 		c.Next()
@@ -78,19 +118,19 @@ func RequireSelfByParam(param string) gin.HandlerFunc {
 	}
 }
 
-// RequireSelfOrAdminByParam allows access if admin or if :param matches claims.UserID
-func RequireSelfOrAdminByParam(param string) gin.HandlerFunc {
+// RequireSelfOrPermissionByParam allows access if :param matches claims.UserID or if the user holds perm
+func RequireSelfOrPermissionByParam(db *gorm.DB, param, perm string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		claims := GetClaims(c)
 		if claims == nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"code": "unauthorized", "message": "missing auth"})
 			return
 		}
-		if strings.ToLower(claims.Role) == "admin" {
+		if c.Param(param) != "" && c.Param(param) == strconv.FormatUint(claims.UserID, 10) {
 			c.Next()
 			return
 		}
-		if c.Param(param) != "" && c.Param(param) == strconv.FormatUint(claims.UserID, 10) {
+		if ok, err := auth.UserHasPermission(c.Request.Context(), db, claims.UserID, perm); err == nil && ok {
 			c.Next()
 			return
 		}
@@ -98,19 +138,19 @@ func RequireSelfOrAdminByParam(param string) gin.HandlerFunc {
 	}
 }
 
-// RequireSelfOrAdminByEmailParam allows access if admin or if :param email matches claims.Email
-func RequireSelfOrAdminByEmailParam(param string) gin.HandlerFunc {
+// RequireSelfOrPermissionByEmailParam allows access if :param email matches claims.Email or if the user holds perm
+func RequireSelfOrPermissionByEmailParam(db *gorm.DB, param, perm string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		claims := GetClaims(c)
 		if claims == nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"code": "unauthorized", "message": "missing auth"})
 			return
 		}
-		if strings.ToLower(claims.Role) == "admin" {
+		if c.Param(param) != "" && strings.EqualFold(c.Param(param), claims.Email) {
 			c.Next()
 			return
 		}
-		if c.Param(param) != "" && strings.EqualFold(c.Param(param), claims.Email) {
+		if ok, err := auth.UserHasPermission(c.Request.Context(), db, claims.UserID, perm); err == nil && ok {
 			c.Next()
 			return
 		}
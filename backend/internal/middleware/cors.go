@@ -1,41 +1,45 @@
 package middleware
 
 import (
+	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
 	"github.com/gin-gonic/gin"
 )
 
+// CORS answers cross-origin requests from the configured origins. Entries are exact origins
+// ("https://app.example.com"), wildcard subdomains ("https://*.example.com") or "*". Credentials
+// are only allowed for explicitly listed origins: "*" allows any origin without cookies
 func CORS(cfg *config.Config) gin.HandlerFunc {
 	allowedMethods := strings.Join(cfg.Security.CORS.AllowedMethods, ", ")
+	allowAny := false
+	patterns := make([]originPattern, 0, len(cfg.Security.CORS.AllowedOrigins))
+	for _, o := range cfg.Security.CORS.AllowedOrigins {
+		if o == "*" {
+			allowAny = true
+			continue
+		}
+		if p, ok := parseOriginPattern(o); ok {
+			patterns = append(patterns, p)
+		}
+	}
 
 	return func(c *gin.Context) {
 		origin := c.GetHeader("Origin")
-		allowAny := false
-		for _, o := range cfg.Security.CORS.AllowedOrigins {
-			if o == "*" {
-				allowAny = true
-				break
-			}
-		}
 		if origin != "" {
-			if allowAny {
+			if originAllowed(patterns, origin) {
 				c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
-			} else {
-				for _, o := range cfg.Security.CORS.AllowedOrigins {
-					if strings.EqualFold(o, origin) {
-						c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
-						break
-					}
-				}
+				c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			} else if allowAny {
+				c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 			}
 		}
 
 		c.Writer.Header().Set("Access-Control-Allow-Methods", allowedMethods)
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Requested-With, X-Request-ID")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Requested-With, X-Request-ID, "+CSRFHeaderName)
 		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Vary", "Origin")
 
 		if c.Request.Method == "OPTIONS" {
@@ -45,3 +49,69 @@ func CORS(cfg *config.Config) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// WebSocketOriginChecker validates the Origin of WebSocket upgrades, which CORS does not cover: browsers
+// may only connect from their own host or from origins explicitly listed (never "*"), since the access-token
+// cookie is sent along. Requests without an Origin header come from non-browser clients and are allowed
+func WebSocketOriginChecker(cfg *config.Config) func(*http.Request) bool {
+	patterns := make([]originPattern, 0, len(cfg.Security.CORS.AllowedOrigins))
+	for _, o := range cfg.Security.CORS.AllowedOrigins {
+		if p, ok := parseOriginPattern(o); ok {
+			patterns = append(patterns, p)
+		}
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+		return originAllowed(patterns, origin)
+	}
+}
+
+// originPattern is a parsed allowed origin. A leading "*." in host matches any subdomain, but not the bare domain
+type originPattern struct {
+	scheme   string
+	host     string
+	port     string
+	wildcard bool
+}
+
+func parseOriginPattern(s string) (originPattern, bool) {
+	u, err := url.Parse(strings.ToLower(strings.TrimRight(strings.TrimSpace(s), "/")))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return originPattern{}, false
+	}
+	p := originPattern{scheme: u.Scheme, host: u.Hostname(), port: u.Port()}
+	if strings.HasPrefix(p.host, "*.") {
+		p.wildcard = true
+		p.host = p.host[1:]
+	}
+	return p, p.host != ""
+}
+
+func originAllowed(patterns []originPattern, origin string) bool {
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" {
+		return false
+	}
+	host, port := u.Hostname(), u.Port()
+	for _, p := range patterns {
+		if p.scheme != u.Scheme || p.port != port {
+			continue
+		}
+		if p.wildcard {
+			if strings.HasSuffix(host, p.host) && len(host) > len(p.host) {
+				return true
+			}
+			continue
+		}
+		if host == p.host {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/features"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireFeature answers 404 while the named feature is off, as if its routes did not exist
+func RequireFeature(flags *features.Store, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !flags.Enabled(c.Request.Context(), name) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"code": "feature_disabled", "message": name + " is disabled"})
+			return
+		}
+		c.Next()
+	}
+}
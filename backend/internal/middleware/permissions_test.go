@@ -0,0 +1,61 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Role{}, &models.Permission{}))
+
+	update := models.Permission{Name: auth.PermNewsUpdate}
+	all := models.Permission{Name: auth.PermAll}
+	require.NoError(t, db.Create(&[]*models.Permission{&update, &all}).Error)
+	require.NoError(t, db.Create(&models.Role{Name: "admin", Permissions: []models.Permission{all}}).Error)
+	require.NoError(t, db.Create(&models.Role{Name: "editor", Permissions: []models.Permission{update}}).Error)
+
+	admin := models.User{Email: "admin@corp.tld", Name: "Admin", Role: "admin"}
+	editor := models.User{Email: "editor@corp.tld", Name: "Editor", Role: "editor"}
+	require.NoError(t, db.Create(&[]*models.User{&admin, &editor}).Error)
+
+	cfg := &config.Config{Auth: config.AuthConfig{JWT: config.JWTConfig{
+		Secret: "test-secret", AccessTokenTTL: time.Hour, RefreshTokenTTL: time.Hour,
+	}}}
+	r := gin.New()
+	r.Use(middleware.AuthRequired(cfg))
+	r.PATCH("/news", middleware.RequirePermission(db, auth.PermNewsUpdate), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	r.DELETE("/news", middleware.RequirePermission(db, auth.PermNewsDelete), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	call := func(u models.User, method string) int {
+		pair, err := auth.GenerateTokenPair(cfg, u.ID, u.Email, u.Role, u.TokenVersion)
+		require.NoError(t, err)
+		req := httptest.NewRequest(method, "/news", nil)
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: pair.AccessToken})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, call(editor, http.MethodPatch))
+	assert.Equal(t, http.StatusForbidden, call(editor, http.MethodDelete))
+	assert.Equal(t, http.StatusNoContent, call(admin, http.MethodDelete))
+
+	// The role is read from the database, so a demotion applies to tokens already issued
+	db.Model(&admin).Update("role", "editor")
+	assert.Equal(t, http.StatusForbidden, call(admin, http.MethodDelete))
+}
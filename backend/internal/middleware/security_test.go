@@ -0,0 +1,104 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORS_OriginMatching(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{Security: config.SecurityConfig{CORS: config.CORSConfig{
+		AllowedOrigins: []string{"http://localhost:3000", "https://*.jeb.tld"},
+		AllowedMethods: []string{"GET", "POST"},
+	}}}
+	r := gin.New()
+	r.Use(middleware.CORS(cfg))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	cases := map[string]bool{
+		"http://localhost:3000":    true,
+		"https://app.jeb.tld":      true,
+		"https://a.b.jeb.tld":      true,
+		"https://jeb.tld":          false,
+		"http://app.jeb.tld":       false,
+		"https://evil-jeb.tld":     false,
+		"https://app.jeb.tld.evil": false,
+		"http://localhost:4000":    false,
+	}
+	for origin, allowed := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if allowed {
+			assert.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"), origin)
+			assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"), origin)
+		} else {
+			assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), origin)
+		}
+	}
+
+	// "*" never allows credentials
+	cfg.Security.CORS.AllowedOrigins = []string{"*"}
+	r = gin.New()
+	r.Use(middleware.CORS(cfg))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://anywhere.tld")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCSRF_DoubleSubmit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{
+		Auth:     config.AuthConfig{JWT: config.JWTConfig{Secret: "test-secret"}},
+		Security: config.SecurityConfig{CSRF: config.CSRFConfig{Enabled: true}},
+	}
+	r := gin.New()
+	r.Use(middleware.CSRF(cfg))
+	r.Any("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	token, err := middleware.NewCSRFToken("test-secret")
+	require.NoError(t, err)
+	forged, err := middleware.NewCSRFToken("another-secret")
+	require.NoError(t, err)
+
+	call := func(method string, session bool, cookie, header string, extra map[string]string) int {
+		req := httptest.NewRequest(method, "/", nil)
+		if session {
+			req.AddCookie(&http.Cookie{Name: "access_token", Value: "jwt"})
+		}
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: middleware.CSRFCookieName, Value: cookie})
+		}
+		if header != "" {
+			req.Header.Set(middleware.CSRFHeaderName, header)
+		}
+		for k, v := range extra {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, call(http.MethodGet, true, "", "", nil), "safe methods are not checked")
+	assert.Equal(t, http.StatusNoContent, call(http.MethodPost, false, "", "", nil), "requests without a session are not checked")
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, true, "", "", nil))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPatch, true, token, "", nil))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodDelete, true, token, forged, nil))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, true, forged, forged, nil), "tokens must be signed with the app secret")
+	assert.Equal(t, http.StatusNoContent, call(http.MethodPost, true, token, token, nil))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, true, "", "", map[string]string{"Authorization": "Bearer abc"}), "other credentials do not lift the check")
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, true, "", "", map[string]string{"X-API-Key": "key"}))
+}
@@ -0,0 +1,275 @@
+// Package digest emails users a summary of the messages they left unread
+package digest
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/notifications/email"
+	"gorm.io/gorm"
+)
+
+// Options configures a digest run
+type Options struct {
+	// How long a message stays unread before it is emailed
+	Delay time.Duration
+	// Base URL of the web app, for the links to the conversations
+	BaseURL string
+	// URL of the unsubscribe endpoint, the token is appended as a query parameter
+	UnsubscribeURL string
+	// Secret signing the unsubscribe tokens
+	Secret string
+}
+
+// Messages shown per conversation and length of their snippets
+const (
+	messagesPerConversation = 3
+	snippetLength           = 140
+)
+
+// pending is an unread message waiting to be emailed to UserID
+type pending struct {
+	UserID         uint64
+	ConversationID uint64
+	MessageID      uint64
+	SenderID       uint64
+	Content        string
+	// Last message emailed before this run
+	Digested *uint64
+}
+
+type conversationDigest struct {
+	id       uint64
+	messages []pending
+}
+
+func (c *conversationDigest) last() uint64 { return c.messages[len(c.messages)-1].MessageID }
+
+// Send emails every user with messages unread for longer than opts.Delay a digest of them, following their
+// preference: immediate users get one at each run, daily ones at most once a day. Messages of muted
+// conversations and of blocked users are left out, and each message is emailed once.
+// A digest that cannot be sent is given another chance at the next run; it returns how many digests were sent
+func Send(ctx context.Context, db *gorm.DB, mailer email.Mailer, opts Options, now time.Time) (int, error) {
+	db = db.WithContext(ctx)
+	var rows []pending
+	if err := db.Table("messages m").
+		Select("cp.user_id, m.conversation_id, m.id AS message_id, m.sender_id, m.content, cp.digested_message_id AS digested").
+		Joins("JOIN conversation_participants cp ON cp.conversation_id = m.conversation_id AND cp.left_at IS NULL AND NOT cp.muted").
+		Joins("JOIN users u ON u.id = cp.user_id AND u.anonymized_at IS NULL").
+		Where("m.deleted_at IS NULL AND m.kind = ? AND m.sender_id <> cp.user_id AND m.created_at <= ?", models.MessageKindText, now.Add(-opts.Delay)).
+		Where("(cp.last_read_message_id IS NULL OR m.id > cp.last_read_message_id) AND (cp.digested_message_id IS NULL OR m.id > cp.digested_message_id)").
+		Where("m.sender_id NOT IN (SELECT blocked_id FROM user_blocks WHERE blocker_id = cp.user_id)").
+		Where("u.message_digest = ? OR (u.message_digest = ? AND (u.message_digest_sent_at IS NULL OR u.message_digest_sent_at <= ?))",
+			models.MessageDigestImmediate, models.MessageDigestDaily, now.Add(-24*time.Hour)).
+		Order("cp.user_id, m.conversation_id, m.id").
+		Scan(&rows).Error; err != nil {
+		return 0, fmt.Errorf("find unread messages: %w", err)
+	}
+
+	byUser := make(map[uint64][]*conversationDigest)
+	var userIDs []uint64
+	for _, row := range rows {
+		convs := byUser[row.UserID]
+		if len(convs) == 0 {
+			userIDs = append(userIDs, row.UserID)
+		}
+		if len(convs) == 0 || convs[len(convs)-1].id != row.ConversationID {
+			convs = append(convs, &conversationDigest{id: row.ConversationID})
+			byUser[row.UserID] = convs
+		}
+		last := convs[len(convs)-1]
+		last.messages = append(last.messages, row)
+	}
+
+	sent := 0
+	var errs []error
+	for _, userID := range userIDs {
+		claimed, err := claim(db, userID, byUser[userID])
+		if err != nil {
+			return sent, err
+		}
+		if len(claimed) == 0 {
+			continue
+		}
+		if err := sendDigest(ctx, db, mailer, opts, userID, claimed, now); err != nil {
+			errs = append(errs, err)
+			release(db, userID, claimed)
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+func sendDigest(ctx context.Context, db *gorm.DB, mailer email.Mailer, opts Options, userID uint64, convs []*conversationDigest, now time.Time) error {
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return fmt.Errorf("load user %d: %w", userID, err)
+	}
+	subject, body, err := render(db, opts, user, convs)
+	if err != nil {
+		return err
+	}
+	if err := mailer.Send(ctx, user.Email, subject, body); err != nil {
+		return fmt.Errorf("send digest to user %d: %w", userID, err)
+	}
+	if err := db.Model(&user).Update("message_digest_sent_at", now).Error; err != nil {
+		return fmt.Errorf("mark digest of user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// claim marks the messages of convs as emailed and returns the conversations that were not claimed
+// meanwhile by a concurrent run
+func claim(db *gorm.DB, userID uint64, convs []*conversationDigest) ([]*conversationDigest, error) {
+	claimed := make([]*conversationDigest, 0, len(convs))
+	for _, conv := range convs {
+		last := conv.last()
+		res := db.Model(&models.ConversationParticipant{}).
+			Where("conversation_id = ? AND user_id = ? AND (digested_message_id IS NULL OR digested_message_id < ?)", conv.id, userID, last).
+			Update("digested_message_id", last)
+		if res.Error != nil {
+			return claimed, fmt.Errorf("claim conversation %d for user %d: %w", conv.id, userID, res.Error)
+		}
+		if res.RowsAffected > 0 {
+			claimed = append(claimed, conv)
+		}
+	}
+	return claimed, nil
+}
+
+// release gives back the conversations claimed for a digest that could not be sent
+func release(db *gorm.DB, userID uint64, convs []*conversationDigest) {
+	for _, conv := range convs {
+		db.Model(&models.ConversationParticipant{}).
+			Where("conversation_id = ? AND user_id = ? AND digested_message_id = ?", conv.id, userID, conv.last()).
+			Update("digested_message_id", conv.messages[0].Digested)
+	}
+}
+
+func render(db *gorm.DB, opts Options, user models.User, convs []*conversationDigest) (string, string, error) {
+	conversationIDs := make([]uint64, len(convs))
+	var senderIDs []uint64
+	total := 0
+	for i, conv := range convs {
+		conversationIDs[i] = conv.id
+		for _, m := range conv.messages {
+			senderIDs = append(senderIDs, m.SenderID)
+		}
+		total += len(conv.messages)
+	}
+	var conversations []models.Conversation
+	if err := db.Where("id IN ?", conversationIDs).Find(&conversations).Error; err != nil {
+		return "", "", fmt.Errorf("load conversations: %w", err)
+	}
+	titles := make(map[uint64]*string, len(conversations))
+	for _, conv := range conversations {
+		titles[conv.ID] = conv.Title
+	}
+	var senders []models.User
+	if err := db.Where("id IN ?", senderIDs).Find(&senders).Error; err != nil {
+		return "", "", fmt.Errorf("load senders: %w", err)
+	}
+	names := make(map[uint64]string, len(senders))
+	for _, s := range senders {
+		names[s.ID] = s.Name
+	}
+
+	baseURL := strings.TrimRight(opts.BaseURL, "/")
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "<p>Hello %s,</p><p>You have %s waiting for you:</p>", html.EscapeString(user.Name), plural(total, "unread message"))
+	for _, conv := range convs {
+		title := conversationTitle(titles[conv.id], conv.messages, names)
+		fmt.Fprintf(&b, "<h3><a href=\"%s/conversations/%d\">%s</a></h3><ul>", baseURL, conv.id, html.EscapeString(title))
+		shown := conv.messages
+		if len(shown) > messagesPerConversation {
+			shown = shown[len(shown)-messagesPerConversation:]
+		}
+		for _, m := range shown {
+			fmt.Fprintf(&b, "<li><strong>%s</strong>: %s</li>", html.EscapeString(names[m.SenderID]), html.EscapeString(Snippet(m.Content)))
+		}
+		b.WriteString("</ul>")
+		if more := len(conv.messages) - len(shown); more > 0 {
+			fmt.Fprintf(&b, "<p>and %s more</p>", plural(more, "message"))
+		}
+	}
+	fmt.Fprintf(&b, "<p style=\"font-size:small\">You receive these emails %s. <a href=\"%s?token=%s\">Unsubscribe</a></p>",
+		frequencyLabel(user.MessageDigest), opts.UnsubscribeURL, UnsubscribeToken(opts.Secret, user.ID))
+	return fmt.Sprintf("You have %s", plural(total, "unread message")), b.String(), nil
+}
+
+// conversationTitle is the title of the conversation, or the names of the senders when it has none
+func conversationTitle(title *string, messages []pending, names map[uint64]string) string {
+	if title != nil && *title != "" {
+		return *title
+	}
+	var senders []string
+	seen := make(map[uint64]bool)
+	for _, m := range messages {
+		if !seen[m.SenderID] {
+			seen[m.SenderID] = true
+			senders = append(senders, names[m.SenderID])
+		}
+	}
+	return "Conversation with " + strings.Join(senders, ", ")
+}
+
+// Snippet shortens content to the start of its first words, on a single line
+func Snippet(content string) string {
+	content = strings.Join(strings.Fields(content), " ")
+	runes := []rune(content)
+	if len(runes) <= snippetLength {
+		return content
+	}
+	cut := string(runes[:snippetLength])
+	if i := strings.LastIndex(cut, " "); i > snippetLength/2 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func frequencyLabel(preference string) string {
+	if preference == models.MessageDigestDaily {
+		return "once a day at most when messages are left unread"
+	}
+	return "when messages are left unread"
+}
+
+// UnsubscribeToken returns the token unsubscribing userID from the digests. It does not expire, so that
+// the links of past emails keep working
+func UnsubscribeToken(secret string, userID uint64) string {
+	id := strconv.FormatUint(userID, 10)
+	return id + "." + signature(secret, id)
+}
+
+// ParseUnsubscribeToken returns the user a token issued by UnsubscribeToken is for
+func ParseUnsubscribeToken(secret, token string) (uint64, bool) {
+	id, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(signature(secret, id))) {
+		return 0, false
+	}
+	userID, err := strconv.ParseUint(id, 10, 64)
+	return userID, err == nil
+}
+
+func signature(secret, id string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("digest-unsubscribe:" + id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
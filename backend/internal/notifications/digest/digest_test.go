@@ -0,0 +1,135 @@
+package digest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/notifications/digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type mail struct{ to, subject, body string }
+
+type mailbox struct {
+	sent []mail
+	fail bool
+}
+
+func (m *mailbox) Send(_ context.Context, to, subject, body string) error {
+	if m.fail {
+		return errors.New("smtp down")
+	}
+	m.sent = append(m.sent, mail{to, subject, body})
+	return nil
+}
+
+func TestSend(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Conversation{}, &models.ConversationParticipant{}, &models.Message{}, &models.UserBlock{}))
+
+	now := time.Now().UTC()
+	for _, u := range []models.User{
+		{ID: 1, Email: "founder@test.com", Name: "Founder", Role: "founder", MessageDigest: models.MessageDigestImmediate},
+		{ID: 2, Email: "investor@test.com", Name: "Investor <VC>", Role: "investor", MessageDigest: models.MessageDigestDaily},
+		{ID: 3, Email: "spam@test.com", Name: "Spammer", Role: "investor", MessageDigest: models.MessageDigestNever},
+	} {
+		require.NoError(t, db.Create(&u).Error)
+	}
+	title := "Seed round"
+	deal := models.Conversation{Title: &title}
+	direct := models.Conversation{}
+	require.NoError(t, db.Create(&deal).Error)
+	require.NoError(t, db.Create(&direct).Error)
+	for _, p := range []models.ConversationParticipant{
+		{ConversationID: deal.ID, UserID: 1}, {ConversationID: deal.ID, UserID: 2}, {ConversationID: deal.ID, UserID: 3},
+		{ConversationID: direct.ID, UserID: 1}, {ConversationID: direct.ID, UserID: 3},
+	} {
+		require.NoError(t, db.Create(&p).Error)
+	}
+	require.NoError(t, db.Create(&models.UserBlock{BlockerID: 1, BlockedID: 3}).Error)
+	message := func(conv, sender uint64, content string, age time.Duration) {
+		require.NoError(t, db.Create(&models.Message{ConversationID: conv, SenderID: sender, Content: content, Kind: models.MessageKindText, CreatedAt: now.Add(-age)}).Error)
+	}
+	message(deal.ID, 2, "Could you share your <b>deck</b>?", time.Hour)
+	message(deal.ID, 2, strings.Repeat("numbers ", 40), 50*time.Minute)
+	message(deal.ID, 3, "buy now", 40*time.Minute)
+	message(direct.ID, 3, "hello?", time.Hour)
+	message(deal.ID, 2, "too recent", time.Minute)
+
+	mails := &mailbox{}
+	opts := digest.Options{Delay: 30 * time.Minute, BaseURL: "https://app.test/", UnsubscribeURL: "https://api.test/unsubscribe", Secret: "secret"}
+
+	// A failed delivery is retried at the next run
+	mails.fail = true
+	n, err := digest.Send(context.Background(), db, mails, opts, now)
+	assert.Error(t, err)
+	assert.Zero(t, n)
+	mails.fail = false
+
+	n, err = digest.Send(context.Background(), db, mails, opts, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	require.Len(t, mails.sent, 2)
+
+	founder := mails.sent[0]
+	assert.Equal(t, "founder@test.com", founder.to)
+	assert.Equal(t, "You have 2 unread messages", founder.subject, "messages of blocked users are left out")
+	assert.Contains(t, founder.body, `<a href="https://app.test/conversations/1">Seed round</a>`)
+	assert.Contains(t, founder.body, "<strong>Investor &lt;VC&gt;</strong>: Could you share your &lt;b&gt;deck&lt;/b&gt;?")
+	assert.Contains(t, founder.body, "numbers numbers…")
+	assert.NotContains(t, founder.body, "too recent")
+	assert.Contains(t, founder.body, "https://api.test/unsubscribe?token="+digest.UnsubscribeToken("secret", 1))
+
+	investor := mails.sent[1]
+	assert.Equal(t, "investor@test.com", investor.to)
+	assert.Equal(t, "You have 1 unread message", investor.subject)
+	assert.Contains(t, investor.body, "once a day")
+
+	// Messages are emailed once; daily users wait a day before the next digest
+	message(deal.ID, 1, "Sure, here it is", 45*time.Minute)
+	mails.sent = nil
+	n, err = digest.Send(context.Background(), db, mails, opts, now)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = digest.Send(context.Background(), db, mails, opts, now.Add(25*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	for _, m := range mails.sent {
+		assert.NotContains(t, m.body, "Could you share", m.to)
+	}
+
+	// Muted conversations and read messages are not emailed
+	mails.sent = nil
+	message(deal.ID, 2, "any news?", 45*time.Minute)
+	message(deal.ID, 2, "ping", 45*time.Minute)
+	require.NoError(t, db.Model(&models.ConversationParticipant{}).Where("user_id = ? AND conversation_id = ?", 1, deal.ID).Update("muted", true).Error)
+	var last models.Message
+	require.NoError(t, db.Order("id DESC").First(&last).Error)
+	require.NoError(t, db.Model(&models.ConversationParticipant{}).Where("user_id = ?", 3).Update("last_read_message_id", last.ID).Error)
+	n, err = digest.Send(context.Background(), db, mails, opts, now.Add(26*time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestUnsubscribeToken(t *testing.T) {
+	token := digest.UnsubscribeToken("secret", 42)
+	id, ok := digest.ParseUnsubscribeToken("secret", token)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(42), id)
+
+	_, ok = digest.ParseUnsubscribeToken("other", token)
+	assert.False(t, ok)
+	_, ok = digest.ParseUnsubscribeToken("secret", "43"+strings.TrimPrefix(token, "42"))
+	assert.False(t, ok)
+	_, ok = digest.ParseUnsubscribeToken("secret", "")
+	assert.False(t, ok)
+}
@@ -0,0 +1,84 @@
+// Package inapp publishes in-app notifications to the event streams of connected users
+package inapp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
+	"gorm.io/gorm"
+)
+
+// Notification kinds
+const (
+	KindOpportunityDeadline = "opportunity_deadline"
+)
+
+// Notification is the payload of a realtime.EventNotification event
+type Notification struct {
+	Kind  string `json:"kind"`
+	Title string `json:"title"`
+	Body  string `json:"body,omitempty"`
+	// Resource the notification is about, e.g. "opportunity"
+	Resource   string `json:"resource,omitempty"`
+	ResourceID uint64 `json:"resource_id,omitempty"`
+	// When the notified event happens (a deadline, ...)
+	At *time.Time `json:"at,omitempty"`
+}
+
+// Notify sends n to recipients, or to every connected user when recipients is empty
+func Notify(ctx context.Context, broker realtime.Broker, n Notification, recipients ...uint64) error {
+	ev, err := realtime.NewEvent(realtime.EventNotification, 0, recipients, n)
+	if err != nil {
+		return err
+	}
+	ev.Broadcast = len(recipients) == 0
+	return broker.Publish(ctx, ev)
+}
+
+// RemindDeadlines announces the opportunities whose deadline falls within lead of now, once per deadline.
+// It returns how many opportunities were announced
+func RemindDeadlines(ctx context.Context, db *gorm.DB, broker realtime.Broker, now time.Time, lead time.Duration) (int, error) {
+	var due []models.Opportunity
+	if err := db.WithContext(ctx).
+		Where("deadline > ? AND deadline <= ? AND deadline_reminded_at IS NULL", now, now.Add(lead)).
+		Order("deadline ASC").
+		Find(&due).Error; err != nil {
+		return 0, fmt.Errorf("find opportunities: %w", err)
+	}
+
+	sent := 0
+	for _, o := range due {
+		// Claim the reminder first so that concurrent replicas do not announce it twice
+		res := db.WithContext(ctx).Model(&models.Opportunity{}).
+			Where("id = ? AND deadline_reminded_at IS NULL", o.ID).
+			Update("deadline_reminded_at", now)
+		if res.Error != nil {
+			return sent, fmt.Errorf("mark opportunity %d: %w", o.ID, res.Error)
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		n := Notification{
+			Kind:       KindOpportunityDeadline,
+			Title:      o.Title,
+			Body:       fmt.Sprintf("%s closes on %s", o.Title, o.Deadline.UTC().Format("January 2, 2006 15:04 MST")),
+			Resource:   "opportunity",
+			ResourceID: uint64(o.ID),
+			At:         o.Deadline,
+		}
+		if err := Notify(ctx, broker, n); err != nil {
+			// Release the claim so that the next run retries the reminder
+			if rerr := db.WithContext(context.WithoutCancel(ctx)).Model(&models.Opportunity{}).
+				Where("id = ?", o.ID).
+				Update("deadline_reminded_at", nil).Error; rerr != nil {
+				return sent, fmt.Errorf("notify opportunity %d: %w (release reminder: %v)", o.ID, err, rerr)
+			}
+			return sent, fmt.Errorf("notify opportunity %d: %w", o.ID, err)
+		}
+		sent++
+	}
+	return sent, nil
+}
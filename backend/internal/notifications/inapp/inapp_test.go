@@ -0,0 +1,84 @@
+package inapp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/notifications/inapp"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRemindDeadlines(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Opportunity{}))
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time { v := now.Add(d); return &v }
+	for _, o := range []models.Opportunity{
+		{Title: "Soon", Type: "grant", Organism: "EU", Deadline: at(24 * time.Hour)},
+		{Title: "Later", Type: "grant", Organism: "EU", Deadline: at(10 * 24 * time.Hour)},
+		{Title: "Closed", Type: "grant", Organism: "EU", Deadline: at(-time.Hour)},
+		{Title: "Open-ended", Type: "grant", Organism: "EU"},
+	} {
+		require.NoError(t, db.Create(&o).Error)
+	}
+
+	broker := realtime.NewMemoryBroker(8)
+	sub := broker.Subscribe(42)
+	defer sub.Close()
+
+	n, err := inapp.RemindDeadlines(context.Background(), db, broker, now, 72*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	ev := <-sub.Events()
+	assert.Equal(t, realtime.EventNotification, ev.Type)
+	assert.Contains(t, string(ev.Data), `"title":"Soon"`)
+
+	n, err = inapp.RemindDeadlines(context.Background(), db, broker, now.Add(time.Hour), 72*time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, n, "each deadline is announced once")
+}
+
+// failingBroker rejects every event
+type failingBroker struct {
+	*realtime.MemoryBroker
+}
+
+func (failingBroker) Publish(context.Context, realtime.Event) error {
+	return errors.New("broker unavailable")
+}
+
+func TestRemindDeadlines_NotifyFailure(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Opportunity{}))
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	deadline := now.Add(24 * time.Hour)
+	o := models.Opportunity{Title: "Soon", Type: "grant", Organism: "EU", Deadline: &deadline}
+	require.NoError(t, db.Create(&o).Error)
+
+	// A reminder that could not be published is not marked as sent
+	n, err := inapp.RemindDeadlines(context.Background(), db, failingBroker{realtime.NewMemoryBroker(8)}, now, 72*time.Hour)
+	assert.Error(t, err)
+	assert.Zero(t, n)
+	require.NoError(t, db.First(&o, o.ID).Error)
+	assert.Nil(t, o.DeadlineRemindedAt)
+
+	// so the next run sends it
+	broker := realtime.NewMemoryBroker(8)
+	sub := broker.Subscribe(42)
+	defer sub.Close()
+	n, err = inapp.RemindDeadlines(context.Background(), db, broker, now.Add(time.Minute), 72*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Contains(t, string((<-sub.Events()).Data), `"title":"Soon"`)
+}
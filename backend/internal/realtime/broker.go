@@ -0,0 +1,66 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Broker publishes events to the subscribers of every API replica
+type Broker interface {
+	Publish(ctx context.Context, ev Event) error
+	Subscribe(userID uint64) *Subscription
+	// Since returns the retained events of userID published after lastID; see Hub.Since
+	Since(userID uint64, lastID string) ([]Event, bool)
+	Close() error
+}
+
+// MemoryBroker delivers events within the current process only, for single-replica deployments and tests
+type MemoryBroker struct {
+	*Hub
+}
+
+func NewMemoryBroker(buffer int) *MemoryBroker {
+	return &MemoryBroker{Hub: NewHub(buffer)}
+}
+
+func (b *MemoryBroker) Publish(_ context.Context, ev Event) error {
+	b.Deliver(ev)
+	return nil
+}
+
+func (b *MemoryBroker) Close() error {
+	return nil
+}
+
+// New returns the broker selected by realtime.broker ("memory" or "postgres").
+// The Postgres broker needs the database; without it the process falls back to in-memory fan-out
+func New(ctx context.Context, cfg *config.Config, db *gorm.DB, log *logrus.Logger) (Broker, error) {
+	var b interface {
+		Broker
+		KeepHistory(size int, ttl time.Duration)
+	}
+	switch cfg.Realtime.Broker {
+	case "", "memory":
+		b = NewMemoryBroker(cfg.Realtime.BufferSize)
+	case "postgres":
+		if db == nil || cfg.Database.URL == "" {
+			log.Warn("realtime: database unavailable, falling back to in-memory broker")
+			b = NewMemoryBroker(cfg.Realtime.BufferSize)
+			break
+		}
+		pg, err := NewPostgresBroker(ctx, cfg.Database.URL, cfg.Realtime.Channel, db, cfg.Realtime.BufferSize, log)
+		if err != nil {
+			return nil, err
+		}
+		b = pg
+	default:
+		return nil, fmt.Errorf("unsupported realtime broker %q", cfg.Realtime.Broker)
+	}
+	b.KeepHistory(cfg.Realtime.HistorySize, cfg.Realtime.Retention)
+	return b, nil
+}
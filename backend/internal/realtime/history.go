@@ -0,0 +1,74 @@
+package realtime
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHistorySize = 1000
+	DefaultHistoryTTL  = 5 * time.Minute
+)
+
+type recordedEvent struct {
+	event Event
+	at    time.Time
+}
+
+// History is a ring buffer of the latest events. Every replica receives the events in the same order,
+// so a client can resume with the ID of the last event it saw on whichever replica it reconnects to
+type History struct {
+	mu     sync.Mutex
+	events []recordedEvent
+	next   int
+	full   bool
+	ttl    time.Duration
+}
+
+func NewHistory(size int, ttl time.Duration) *History {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	if ttl <= 0 {
+		ttl = DefaultHistoryTTL
+	}
+	return &History{events: make([]recordedEvent, size), ttl: ttl}
+}
+
+// Record appends ev, overwriting the oldest event once the buffer is full
+func (h *History) Record(ev Event, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events[h.next] = recordedEvent{event: ev, at: now}
+	h.next = (h.next + 1) % len(h.events)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Since returns the unexpired events addressed to userID recorded after lastID, oldest first.
+// ok is false when lastID is unknown or expired
+func (h *History) Since(userID uint64, lastID string, now time.Time) (events []Event, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ordered := h.events[:h.next]
+	if h.full {
+		ordered = append(append([]recordedEvent{}, h.events[h.next:]...), h.events[:h.next]...)
+	}
+	for i := len(ordered) - 1; i >= 0; i-- {
+		if ordered[i].event.ID != lastID {
+			continue
+		}
+		if now.Sub(ordered[i].at) > h.ttl {
+			return nil, false
+		}
+		for _, rec := range ordered[i+1:] {
+			if rec.event.addressedTo(userID) {
+				events = append(events, rec.event)
+			}
+		}
+		return events, true
+	}
+	return nil, false
+}
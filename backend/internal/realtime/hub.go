@@ -0,0 +1,199 @@
+package realtime
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event types pushed to clients
+const (
+	EventMessageCreated = "message.created"
+	EventMessageRead    = "message.read"
+	EventMessageUpdated = "message.updated"
+	EventMessageDeleted = "message.deleted"
+	EventTyping         = "typing"
+	// Unread messages of the recipient changed; sent to each participant separately
+	EventUnreadUpdated       = "unread.updated"
+	EventConversationCreated = "conversation.created"
+	// Title, owner or participants changed; also sent to the users who were removed or left
+	EventConversationUpdated = "conversation.updated"
+	// In-app notification from another subsystem (opportunity deadlines, ...)
+	EventNotification = "notification"
+)
+
+// DefaultBufferSize is the number of events queued per subscription before it is considered too slow
+const DefaultBufferSize = 32
+
+// Event is a notification pushed to the live sessions of its recipients
+type Event struct {
+	// Unique identifier, assigned on publication and used to resume streams
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	ConversationID uint64          `json:"conversation_id,omitempty"`
+	Data           json.RawMessage `json:"data,omitempty"`
+	// Set when the payload was dropped in transit; clients refetch the resource instead
+	Truncated bool `json:"truncated,omitempty"`
+	// Users the event is delivered to, never sent to clients
+	Recipients []uint64 `json:"-"`
+	// Deliver to every connected user instead of Recipients
+	Broadcast bool `json:"-"`
+}
+
+// addressedTo reports whether userID receives ev
+func (ev *Event) addressedTo(userID uint64) bool {
+	if ev.Broadcast {
+		return true
+	}
+	for _, id := range ev.Recipients {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// newEventID returns an identifier ordered by creation time, unique across replicas
+func newEventID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + hex.EncodeToString(b)
+}
+
+// NewEvent builds an event whose payload is data encoded as JSON
+func NewEvent(typ string, conversationID uint64, recipients []uint64, data interface{}) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("marshal %s event: %w", typ, err)
+	}
+	return Event{Type: typ, ConversationID: conversationID, Data: raw, Recipients: recipients}, nil
+}
+
+// Subscription receives the events addressed to one user on this replica
+type Subscription struct {
+	UserID uint64
+	events chan Event
+	hub    *Hub
+	closed bool
+}
+
+// Events is closed when the subscription is closed, including when it could not keep up with its events
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close unregisters the subscription; it is safe to call more than once
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Hub fans events out to the subscriptions of the local process
+type Hub struct {
+	mu      sync.Mutex
+	subs    map[uint64]map[*Subscription]struct{}
+	buffer  int
+	history *History
+}
+
+func NewHub(buffer int) *Hub {
+	if buffer <= 0 {
+		buffer = DefaultBufferSize
+	}
+	return &Hub{subs: make(map[uint64]map[*Subscription]struct{}), buffer: buffer}
+}
+
+// Subscribe registers a new subscription for userID
+func (h *Hub) Subscribe(userID uint64) *Subscription {
+	s := &Subscription{UserID: userID, events: make(chan Event, h.buffer), hub: h}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][s] = struct{}{}
+	return s
+}
+
+// KeepHistory retains the last size events for at most ttl so that streams can resume after a reconnection
+func (h *Hub) KeepHistory(size int, ttl time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.history = NewHistory(size, ttl)
+}
+
+// Since returns the retained events addressed to userID published after the event lastID.
+// ok is false when lastID is no longer retained (or history is disabled): the client must resynchronize
+func (h *Hub) Since(userID uint64, lastID string) (events []Event, ok bool) {
+	h.mu.Lock()
+	history := h.history
+	h.mu.Unlock()
+	if history == nil {
+		return nil, false
+	}
+	return history.Since(userID, lastID, time.Now())
+}
+
+// Deliver hands ev to every local subscription of its recipients without blocking.
+// A subscription whose buffer is full is closed so that its client reconnects and resynchronizes
+func (h *Hub) Deliver(ev Event) {
+	if ev.ID == "" {
+		ev.ID = newEventID()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.history != nil {
+		h.history.Record(ev, time.Now())
+	}
+	if ev.Broadcast {
+		for _, set := range h.subs {
+			h.send(set, ev)
+		}
+		return
+	}
+	for _, userID := range ev.Recipients {
+		h.send(h.subs[userID], ev)
+	}
+}
+
+// send must be called with h.mu held
+func (h *Hub) send(set map[*Subscription]struct{}, ev Event) {
+	for s := range set {
+		select {
+		case s.events <- ev:
+		default:
+			h.remove(s)
+		}
+	}
+}
+
+// Connections returns the number of open subscriptions
+func (h *Hub) Connections() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := 0
+	for _, set := range h.subs {
+		n += len(set)
+	}
+	return n
+}
+
+// remove must be called with h.mu held
+func (h *Hub) remove(s *Subscription) {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.events)
+	if set := h.subs[s.UserID]; set != nil {
+		delete(set, s)
+		if len(set) == 0 {
+			delete(h.subs, s.UserID)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package realtime_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBroker_Delivery(t *testing.T) {
+	b := realtime.NewMemoryBroker(2)
+	alice := b.Subscribe(1)
+	aliceTab := b.Subscribe(1)
+	bob := b.Subscribe(2)
+	assert.Equal(t, 3, b.Connections())
+
+	ev, err := realtime.NewEvent(realtime.EventTyping, 7, []uint64{1}, map[string]uint64{"user_id": 2})
+	require.NoError(t, err)
+	require.NoError(t, b.Publish(context.Background(), ev))
+
+	got := <-alice.Events()
+	assert.Equal(t, realtime.EventTyping, got.Type)
+	assert.Equal(t, uint64(7), got.ConversationID)
+	assert.JSONEq(t, `{"user_id":2}`, string(got.Data))
+	assert.Equal(t, realtime.EventTyping, (<-aliceTab.Events()).Type)
+	select {
+	case <-bob.Events():
+		t.Fatal("bob is not a recipient")
+	default:
+	}
+
+	// A subscriber that does not drain its events is disconnected instead of blocking the others
+	for i := 0; i < 3; i++ {
+		require.NoError(t, b.Publish(context.Background(), realtime.Event{Type: "ping", Recipients: []uint64{1, 2}}))
+		<-bob.Events()
+	}
+	n := 0
+	for range alice.Events() {
+		n++
+	}
+	assert.Equal(t, 2, n, "buffered events are still delivered before the channel closes")
+	assert.Equal(t, 1, b.Connections())
+
+	bob.Close()
+	bob.Close()
+	assert.Zero(t, b.Connections())
+}
+
+func TestHub_HistoryResume(t *testing.T) {
+	b := realtime.NewMemoryBroker(8)
+	b.KeepHistory(3, time.Minute)
+	publish := func(ev realtime.Event) string {
+		sub := b.Subscribe(1)
+		defer sub.Close()
+		require.NoError(t, b.Publish(context.Background(), ev))
+		return (<-sub.Events()).ID
+	}
+
+	first := publish(realtime.Event{Type: "a", Recipients: []uint64{1}})
+	require.NoError(t, b.Publish(context.Background(), realtime.Event{Type: "other", Recipients: []uint64{2}}))
+	publish(realtime.Event{Type: "b", Broadcast: true})
+
+	missed, ok := b.Since(1, first)
+	require.True(t, ok)
+	require.Len(t, missed, 1, "events of other users are not replayed")
+	assert.Equal(t, "b", missed[0].Type)
+
+	// Once the buffer wraps around, the oldest IDs can no longer be resumed from
+	publish(realtime.Event{Type: "c", Recipients: []uint64{1}})
+	_, ok = b.Since(1, first)
+	assert.False(t, ok)
+	_, ok = b.Since(1, "unknown")
+	assert.False(t, ok)
+}
@@ -0,0 +1,138 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultChannel is the Postgres notification channel used when realtime.channel is empty
+	DefaultChannel = "realtime_events"
+	// maxNotifyPayload stays below the 8000 bytes Postgres accepts in a NOTIFY payload
+	maxNotifyPayload = 7900
+	maxListenBackoff = 30 * time.Second
+)
+
+// envelope is the NOTIFY payload: the event and its recipients, which Event does not serialize
+type envelope struct {
+	Recipients []uint64 `json:"r,omitempty"`
+	Broadcast  bool     `json:"b,omitempty"`
+	Event      Event    `json:"e"`
+}
+
+// PostgresBroker fans events out to every replica through Postgres LISTEN/NOTIFY.
+// Publishing only notifies: local subscribers receive the event back from the listener like the other replicas
+type PostgresBroker struct {
+	*Hub
+	db      *gorm.DB
+	channel string
+	cancel  context.CancelFunc
+	done    chan struct{}
+	log     *logrus.Logger
+}
+
+// NewPostgresBroker starts listening on channel with a dedicated connection to dsn, reconnecting with backoff when it drops
+func NewPostgresBroker(ctx context.Context, dsn, channel string, db *gorm.DB, buffer int, log *logrus.Logger) (*PostgresBroker, error) {
+	if channel == "" {
+		channel = DefaultChannel
+	}
+	if _, err := pgx.ParseConfig(dsn); err != nil {
+		return nil, fmt.Errorf("parse database url: %w", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	b := &PostgresBroker{
+		Hub:     NewHub(buffer),
+		db:      db,
+		channel: channel,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		log:     log,
+	}
+	go b.listen(ctx, dsn)
+	return b, nil
+}
+
+func (b *PostgresBroker) Publish(ctx context.Context, ev Event) error {
+	if ev.ID == "" {
+		ev.ID = newEventID()
+	}
+	payload, err := json.Marshal(envelope{Recipients: ev.Recipients, Broadcast: ev.Broadcast, Event: ev})
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	if len(payload) > maxNotifyPayload {
+		ev.Data = nil
+		ev.Truncated = true
+		if payload, err = json.Marshal(envelope{Recipients: ev.Recipients, Broadcast: ev.Broadcast, Event: ev}); err != nil {
+			return fmt.Errorf("marshal event: %w", err)
+		}
+	}
+	if err := b.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", b.channel, string(payload)).Error; err != nil {
+		return fmt.Errorf("pg_notify: %w", err)
+	}
+	return nil
+}
+
+// Close stops the listener and waits for its connection to be released
+func (b *PostgresBroker) Close() error {
+	b.cancel()
+	<-b.done
+	return nil
+}
+
+func (b *PostgresBroker) listen(ctx context.Context, dsn string) {
+	defer close(b.done)
+	backoff := time.Second
+	for {
+		err := b.listenOnce(ctx, dsn, func() { backoff = time.Second })
+		if ctx.Err() != nil {
+			return
+		}
+		b.log.WithError(err).WithField("retry_in", backoff).Warn("realtime: listener disconnected")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxListenBackoff)
+	}
+}
+
+func (b *PostgresBroker) listenOnce(ctx context.Context, dsn string, connected func()) error {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = conn.Close(closeCtx)
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{b.channel}.Sanitize()); err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	connected()
+	b.log.WithField("channel", b.channel).Info("realtime: listening for events")
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("wait for notification: %w", err)
+		}
+		var env envelope
+		if err := json.Unmarshal([]byte(n.Payload), &env); err != nil {
+			b.log.WithError(err).Warn("realtime: ignoring malformed notification")
+			continue
+		}
+		env.Event.Recipients = env.Recipients
+		env.Event.Broadcast = env.Broadcast
+		b.Deliver(env.Event)
+	}
+}
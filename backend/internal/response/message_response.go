@@ -20,6 +20,22 @@ type MessageListResponse struct {
 	Pagination PageMeta         `json:"pagination"`
 }
 
+type MessageCursors struct {
+	// ID of the oldest message returned, to pass as before for the previous page
+	Before *uint64 `json:"before" example:"120"`
+	// ID of the newest message returned, to pass as after for the next page
+	After *uint64 `json:"after" example:"139"`
+	// Whether older messages exist
+	HasBefore bool `json:"has_before" example:"true"`
+	// Whether newer messages exist
+	HasAfter bool `json:"has_after" example:"false"`
+}
+
+type MessageCursorListResponse struct {
+	Data    []models.Message `json:"data"`
+	Cursors MessageCursors   `json:"cursors"`
+}
+
 type ConversationWithUnreadCountResponse struct {
 	Data        models.Conversation `json:"data"`
 	UnreadCount int                 `json:"unread_count"`
@@ -29,3 +45,33 @@ type ConversationsWithUnreadResponse struct {
 	Data       []ConversationWithUnreadCountResponse `json:"data"`
 	Pagination PageMeta                              `json:"pagination"`
 }
+
+type ConversationParticipantObjectResponse struct {
+	Data models.ConversationParticipant `json:"data"`
+}
+
+type UserBlockObjectResponse struct {
+	Data models.UserBlock `json:"data"`
+}
+
+type UserBlockListResponse struct {
+	Data []models.UserBlock `json:"data"`
+}
+
+type MessageAttachmentObjectResponse struct {
+	Data models.MessageAttachment `json:"data"`
+}
+
+type MessageSearchHit struct {
+	Conversation models.Conversation `json:"conversation"`
+	Message      models.Message      `json:"message"`
+	// Matching fragments, HTML-escaped, with matches wrapped in <mark>
+	Snippet string `json:"snippet" example:"Here is our <mark>pitch</mark> deck"`
+	// Relevance, higher is better
+	Rank float64 `json:"rank" example:"0.0607927"`
+}
+
+type MessageSearchResponse struct {
+	Data       []MessageSearchHit `json:"data"`
+	Pagination PageMeta           `json:"pagination"`
+}
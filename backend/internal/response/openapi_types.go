@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database/models"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/features"
 )
 
 type MessageResponse struct {
@@ -77,6 +78,27 @@ type StartupListResponse struct {
 	Pagination PageMeta         `json:"pagination"`
 }
 
+type StartupSearchHit struct {
+	Startup models.Startup `json:"startup"`
+	// Matching fragment of the description, HTML-escaped, with matches wrapped in <mark>; empty without q
+	Snippet string `json:"snippet" example:"AI <mark>platform</mark> for SMBs"`
+	// Relevance, higher is better; 0 without q
+	Rank float64 `json:"rank" example:"0.4607927"`
+}
+
+// StartupSearchFacets counts the matching startups per value of each facet
+type StartupSearchFacets struct {
+	Sector        map[string]int64 `json:"sector" example:"tech:12,health:4"`
+	Maturity      map[string]int64 `json:"maturity" example:"early:9,middle:7"`
+	ProjectStatus map[string]int64 `json:"project_status" example:"ongoing:14,completed:2"`
+}
+
+type StartupSearchResponse struct {
+	Data       []StartupSearchHit  `json:"data"`
+	Facets     StartupSearchFacets `json:"facets"`
+	Pagination PageMeta            `json:"pagination"`
+}
+
 type UserObjectResponse struct {
 	Data models.User `json:"data"`
 }
@@ -84,3 +106,157 @@ type UserListResponse struct {
 	Data       []models.User `json:"data"`
 	Pagination PageMeta      `json:"pagination"`
 }
+
+type UserDirectoryResponse struct {
+	// Full users for viewers holding users.read, profiles otherwise
+	Data       []models.UserProfile `json:"data"`
+	Pagination PageMeta             `json:"pagination"`
+}
+
+type FeaturesResponse struct {
+	// Whether each feature is on
+	Data map[string]bool `json:"data" example:"messaging:true,opportunities:true,favorites:false"`
+}
+type FeatureFlagObjectResponse struct {
+	Data features.Flag `json:"data"`
+}
+type FeatureFlagListResponse struct {
+	Data []features.Flag `json:"data"`
+}
+
+type NotificationPreferences struct {
+	// How often unread messages are emailed
+	MessageDigest string `json:"message_digest" enums:"immediate,daily,never" example:"daily"`
+}
+type NotificationPreferencesResponse struct {
+	Data NotificationPreferences `json:"data"`
+}
+
+type RoleObjectResponse struct {
+	Data models.Role `json:"data"`
+}
+type RoleListResponse struct {
+	Data []models.Role `json:"data"`
+}
+type PermissionListResponse struct {
+	Data []models.Permission `json:"data"`
+}
+
+type AuditEventListResponse struct {
+	Data       []models.AuditEvent `json:"data"`
+	Pagination PageMeta            `json:"pagination"`
+}
+
+type ImpersonationData struct {
+	User           models.User `json:"user"`
+	ImpersonatorID uint64      `json:"impersonator_id" example:"1"`
+	ExpiresAt      time.Time   `json:"expires_at" format:"date-time"`
+	ReadOnly       bool        `json:"read_only" example:"true"`
+}
+type ImpersonationResponse struct {
+	Data ImpersonationData `json:"data"`
+}
+type ImpersonationStopResponse struct {
+	Message  string `json:"message" example:"impersonation stopped"`
+	Restored bool   `json:"restored" example:"true"`
+}
+
+type AccountDeletionResponse struct {
+	Message             string    `json:"message" example:"account deletion scheduled"`
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at" format:"date-time"`
+}
+
+type CSRFTokenResponse struct {
+	CSRFToken string `json:"csrf_token" example:"q2V0...Xw.9fJc...kQ"`
+}
+
+type PasswordPolicyData struct {
+	MinLength           int  `json:"min_length" example:"10"`
+	MaxLength           int  `json:"max_length" example:"72"`
+	MinCharacterClasses int  `json:"min_character_classes" example:"3"`
+	BreachedCheck       bool `json:"breached_check" example:"true"`
+}
+
+type PasswordPolicyResponse struct {
+	Data PasswordPolicyData `json:"data"`
+}
+
+type InvitationObjectResponse struct {
+	Data models.Invitation `json:"data"`
+}
+
+type InvitationListResponse struct {
+	Data       []models.Invitation `json:"data"`
+	Pagination PageMeta            `json:"pagination"`
+}
+
+type InvitationLookup struct {
+	Email         string    `json:"email" format:"email" example:"jane@startup.tld"`
+	Role          string    `json:"role" example:"founder"`
+	StartupID     *uint64   `json:"startup_id,omitempty" example:"3"`
+	StartupName   string    `json:"startup_name,omitempty" example:"Acme Robotics"`
+	ExpiresAt     time.Time `json:"expires_at" format:"date-time"`
+	AccountExists bool      `json:"account_exists" example:"false"`
+}
+
+type InvitationLookupResponse struct {
+	Data InvitationLookup `json:"data"`
+}
+
+type InvitationAcceptResponse struct {
+	User       models.User       `json:"user"`
+	Invitation models.Invitation `json:"invitation"`
+}
+
+type MessageReportObjectResponse struct {
+	Data models.MessageReport `json:"data"`
+}
+
+type MessageReportListResponse struct {
+	Data       []models.MessageReport `json:"data"`
+	Pagination PageMeta               `json:"pagination"`
+}
+
+type MessageModeration struct {
+	Message models.Message         `json:"message"`
+	Edits   []models.MessageEdit   `json:"edits"`
+	Reports []models.MessageReport `json:"reports"`
+}
+
+type MessageModerationResponse struct {
+	Data MessageModeration `json:"data"`
+}
+
+type FavoriteObjectResponse struct {
+	Data models.Favorite `json:"data"`
+}
+
+type FavoriteListResponse struct {
+	Data       []models.Favorite `json:"data"`
+	Pagination PageMeta          `json:"pagination"`
+}
+
+type SearchHit struct {
+	// Kind of entity
+	Type string `json:"type" enums:"startup,investor,partner,news,event,opportunity" example:"startup"`
+	ID   uint64 `json:"id" example:"1"`
+	// Name or title of the entity
+	Title string `json:"title" example:"Acme"`
+	// Matching fragment, HTML-escaped, with matches wrapped in <mark>
+	Snippet string `json:"snippet" example:"AI <mark>platform</mark> for SMBs"`
+	// Relevance, higher is better
+	Rank float64 `json:"rank" example:"0.4607927"`
+	// The startup, investor, partner, news item, event or opportunity
+	Item interface{} `json:"item" swaggertype:"object"`
+}
+
+// SearchGroup holds the results of one kind of entity, paginated on their own
+type SearchGroup struct {
+	Type       string      `json:"type" enums:"startup,investor,partner,news,event,opportunity" example:"startup"`
+	Data       []SearchHit `json:"data"`
+	Pagination PageMeta    `json:"pagination"`
+}
+
+type SearchResponse struct {
+	Data []SearchGroup `json:"data"`
+}
@@ -35,7 +35,13 @@ type StatisticsResponse struct {
 	TotalViews            int64   `json:"total_views" example:"4200"`
 	ViewsGrowthPercent    float64 `json:"views_growth_percent" example:"34.5"`
 	EngagementRatePercent float64 `json:"engagement_rate_percent" example:"12.3"`
-	Period                string  `json:"period" example:"weekly"`
+	// Favorites across startups, events and opportunities
+	TotalFavorites int64 `json:"total_favorites" example:"87"`
+	// Favorites added during the period
+	FavoritesGrowth int64 `json:"favorites_growth" example:"9"`
+	// Favorites per kind of entity
+	FavoritesByType map[string]int64 `json:"favorites_by_type" example:"startup:60,event:20,opportunity:7"`
+	Period          string           `json:"period" example:"weekly"`
 }
 
 type TopProject struct {
@@ -0,0 +1,112 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Types of the documents in the global search index
+const (
+	TypeStartup     = "startup"
+	TypeInvestor    = "investor"
+	TypePartner     = "partner"
+	TypeNews        = "news"
+	TypeEvent       = "event"
+	TypeOpportunity = "opportunity"
+)
+
+// Types lists every document type, in the order results are grouped when they rank equally
+var Types = []string{TypeStartup, TypeInvestor, TypePartner, TypeNews, TypeEvent, TypeOpportunity}
+
+// ParseTypes reads a comma-separated list of document types, all of which must be allowed. An empty list means
+// every allowed type
+func ParseTypes(raw string, allowed []string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return slices.Clone(allowed), nil
+	}
+	types := make([]string, 0, len(allowed))
+	for _, t := range strings.Split(raw, ",") {
+		t = strings.TrimSpace(t)
+		if !slices.Contains(allowed, t) {
+			return nil, fmt.Errorf("unknown type %q, expected one of %s", t, strings.Join(allowed, ", "))
+		}
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	return types, nil
+}
+
+// Hit is a document of the global search index matching a query
+type Hit struct {
+	EntityType string
+	EntityID   uint64
+	Title      string
+	Rank       float64
+	// ts_headline fragment, to be passed to Highlight
+	Snippet string
+}
+
+// matchClause selects the documents matching @q, through full-text search or, to tolerate typos, title trigrams
+const matchClause = "d.entity_type IN @types AND (d.search_vector @@ query OR d.title % @q)"
+
+// Documents returns, for each of types, the given page of the documents best matching q, best matches first
+func Documents(ctx context.Context, db *gorm.DB, q string, types []string, page, perPage int) ([]Hit, error) {
+	var hits []Hit
+	err := db.WithContext(ctx).Raw(`
+SELECT r.entity_type, r.entity_id, r.title, r.rank,
+       ts_headline('simple', CASE WHEN r.body = '' THEN r.title ELSE r.body END, query, @options) AS snippet
+FROM (
+    SELECT d.entity_type, d.entity_id, d.title, d.body,
+           ts_rank(d.search_vector, query) + similarity(d.title, @q) AS rank,
+           ROW_NUMBER() OVER (
+               PARTITION BY d.entity_type
+               ORDER BY ts_rank(d.search_vector, query) + similarity(d.title, @q) DESC, d.entity_id DESC
+           ) AS position
+    FROM search_documents d
+    CROSS JOIN websearch_to_tsquery('simple', @q) query
+    WHERE `+matchClause+`
+) r
+CROSS JOIN websearch_to_tsquery('simple', @q) query
+WHERE r.position > @from AND r.position <= @to
+ORDER BY r.rank DESC, r.entity_id DESC`, map[string]interface{}{
+		"q":       q,
+		"types":   types,
+		"options": HeadlineOptions,
+		"from":    (page - 1) * perPage,
+		"to":      page * perPage,
+	}).Scan(&hits).Error
+	if err != nil {
+		return nil, fmt.Errorf("search documents: %w", err)
+	}
+	return hits, nil
+}
+
+// Counts returns how many documents of each of types match q
+func Counts(ctx context.Context, db *gorm.DB, q string, types []string) (map[string]int64, error) {
+	var rows []struct {
+		EntityType string
+		Total      int64
+	}
+	err := db.WithContext(ctx).Raw(`
+SELECT d.entity_type, COUNT(*) AS total
+FROM search_documents d
+CROSS JOIN websearch_to_tsquery('simple', @q) query
+WHERE `+matchClause+`
+GROUP BY d.entity_type`, map[string]interface{}{"q": q, "types": types}).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("count search documents: %w", err)
+	}
+	counts := make(map[string]int64, len(types))
+	for _, t := range types {
+		counts[t] = 0
+	}
+	for _, row := range rows {
+		counts[row.EntityType] = row.Total
+	}
+	return counts, nil
+}
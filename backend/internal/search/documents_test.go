@@ -0,0 +1,24 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTypes(t *testing.T) {
+	types, err := search.ParseTypes(" ", search.Types)
+	require.NoError(t, err)
+	assert.Equal(t, search.Types, types)
+
+	types, err = search.ParseTypes("event, startup,event", search.Types)
+	require.NoError(t, err)
+	assert.Equal(t, []string{search.TypeEvent, search.TypeStartup}, types)
+
+	_, err = search.ParseTypes("startup,users", search.Types)
+	assert.ErrorContains(t, err, `unknown type "users"`)
+	_, err = search.ParseTypes("opportunity", []string{search.TypeStartup})
+	assert.Error(t, err)
+}
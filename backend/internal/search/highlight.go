@@ -0,0 +1,28 @@
+// Package search holds the helpers shared by the Postgres full-text search endpoints and the queries of the global search index
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// Private-use characters delimit the matches in ts_headline output, so that they cannot be confused with user content
+const (
+	startSel = "\uE000"
+	stopSel  = "\uE001"
+)
+
+// HeadlineOptions are the ts_headline options to use with Highlight
+const HeadlineOptions = "StartSel=" + startSel + ", StopSel=" + stopSel + ", MaxWords=25, MinWords=8, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// Highlight turns a ts_headline fragment produced with HeadlineOptions into HTML-safe text where matches are wrapped in <mark>
+func Highlight(headline string) string {
+	escaped := html.EscapeString(headline)
+	return strings.NewReplacer(startSel, "<mark>", stopSel, "</mark>").Replace(escaped)
+}
+
+// ValidQuery reports whether q is worth sending to the full-text search
+func ValidQuery(q string) bool {
+	n := len([]rune(strings.TrimSpace(q)))
+	return n >= 2 && n <= 200
+}
@@ -0,0 +1,21 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlight(t *testing.T) {
+	headline := "our \uE000pitch\uE001 deck <script>alert(1)</script> & \uE000pitch\uE001"
+	assert.Equal(t, "our <mark>pitch</mark> deck &lt;script&gt;alert(1)&lt;/script&gt; &amp; <mark>pitch</mark>", search.Highlight(headline))
+	assert.Contains(t, search.HeadlineOptions, "StartSel=\uE000")
+}
+
+func TestValidQuery(t *testing.T) {
+	assert.False(t, search.ValidQuery(" a "))
+	assert.True(t, search.ValidQuery("é!"))
+	assert.True(t, search.ValidQuery("seed round"))
+	assert.False(t, search.ValidQuery(string(make([]rune, 201))))
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/accounts"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/attachments"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/features"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/notifications/digest"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/notifications/inapp"
+)
+
+// initJobs schedules the maintenance jobs through the sync scheduler, next to the JEB jobs, so that they
+// share its panic recovery and never overlap with a slow previous run
+func (h *HTTPServer) initJobs() {
+	if h.db == nil || h.sched == nil {
+		h.log.Warn("jobs disabled: database not connected")
+		return
+	}
+
+	if spec := h.cfg.Auth.AccountDeletion.PurgeCron; spec != "" {
+		if _, err := h.sched.Schedule(spec, func(ctx context.Context) {
+			n, err := accounts.PurgeDue(ctx, h.db, time.Now().UTC())
+			if err != nil {
+				h.log.WithError(err).WithField("count", n).Error("account purge failed")
+				return
+			}
+			if n > 0 {
+				h.log.WithField("count", n).Info("deleted accounts anonymized")
+			}
+		}, "account_purge"); err != nil {
+			h.log.WithError(err).Warn("failed to schedule account purge")
+		} else {
+			h.log.WithField("spec", spec).Info("scheduled account purge")
+		}
+	}
+
+	if spec := h.cfg.Notifications.DeadlineReminders.Cron; spec != "" && h.cfg.Notifications.InApp && h.broker != nil {
+		lead := h.cfg.Notifications.DeadlineReminders.Lead
+		if lead <= 0 {
+			lead = 72 * time.Hour
+		}
+		if _, err := h.sched.Schedule(spec, func(ctx context.Context) {
+			if !h.flags.Enabled(ctx, features.Opportunities) {
+				return
+			}
+			n, err := inapp.RemindDeadlines(ctx, h.db, h.broker, time.Now().UTC(), lead)
+			if err != nil {
+				h.log.WithError(err).WithField("count", n).Error("opportunity deadline reminders failed")
+				return
+			}
+			if n > 0 {
+				h.log.WithField("count", n).Info("opportunity deadlines announced")
+			}
+		}, "deadline_reminders"); err != nil {
+			h.log.WithError(err).Warn("failed to schedule opportunity deadline reminders")
+		} else {
+			h.log.WithField("spec", spec).Info("scheduled opportunity deadline reminders")
+		}
+	}
+
+	if spec := h.cfg.Messaging.Attachments.PurgeCron; spec != "" && h.media != nil {
+		after := attachments.NewPolicy(h.cfg.Messaging.Attachments).PurgeAfter
+		if _, err := h.sched.Schedule(spec, func(ctx context.Context) {
+			n, err := attachments.PurgeOrphans(ctx, h.db, h.media, time.Now().UTC().Add(-after))
+			if err != nil {
+				h.log.WithError(err).WithField("count", n).Error("attachment purge failed")
+				return
+			}
+			if n > 0 {
+				h.log.WithField("count", n).Info("unsent attachments removed")
+			}
+		}, "attachment_purge"); err != nil {
+			h.log.WithError(err).Warn("failed to schedule attachment purge")
+		} else {
+			h.log.WithField("spec", spec).Info("scheduled attachment purge")
+		}
+	}
+
+	h.scheduleMessageDigest()
+}
+
+// scheduleMessageDigest emails unread messages through the sync scheduler, next to the JEB jobs
+func (h *HTTPServer) scheduleMessageDigest() {
+	cfg := h.cfg.Notifications.MessageDigest
+	if cfg.Cron == "" || h.mailer == nil {
+		return
+	}
+	baseURL := strings.TrimRight(h.cfg.App.BaseURL, "/")
+	opts := digest.Options{
+		Delay:          cfg.Delay,
+		BaseURL:        baseURL,
+		UnsubscribeURL: fmt.Sprintf("%s/api/%s/notifications/unsubscribe", baseURL, h.cfg.App.Version),
+		Secret:         h.cfg.Auth.JWT.Secret,
+	}
+	if opts.Delay <= 0 {
+		opts.Delay = 30 * time.Minute
+	}
+	if _, err := h.sched.Schedule(cfg.Cron, func(ctx context.Context) {
+		if !h.flags.Enabled(ctx, features.Messaging) {
+			return
+		}
+		n, err := digest.Send(ctx, h.db, h.mailer, opts, time.Now().UTC())
+		if err != nil {
+			h.log.WithError(err).WithField("count", n).Error("message digests failed")
+			return
+		}
+		if n > 0 {
+			h.log.WithField("count", n).Info("message digests sent")
+		}
+	}, "message_digest"); err != nil {
+		h.log.WithError(err).Warn("failed to schedule message digests")
+	} else {
+		h.log.WithField("spec", cfg.Cron).Info("scheduled message digests")
+	}
+}
@@ -0,0 +1,18 @@
+package server
+
+import (
+	"context"
+
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
+)
+
+// initRealtime sets up the broker pushing conversation events to WebSocket clients
+func (h *HTTPServer) initRealtime() {
+	broker, err := realtime.New(context.Background(), h.cfg, h.db, h.log)
+	if err != nil {
+		h.log.WithError(err).Warn("realtime disabled")
+		return
+	}
+	h.broker = broker
+	h.log.WithField("broker", h.cfg.Realtime.Broker).Info("realtime broker initialized")
+}
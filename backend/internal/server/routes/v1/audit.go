@@ -0,0 +1,20 @@
+package v1
+
+import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func RegisterAudit(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logger *logrus.Logger) {
+	h := v1handlers.NewAuditHandler(logger, db)
+
+	admin := r.Group("/admin/audit")
+	admin.Use(middleware.AuthRequired(cfg), middleware.RequirePermission(db, auth.PermAuditRead))
+	admin.GET("", h.ListAuditEvents)
+	admin.GET("/export", h.ExportAuditEvents)
+}
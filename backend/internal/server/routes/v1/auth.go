@@ -1,24 +1,61 @@
 package v1
 
 import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/audit"
+	authz "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/password"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
 	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/notifications/email"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
-func RegisterAuth(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logger *logrus.Logger, mailer email.Mailer) {
-	h := v1handlers.NewAuthHandler(cfg, db, logger, mailer)
+func RegisterAuth(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logger *logrus.Logger, mailer email.Mailer, passwords *password.Policy) {
+	h := v1handlers.NewAuthHandler(cfg, db, logger, mailer, passwords)
 	auth := r.Group("/auth")
 	auth.POST("/register", h.Register)
 	auth.POST("/login", h.Login)
 	auth.POST("/refresh", h.Refresh)
 	auth.POST("/logout", h.Logout)
+	auth.GET("/csrf", h.CSRFToken)
 
 	auth.POST("/verify", h.VerifyEmail)
 	auth.GET("/verify", h.VerifyEmail)
 	auth.POST("/forgot-password", h.ForgotPassword)
 	auth.POST("/reset-password", h.ResetPassword)
+	auth.GET("/password-policy", h.PasswordPolicy)
+	auth.POST("/magic-link", h.RequestMagicLink)
+	auth.GET("/magic-link/consume", h.ConsumeMagicLink)
+	auth.GET("/email-change/confirm", h.ConfirmEmailChange)
+	auth.POST("/email-change/confirm", h.ConfirmEmailChange)
+
+	auth.GET("/oauth/providers", h.OAuthProviders)
+	auth.GET("/oauth/:provider/login", h.OAuthStart)
+	auth.GET("/oauth/:provider/callback", h.OAuthCallback)
+
+	auth.POST("/impersonation/stop", middleware.ImpersonationAuth(cfg), middleware.AuditAction(cfg, db, logger, "user.impersonate_stop", "user"), h.StopImpersonation)
+
+	invitations := r.Group("/invitations")
+	invitations.GET("/lookup", h.LookupInvitation)
+	invitations.POST("/accept", middleware.AuthOptional(cfg), h.AcceptInvitation)
+	invitations.GET("", middleware.AuthRequired(cfg), h.ListInvitations)
+	invitations.POST("", middleware.AuthRequired(cfg), middleware.Audit(cfg, db, logger, "invitation", audit.TableSnapshot("invitations")), h.CreateInvitation)
+	invitations.DELETE("/:id", middleware.AuthRequired(cfg), middleware.Audit(cfg, db, logger, "invitation", audit.TableSnapshot("invitations")), h.RevokeInvitation)
+
+	me := r.Group("/users/me")
+	me.Use(middleware.AuthRequired(cfg))
+	me.GET("/identities", h.ListIdentities)
+	me.GET("/identities/:provider/link", h.LinkIdentity)
+	me.DELETE("/identities/:provider", h.UnlinkIdentity)
+	me.POST("/email", h.RequestEmailChange)
+	me.POST("/password", h.ChangePassword)
+	me.DELETE("", h.DeleteMe)
+	me.POST("/deletion/cancel", h.CancelDeletion)
+
+	admin := r.Group("/admin/users")
+	admin.Use(middleware.AuthRequired(cfg))
+	admin.POST("/:id/impersonate", middleware.RequirePermission(db, authz.PermUsersImpersonate), middleware.AuditAction(cfg, db, logger, "user.impersonate_start", "user"), h.StartImpersonation)
 }
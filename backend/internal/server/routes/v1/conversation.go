@@ -1,25 +1,56 @@
 package v1
 
 import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
 	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/storage/s3"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
-func RegisterConversations(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logger *logrus.Logger) {
-	h := v1handlers.NewConversationsHandler(db, logger)
+func RegisterConversations(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logger *logrus.Logger, broker realtime.Broker, media s3.ObjectStore) {
+	h := v1handlers.NewConversationsHandler(cfg, db, logger, broker, media)
 
 	conversations := r.Group("/conversations")
 	conversations.Use(middleware.AuthRequired(cfg))
 	{
 		conversations.GET("", h.GetConversations)
 		conversations.POST("", h.CreateConversation)
+		conversations.GET("/ws", h.Stream)
+		conversations.GET("/search", h.SearchMessages)
 		conversations.GET("/:id", h.GetConversation)
+		conversations.PATCH("/:id", h.UpdateConversation)
+		conversations.POST("/:id/participants", h.AddParticipants)
+		conversations.DELETE("/:id/participants/:uid", h.RemoveParticipant)
+		conversations.POST("/:id/owner", h.TransferOwnership)
+		conversations.POST("/:id/leave", h.LeaveConversation)
 		conversations.GET("/:id/messages", h.GetMessages)
 		conversations.POST("/:id/messages", h.SendMessage)
+		conversations.POST("/:id/attachments", h.UploadAttachment)
+		conversations.GET("/:id/attachments/:aid", h.DownloadAttachment)
+		conversations.PATCH("/:id/messages/:mid", h.EditMessage)
+		conversations.DELETE("/:id/messages/:mid", h.DeleteMessage)
+		conversations.POST("/:id/messages/:mid/report", h.ReportMessage)
 		conversations.POST("/:id/mark-read", h.MarkMessageRead)
+		conversations.POST("/:id/mute", h.MuteConversation)
+		conversations.DELETE("/:id/mute", h.UnmuteConversation)
 	}
+
+	blocks := r.Group("/users/me/blocks")
+	blocks.Use(middleware.AuthRequired(cfg))
+	blocks.GET("", h.ListBlocks)
+	blocks.POST("", h.BlockUser)
+	blocks.DELETE("/:uid", h.UnblockUser)
+
+	admin := r.Group("/admin")
+	admin.Use(middleware.AuthRequired(cfg), middleware.RequirePermission(db, auth.PermMessagesModerate))
+	admin.GET("/message-reports", h.ListMessageReports)
+	admin.POST("/message-reports/:id/resolve", middleware.AuditAction(cfg, db, logger, "message_report.resolve", "message_report"), h.ResolveMessageReport)
+	admin.GET("/messages/:id", h.GetMessageModeration)
+	admin.POST("/messages/:id/hide", middleware.AuditAction(cfg, db, logger, "message.hide", "message"), h.HideMessage)
+	admin.POST("/messages/:id/restore", middleware.AuditAction(cfg, db, logger, "message.restore", "message"), h.RestoreMessage)
 }
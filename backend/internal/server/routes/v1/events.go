@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/audit"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
 	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
@@ -14,12 +16,12 @@ func RegisterEvents(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logger
 	h := v1handlers.NewEventsHandler(logger, db, uploader)
 
 	events := r.Group("/events")
-	events.GET("", h.GetEvents)
-	events.GET("/:id", h.GetEvent)
+	events.GET("", middleware.AuthOptional(cfg), h.GetEvents)
+	events.GET("/:id", middleware.AuthOptional(cfg), h.GetEvent)
 
 	admin := r.Group("/admin/events")
-	admin.Use(middleware.AuthRequired(cfg), middleware.RequireAdmin())
-	admin.POST("", h.CreateEvent)
-	admin.PATCH("/:id", h.UpdateEvent)
-	admin.DELETE("/:id", h.DeleteEvent)
+	admin.Use(middleware.AuthRequired(cfg), middleware.Audit(cfg, db, logger, "event", audit.TableSnapshot("events")))
+	admin.POST("", middleware.RequirePermission(db, auth.PermEventsCreate), h.CreateEvent)
+	admin.PATCH("/:id", middleware.RequirePermission(db, auth.PermEventsUpdate), h.UpdateEvent)
+	admin.DELETE("/:id", middleware.RequirePermission(db, auth.PermEventsDelete), h.DeleteEvent)
 }
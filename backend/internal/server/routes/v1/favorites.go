@@ -0,0 +1,20 @@
+package v1
+
+import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func RegisterFavorites(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logger *logrus.Logger) {
+	h := v1handlers.NewFavoritesHandler(db, logger)
+
+	favorites := r.Group("/me/favorites")
+	favorites.Use(middleware.AuthRequired(cfg))
+	favorites.GET("", h.ListFavorites)
+	favorites.POST("/:type/:id", h.AddFavorite)
+	favorites.DELETE("/:type/:id", h.RemoveFavorite)
+}
@@ -0,0 +1,24 @@
+package v1
+
+import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/features"
+	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func RegisterFeatures(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logger *logrus.Logger, flags *features.Store) {
+	h := v1handlers.NewFeaturesHandler(logger, flags)
+
+	r.GET("/features", h.GetFeatures)
+
+	admin := r.Group("/admin/features")
+	admin.Use(middleware.AuthRequired(cfg), middleware.RequirePermission(db, auth.PermFeaturesManage))
+	admin.GET("", h.ListFeatureFlags)
+	admin.PUT("/:id", middleware.AuditAction(cfg, db, logger, "feature.update", "feature"), h.SetFeatureFlag)
+	admin.DELETE("/:id", middleware.AuditAction(cfg, db, logger, "feature.reset", "feature"), h.ResetFeatureFlag)
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/audit"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
 	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
@@ -17,8 +19,8 @@ func RegisterInvestors(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logg
 	investors.GET("/:id", h.GetInvestor)
 
 	admin := r.Group("/admin/investors")
-	admin.Use(middleware.AuthRequired(cfg), middleware.RequireAdmin())
-	admin.POST("", h.CreateInvestor)
-	admin.PATCH("/:id", h.UpdateInvestor)
-	admin.DELETE("/:id", h.DeleteInvestor)
+	admin.Use(middleware.AuthRequired(cfg), middleware.Audit(cfg, db, logger, "investor", audit.TableSnapshot("investors")))
+	admin.POST("", middleware.RequirePermission(db, auth.PermInvestorsCreate), h.CreateInvestor)
+	admin.PATCH("/:id", middleware.RequirePermission(db, auth.PermInvestorsUpdate), h.UpdateInvestor)
+	admin.DELETE("/:id", middleware.RequirePermission(db, auth.PermInvestorsDelete), h.DeleteInvestor)
 }
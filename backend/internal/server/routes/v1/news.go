@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/audit"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
 	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
@@ -19,8 +21,8 @@ func RegisterNews(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logger *l
 
 	// Admin
 	admin := r.Group("/admin/news")
-	admin.Use(middleware.AuthRequired(cfg), middleware.RequireAdmin())
-	admin.POST("", h.CreateNews)
-	admin.PATCH("/:id", h.UpdateNews)
-	admin.DELETE("/:id", h.DeleteNews)
+	admin.Use(middleware.AuthRequired(cfg), middleware.Audit(cfg, db, logger, "news", audit.TableSnapshot("news")))
+	admin.POST("", middleware.RequirePermission(db, auth.PermNewsCreate), h.CreateNews)
+	admin.PATCH("/:id", middleware.RequirePermission(db, auth.PermNewsUpdate), h.UpdateNews)
+	admin.DELETE("/:id", middleware.RequirePermission(db, auth.PermNewsDelete), h.DeleteNews)
 }
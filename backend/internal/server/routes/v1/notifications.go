@@ -0,0 +1,23 @@
+package v1
+
+import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func RegisterNotifications(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logger *logrus.Logger, broker realtime.Broker) {
+	h := v1handlers.NewNotificationsHandler(cfg, db, logger, broker)
+
+	notifications := r.Group("/notifications")
+	notifications.GET("/stream", middleware.AuthRequired(cfg), h.Stream)
+	notifications.GET("/preferences", middleware.AuthRequired(cfg), h.GetPreferences)
+	notifications.PATCH("/preferences", middleware.AuthRequired(cfg), h.UpdatePreferences)
+	// Linked from emails: no session, the token identifies the user
+	notifications.GET("/unsubscribe", h.Unsubscribe)
+	notifications.POST("/unsubscribe", h.Unsubscribe)
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/audit"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
 	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
@@ -13,17 +15,18 @@ func RegisterOpportunities(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB,
 	opportunityHandler := v1handlers.NewOpportunityHandler(logger, db)
 
 	opportunities := r.Group("/opportunities")
-	opportunities.GET("", opportunityHandler.GetOpportunities)
-	opportunities.GET("/:id", opportunityHandler.GetOpportunity)
+	opportunities.GET("", middleware.AuthOptional(cfg), opportunityHandler.GetOpportunities)
+	opportunities.GET("/:id", middleware.AuthOptional(cfg), opportunityHandler.GetOpportunity)
 
 	admin := r.Group("/admin")
-	admin.Use(middleware.AuthRequired(cfg), middleware.RequireAdmin())
+	admin.Use(middleware.AuthRequired(cfg))
 	{
 		adminOpportunities := admin.Group("/opportunities")
-		adminOpportunities.POST("", opportunityHandler.CreateOpportunity)
-		adminOpportunities.PATCH("/:id", opportunityHandler.UpdateOpportunity)
-		adminOpportunities.GET("", opportunityHandler.GetOpportunities)
-		adminOpportunities.GET("/:id", opportunityHandler.GetOpportunity)
-		adminOpportunities.DELETE("/:id", opportunityHandler.DeleteOpportunity)
+		adminOpportunities.Use(middleware.Audit(cfg, db, logger, "opportunity", audit.TableSnapshot("opportunities")))
+		adminOpportunities.POST("", middleware.RequirePermission(db, auth.PermOpportunitiesCreate), opportunityHandler.CreateOpportunity)
+		adminOpportunities.PATCH("/:id", middleware.RequirePermission(db, auth.PermOpportunitiesUpdate), opportunityHandler.UpdateOpportunity)
+		adminOpportunities.GET("", middleware.RequirePermission(db, auth.PermOpportunitiesRead), opportunityHandler.GetOpportunities)
+		adminOpportunities.GET("/:id", middleware.RequirePermission(db, auth.PermOpportunitiesRead), opportunityHandler.GetOpportunity)
+		adminOpportunities.DELETE("/:id", middleware.RequirePermission(db, auth.PermOpportunitiesDelete), opportunityHandler.DeleteOpportunity)
 	}
 }
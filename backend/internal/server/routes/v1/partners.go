@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/audit"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
 	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
@@ -17,8 +19,8 @@ func RegisterPartners(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logge
 	partners.GET("/:id", h.GetPartner)
 
 	admin := r.Group("/admin/partners")
-	admin.Use(middleware.AuthRequired(cfg), middleware.RequireAdmin())
-	admin.POST("", h.CreatePartner)
-	admin.PATCH("/:id", h.UpdatePartner)
-	admin.DELETE("/:id", h.DeletePartner)
+	admin.Use(middleware.AuthRequired(cfg), middleware.Audit(cfg, db, logger, "partner", audit.TableSnapshot("partners")))
+	admin.POST("", middleware.RequirePermission(db, auth.PermPartnersCreate), h.CreatePartner)
+	admin.PATCH("/:id", middleware.RequirePermission(db, auth.PermPartnersUpdate), h.UpdatePartner)
+	admin.DELETE("/:id", middleware.RequirePermission(db, auth.PermPartnersDelete), h.DeletePartner)
 }
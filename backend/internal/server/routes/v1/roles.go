@@ -0,0 +1,28 @@
+package v1
+
+import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/audit"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func RegisterRoles(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logger *logrus.Logger) {
+	h := v1handlers.NewRolesHandler(logger, db)
+
+	admin := r.Group("/admin")
+	admin.Use(middleware.AuthRequired(cfg), middleware.RequirePermission(db, auth.PermRolesManage))
+	admin.GET("/permissions", h.ListPermissions)
+	admin.GET("/roles", h.ListRoles)
+	admin.GET("/roles/:id", h.GetRole)
+
+	roles := admin.Group("/roles")
+	roles.Use(middleware.Audit(cfg, db, logger, "role", audit.RoleSnapshot))
+	roles.POST("", h.CreateRole)
+	roles.PATCH("/:id", h.UpdateRole)
+	roles.DELETE("/:id", h.DeleteRole)
+}
@@ -0,0 +1,17 @@
+package v1
+
+import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/features"
+	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func RegisterSearch(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logger *logrus.Logger, flags *features.Store) {
+	h := v1handlers.NewSearchHandler(db, logger, flags)
+
+	r.GET("/search", middleware.AuthOptional(cfg), h.Search)
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/audit"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
 	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
@@ -13,15 +15,16 @@ func RegisterStartups(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logge
 	h := v1handlers.NewStartupsHandler(db, logger)
 
 	g := r.Group("/startups")
-	g.GET("", h.ListStartups)
-	g.GET("/:id", h.GetStartup)
+	g.GET("", middleware.AuthOptional(cfg), h.ListStartups)
+	g.GET("/search", middleware.AuthOptional(cfg), h.SearchStartups)
+	g.GET("/:id", middleware.AuthOptional(cfg), h.GetStartup)
 	g.POST("/:id/views", h.IncrementViews)
 
 	admin := r.Group("/admin/startups")
-	admin.Use(middleware.AuthRequired(cfg), middleware.RequireAdmin())
-	admin.POST("", h.CreateStartup)
-	admin.PATCH("/:id", h.UpdateStartup)
-	admin.DELETE("/:id", h.DeleteStartup)
+	admin.Use(middleware.AuthRequired(cfg), middleware.Audit(cfg, db, logger, "startup", audit.TableSnapshot("startups")))
+	admin.POST("", middleware.RequirePermission(db, auth.PermStartupsCreate), h.CreateStartup)
+	admin.PATCH("/:id", middleware.RequirePermission(db, auth.PermStartupsUpdate), h.UpdateStartup)
+	admin.DELETE("/:id", middleware.RequirePermission(db, auth.PermStartupsDelete), h.DeleteStartup)
 
 	founder := r.Group("/founder/startups")
 	founder.Use(middleware.AuthRequired(cfg), middleware.RequireFounderOfStartup(db, "id"))
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
 	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
@@ -13,7 +14,7 @@ func RegisterStatistics(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, log
 	h := v1handlers.NewStatisticsHandler(db, logger)
 
 	admin := r.Group("/admin/statistics")
-	admin.Use(middleware.AuthRequired(cfg), middleware.RequireAdmin())
+	admin.Use(middleware.AuthRequired(cfg), middleware.RequirePermission(db, auth.PermStatisticsRead))
 	admin.GET("", h.GetStatistics)
 	admin.GET("/top", h.GetTopProjects)
 }
@@ -1,6 +1,9 @@
 package v1
 
 import (
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/audit"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/password"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
 	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
@@ -10,15 +13,16 @@ import (
 	"gorm.io/gorm"
 )
 
-func RegisterUsers(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logger *logrus.Logger, uploader s3.Uploader) {
-	h := v1handlers.NewUsersHandler(db, logger, uploader)
+func RegisterUsers(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logger *logrus.Logger, uploader s3.Uploader, passwords *password.Policy) {
+	h := v1handlers.NewUsersHandler(db, logger, uploader, passwords)
 
 	users := r.Group("/users")
-	users.GET("", h.GetUsers)
+	users.GET("", middleware.AuthOptional(cfg), h.GetUsers)
 	// Register static route before param route to avoid conflicts ("me" vs ":id")
 	users.GET("/me", middleware.AuthRequired(cfg), h.GetMe)
-	users.GET("/email/:email", middleware.AuthRequired(cfg), middleware.RequireSelfOrAdminByEmailParam("email"), h.GetUserByEmail)
-	users.GET("/:id", middleware.AuthRequired(cfg), middleware.RequireSelfOrAdminByParam("id"), h.GetUser)
+	users.PATCH("/me/privacy", middleware.AuthRequired(cfg), h.UpdatePrivacy)
+	users.GET("/email/:email", middleware.AuthRequired(cfg), middleware.RequireSelfOrPermissionByEmailParam(db, "email", auth.PermUsersRead), h.GetUserByEmail)
+	users.GET("/:id", middleware.AuthRequired(cfg), middleware.RequireSelfOrPermissionByParam(db, "id", auth.PermUsersRead), h.GetUser)
 
 	// Keep legacy /me route temporarily for backward compatibility
 	me := r.Group("")
@@ -26,8 +30,8 @@ func RegisterUsers(r *gin.RouterGroup, cfg *config.Config, db *gorm.DB, logger *
 	me.GET("/me", h.GetMe)
 
 	admin := r.Group("/admin/users")
-	admin.Use(middleware.AuthRequired(cfg), middleware.RequireAdmin())
-	admin.POST("", h.CreateUser)
-	admin.PATCH("/:id", h.UpdateUser)
-	admin.DELETE("/:id", h.DeleteUser)
+	admin.Use(middleware.AuthRequired(cfg), middleware.Audit(cfg, db, logger, "user", audit.TableSnapshot("users")))
+	admin.POST("", middleware.RequirePermission(db, auth.PermUsersCreate), h.CreateUser)
+	admin.PATCH("/:id", middleware.RequirePermission(db, auth.PermUsersUpdate), h.UpdateUser)
+	admin.DELETE("/:id", middleware.RequirePermission(db, auth.PermUsersDelete), h.DeleteUser)
 }
@@ -6,11 +6,14 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth/password"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/database"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/features"
 	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/notifications/email"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/realtime"
 	v1routes "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/server/routes/v1"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/storage/s3"
 	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/sync"
@@ -27,6 +30,9 @@ type HTTPServer struct {
 	db     *gorm.DB
 	sched  sync.Scheduler
 	mailer email.Mailer
+	broker realtime.Broker
+	media  s3.ObjectStore
+	flags  *features.Store
 }
 
 func NewHTTPServer(cfg *config.Config) *HTTPServer {
@@ -51,6 +57,7 @@ func NewHTTPServer(cfg *config.Config) *HTTPServer {
 	g.Use(middleware.Recovery(logger))
 	g.Use(middleware.Logger(logger))
 	g.Use(middleware.CORS(cfg))
+	g.Use(middleware.CSRF(cfg))
 
 	var gormDB *gorm.DB
 	if db, err := database.Open(cfg, logger); err != nil {
@@ -67,8 +74,10 @@ func NewHTTPServer(cfg *config.Config) *HTTPServer {
 		db:     gormDB,
 	}
 	h.initMailer()
+	h.initRealtime()
 	h.registerRoutes()
 	h.initSync()
+	h.initJobs()
 
 	return h
 }
@@ -106,18 +115,36 @@ func (s *HTTPServer) registerRoutes() {
 	if err != nil {
 		s.log.WithError(err).Warn("failed to init S3 uploader")
 	}
+	// Attachments are disabled rather than failing at upload time when S3 is not configured
+	if uploader != nil {
+		s.media = uploader
+	}
+
+	// Shared by sign-up, password changes and admin user management so that the breached list is loaded once
+	passwords := password.NewPolicy(s.cfg.Auth.Password, s.log)
 
+	s.flags = features.New(s.cfg.Features, s.db)
+	messaging := v1.Group("", middleware.RequireFeature(s.flags, features.Messaging))
+	opportunities := v1.Group("", middleware.RequireFeature(s.flags, features.Opportunities))
+	favorites := v1.Group("", middleware.RequireFeature(s.flags, features.Favorites))
+
+	v1routes.RegisterFeatures(v1, s.cfg, s.db, s.log, s.flags)
 	v1routes.RegisterStartups(v1, s.cfg, s.db, s.log)
 	v1routes.RegisterInvestors(v1, s.cfg, s.db, s.log)
-	v1routes.RegisterUsers(v1, s.cfg, s.db, s.log, uploader)
+	v1routes.RegisterUsers(v1, s.cfg, s.db, s.log, uploader, passwords)
 	v1routes.RegisterNews(v1, s.cfg, s.db, s.log, uploader)
 	v1routes.RegisterEvents(v1, s.cfg, s.db, s.log, uploader)
-	v1routes.RegisterOpportunities(v1, s.cfg, s.db, s.log)
+	v1routes.RegisterOpportunities(opportunities, s.cfg, s.db, s.log)
 	v1routes.RegisterPartners(v1, s.cfg, s.db, s.log)
+	v1routes.RegisterRoles(v1, s.cfg, s.db, s.log)
+	v1routes.RegisterAudit(v1, s.cfg, s.db, s.log)
 	v1routes.RegisterStatistics(v1, s.cfg, s.db, s.log)
-	v1routes.RegisterAuth(v1, s.cfg, s.db, s.log, s.mailer)
-	v1routes.RegisterConversations(v1, s.cfg, s.db, s.log)
+	v1routes.RegisterAuth(v1, s.cfg, s.db, s.log, s.mailer, passwords)
+	v1routes.RegisterConversations(messaging, s.cfg, s.db, s.log, s.broker, s.media)
+	v1routes.RegisterNotifications(v1, s.cfg, s.db, s.log, s.broker)
 	v1routes.RegisterFounders(v1, s.db, s.log)
+	v1routes.RegisterFavorites(favorites, s.cfg, s.db, s.log)
+	v1routes.RegisterSearch(v1, s.cfg, s.db, s.log, s.flags)
 	v1.Group("/sectors")
 	v1.Group("/locations")
 	v1.Group("/tags")
@@ -174,5 +201,9 @@ func (s *HTTPServer) Shutdown(ctx context.Context) error {
 		_ = s.sched.Stop(shutdownCtx)
 	}
 
-	return s.http.Shutdown(shutdownCtx)
+	err := s.http.Shutdown(shutdownCtx)
+	if s.broker != nil {
+		_ = s.broker.Close()
+	}
+	return err
 }
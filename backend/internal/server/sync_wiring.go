@@ -3,8 +3,10 @@ package server
 import (
 	"context"
 
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/auth"
 	jeb "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/client/jeb"
 	v1handlers "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/handlers/v1"
+	"github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/middleware"
 	storageS3 "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/storage/s3"
 	syc "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/sync"
 )
@@ -41,10 +43,11 @@ func (h *HTTPServer) initSync() {
 	admin := v1.Group("/admin")
 	syncHandler := v1handlers.NewSyncHandler(h.log, h.sched)
 	adminSync := admin.Group("/sync")
+	adminSync.Use(middleware.AuthRequired(h.cfg))
 	{
-		adminSync.GET("/status", syncHandler.Status)
-		adminSync.POST("/full", syncHandler.TriggerFull)
-		adminSync.POST("/incremental", syncHandler.TriggerIncremental)
+		adminSync.GET("/status", middleware.RequirePermission(h.db, auth.PermSyncRead), syncHandler.Status)
+		adminSync.POST("/full", middleware.RequirePermission(h.db, auth.PermSyncTrigger), middleware.AuditAction(h.cfg, h.db, h.log, "sync.trigger_full", "sync"), syncHandler.TriggerFull)
+		adminSync.POST("/incremental", middleware.RequirePermission(h.db, auth.PermSyncTrigger), middleware.AuditAction(h.cfg, h.db, h.log, "sync.trigger_incremental", "sync"), syncHandler.TriggerIncremental)
 	}
 
 	if h.cfg.Sync.IncrementalCron != "" {
@@ -6,6 +6,7 @@ import (
 	"io"
 	"path"
 	"strings"
+	"time"
 
 	cfgpkg "github.com/Epitech-2nd-Year-Projects/survivor-seminar/internal/config"
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -17,6 +18,13 @@ type Uploader interface {
 	Upload(ctx context.Context, key string, contentType string, data []byte) (string, error)
 }
 
+// ObjectStore keeps private objects, only reachable through short-lived presigned URLs
+type ObjectStore interface {
+	Uploader
+	PresignGet(ctx context.Context, key string, ttl time.Duration, disposition string) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
 type S3Uploader struct {
 	client     *awsS3.Client
 	bucket     string
@@ -59,6 +67,32 @@ func (u *S3Uploader) Upload(ctx context.Context, key string, contentType string,
 	return u.publicBase + "/" + path.Clean(key), nil
 }
 
+// PresignGet returns a URL that downloads key for ttl. disposition, when set, overrides the Content-Disposition of the response
+func (u *S3Uploader) PresignGet(ctx context.Context, key string, ttl time.Duration, disposition string) (string, error) {
+	in := &awsS3.GetObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(key),
+	}
+	if disposition != "" {
+		in.ResponseContentDisposition = aws.String(disposition)
+	}
+	req, err := awsS3.NewPresignClient(u.client).PresignGetObject(ctx, in, awsS3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("s3 presign: %w", err)
+	}
+	return req.URL, nil
+}
+
+func (u *S3Uploader) Delete(ctx context.Context, key string) error {
+	if _, err := u.client.DeleteObject(ctx, &awsS3.DeleteObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		return fmt.Errorf("s3 delete: %w", err)
+	}
+	return nil
+}
+
 // bytesReader returns an io.ReadSeeker for []byte without extra alloc
 func bytesReader(b []byte) *byteReader { return &byteReader{b: b} }
 
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_role_permissions_permission_id;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
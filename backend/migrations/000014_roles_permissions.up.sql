@@ -0,0 +1,78 @@
+CREATE TABLE IF NOT EXISTS roles (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT,
+    is_system BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id BIGINT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id BIGINT NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_role_permissions_permission_id ON role_permissions(permission_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('*', 'Every permission'),
+    ('startups.create', 'Create startups'),
+    ('startups.update', 'Update any startup'),
+    ('startups.delete', 'Delete any startup'),
+    ('investors.create', 'Create investors'),
+    ('investors.update', 'Update investors'),
+    ('investors.delete', 'Delete investors'),
+    ('partners.create', 'Create partners'),
+    ('partners.update', 'Update partners'),
+    ('partners.delete', 'Delete partners'),
+    ('news.create', 'Create news'),
+    ('news.update', 'Update news'),
+    ('news.delete', 'Delete news'),
+    ('events.create', 'Create events'),
+    ('events.update', 'Update events'),
+    ('events.delete', 'Delete events'),
+    ('opportunities.read', 'Read opportunities in the back office'),
+    ('opportunities.create', 'Create opportunities'),
+    ('opportunities.update', 'Update opportunities'),
+    ('opportunities.delete', 'Delete opportunities'),
+    ('users.read', 'Read any user profile'),
+    ('users.create', 'Create users'),
+    ('users.update', 'Update users and assign roles'),
+    ('users.delete', 'Delete users'),
+    ('statistics.read', 'Read platform statistics'),
+    ('sync.read', 'Read synchronization status'),
+    ('sync.trigger', 'Trigger synchronizations'),
+    ('roles.manage', 'Create, update and delete roles'),
+    ('startups.*', 'Every startup permission'),
+    ('investors.*', 'Every investor permission'),
+    ('partners.*', 'Every partner permission'),
+    ('news.*', 'Every news permission'),
+    ('events.*', 'Every event permission'),
+    ('opportunities.*', 'Every opportunity permission'),
+    ('users.*', 'Every user permission'),
+    ('sync.*', 'Every synchronization permission')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO roles (name, description, is_system) VALUES
+    ('admin', 'Full access to the back office', TRUE),
+    ('founder', 'Startup founder', TRUE),
+    ('investor', 'Investor', TRUE),
+    ('user', 'Regular user', TRUE)
+ON CONFLICT (name) DO NOTHING;
+
+-- Keep roles already assigned to users (e.g. imported by the JEB sync) as custom roles without permissions
+INSERT INTO roles (name)
+SELECT DISTINCT LOWER(role) FROM users WHERE role IS NOT NULL AND role <> ''
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'admin' AND p.name = '*'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,6 @@
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS impersonator_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_audit_events_impersonator_id ON audit_events(impersonator_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users.impersonate', 'Sign in as another user for support')
+ON CONFLICT (name) DO NOTHING;
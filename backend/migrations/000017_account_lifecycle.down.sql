@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS anonymized_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_scheduled_at;
+
+DELETE FROM auth_tokens WHERE token_type NOT IN ('verify','reset');
+ALTER TABLE auth_tokens DROP CONSTRAINT IF EXISTS auth_tokens_token_type_check;
+ALTER TABLE auth_tokens ADD CONSTRAINT auth_tokens_token_type_check
+    CHECK (token_type IN ('verify','reset'));
+ALTER TABLE auth_tokens DROP COLUMN IF EXISTS payload;
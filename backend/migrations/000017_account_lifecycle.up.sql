@@ -0,0 +1,9 @@
+ALTER TABLE auth_tokens ADD COLUMN IF NOT EXISTS payload TEXT;
+ALTER TABLE auth_tokens DROP CONSTRAINT IF EXISTS auth_tokens_token_type_check;
+ALTER TABLE auth_tokens ADD CONSTRAINT auth_tokens_token_type_check
+    CHECK (token_type IN ('verify','reset','email_change'));
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at ON users(deletion_scheduled_at)
+    WHERE deletion_scheduled_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_discoverable;
+ALTER TABLE users DROP COLUMN IF EXISTS show_email;
+ALTER TABLE users DROP COLUMN IF EXISTS discoverable;
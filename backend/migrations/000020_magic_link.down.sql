@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_auth_tokens_user_type;
+DELETE FROM auth_tokens WHERE token_type = 'magic_link';
+ALTER TABLE auth_tokens DROP CONSTRAINT IF EXISTS auth_tokens_token_type_check;
+ALTER TABLE auth_tokens ADD CONSTRAINT auth_tokens_token_type_check
+    CHECK (token_type IN ('verify','reset','email_change'));
@@ -0,0 +1,4 @@
+ALTER TABLE auth_tokens DROP CONSTRAINT IF EXISTS auth_tokens_token_type_check;
+ALTER TABLE auth_tokens ADD CONSTRAINT auth_tokens_token_type_check
+    CHECK (token_type IN ('verify','reset','email_change','magic_link'));
+CREATE INDEX IF NOT EXISTS idx_auth_tokens_user_type ON auth_tokens(user_id, token_type, created_at);
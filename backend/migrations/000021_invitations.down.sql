@@ -0,0 +1,2 @@
+DELETE FROM permissions WHERE name = 'users.invite';
+DROP TABLE IF EXISTS invitations;
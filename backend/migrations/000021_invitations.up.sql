@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS invitations (
+    id           BIGSERIAL PRIMARY KEY,
+    email        VARCHAR(255) NOT NULL,
+    role         VARCHAR(50)  NOT NULL,
+    startup_id   BIGINT REFERENCES startups(id) ON DELETE CASCADE,
+    investor_id  BIGINT REFERENCES investors(id) ON DELETE SET NULL,
+    token_hash   VARCHAR(64)  NOT NULL UNIQUE,
+    invited_by   BIGINT       NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at   TIMESTAMPTZ  NOT NULL,
+    accepted_at  TIMESTAMPTZ,
+    accepted_by  BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON invitations(LOWER(email));
+CREATE INDEX IF NOT EXISTS idx_invitations_startup_id ON invitations(startup_id);
+CREATE INDEX IF NOT EXISTS idx_invitations_invited_by ON invitations(invited_by);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users.invite', 'Invite users with any role')
+ON CONFLICT (name) DO NOTHING;
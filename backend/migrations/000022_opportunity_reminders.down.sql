@@ -0,0 +1 @@
+ALTER TABLE opportunities DROP COLUMN IF EXISTS deadline_reminded_at;
@@ -0,0 +1 @@
+ALTER TABLE opportunities ADD COLUMN IF NOT EXISTS deadline_reminded_at TIMESTAMPTZ;
@@ -0,0 +1,7 @@
+DELETE FROM permissions WHERE name = 'messages.moderate';
+DROP TABLE IF EXISTS message_reports;
+DROP TABLE IF EXISTS message_edits;
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS moderation_reason,
+    DROP COLUMN IF EXISTS deleted_by,
+    DROP COLUMN IF EXISTS edited_at;
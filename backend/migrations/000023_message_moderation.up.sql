@@ -0,0 +1,35 @@
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS deleted_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS moderation_reason TEXT;
+
+CREATE TABLE IF NOT EXISTS message_edits (
+    id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    edited_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    edited_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_edits_message_id ON message_edits(message_id);
+
+CREATE TABLE IF NOT EXISTS message_reports (
+    id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    reporter_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(30) NOT NULL CHECK (reason IN ('spam', 'harassment', 'inappropriate', 'other')),
+    details TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'dismissed', 'actioned')),
+    reviewed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    resolution_note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (message_id, reporter_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_reports_message_id ON message_reports(message_id);
+CREATE INDEX IF NOT EXISTS idx_message_reports_open ON message_reports(created_at) WHERE status = 'open';
+
+INSERT INTO permissions (name, description) VALUES
+    ('messages.moderate', 'Hide messages and review message reports')
+ON CONFLICT (name) DO NOTHING;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_conversation_participants_active;
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS subject_user_id,
+    DROP COLUMN IF EXISTS event,
+    DROP COLUMN IF EXISTS kind;
+
+ALTER TABLE conversation_participants
+    DROP COLUMN IF EXISTS left_at;
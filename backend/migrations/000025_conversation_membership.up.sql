@@ -0,0 +1,9 @@
+ALTER TABLE conversation_participants
+    ADD COLUMN IF NOT EXISTS left_at TIMESTAMPTZ;
+
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'text' CHECK (kind IN ('text', 'system')),
+    ADD COLUMN IF NOT EXISTS event VARCHAR(40),
+    ADD COLUMN IF NOT EXISTS subject_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_conversation_participants_active ON conversation_participants(user_id, conversation_id) WHERE left_at IS NULL;
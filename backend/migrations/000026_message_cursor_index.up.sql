@@ -0,0 +1,2 @@
+-- Serves keyset pagination (conversation_id, id) and unread counts (id > last_read_message_id)
+CREATE INDEX IF NOT EXISTS idx_messages_conversation_visible ON messages(conversation_id, id) WHERE deleted_at IS NULL;
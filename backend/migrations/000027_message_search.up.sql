@@ -0,0 +1,6 @@
+-- Messages mix French and English, so they are indexed without stemming
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+        GENERATED ALWAYS AS (to_tsvector('simple', coalesce(content, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_messages_search_vector ON messages USING GIN (search_vector);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_conversations_context;
+DROP INDEX IF EXISTS idx_conversations_direct_key;
+
+ALTER TABLE conversations
+    DROP CONSTRAINT IF EXISTS chk_conversations_context,
+    DROP COLUMN IF EXISTS context_id,
+    DROP COLUMN IF EXISTS context_type,
+    DROP COLUMN IF EXISTS direct_key;
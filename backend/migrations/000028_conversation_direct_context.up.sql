@@ -0,0 +1,25 @@
+ALTER TABLE conversations
+    ADD COLUMN IF NOT EXISTS direct_key VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS context_type VARCHAR(20) CHECK (context_type IN ('startup', 'opportunity')),
+    ADD COLUMN IF NOT EXISTS context_id BIGINT,
+    ADD CONSTRAINT chk_conversations_context CHECK ((context_type IS NULL) = (context_id IS NULL));
+
+-- Existing one-to-one conversations keep working as the thread of their pair; when
+-- a pair already has several, the oldest one becomes the thread
+UPDATE conversations c
+SET direct_key = pairs.direct_key
+FROM (
+    SELECT DISTINCT ON (direct_key) conversation_id, direct_key
+    FROM (
+        SELECT cp.conversation_id, MIN(cp.user_id) || ':' || MAX(cp.user_id) AS direct_key
+        FROM conversation_participants cp
+        JOIN conversations conv ON conv.id = cp.conversation_id AND NOT conv.is_group
+        GROUP BY cp.conversation_id
+        HAVING COUNT(*) = 2
+    ) keyed
+    ORDER BY direct_key, conversation_id
+) pairs
+WHERE c.id = pairs.conversation_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_conversations_direct_key ON conversations(direct_key);
+CREATE INDEX IF NOT EXISTS idx_conversations_context ON conversations(context_type, context_id);
@@ -0,0 +1,3 @@
+ALTER TABLE conversation_participants DROP COLUMN IF EXISTS muted;
+
+DROP TABLE IF EXISTS user_blocks;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS user_blocks (
+    id BIGSERIAL PRIMARY KEY,
+    blocker_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (blocker_id <> blocked_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_blocks_pair ON user_blocks(blocker_id, blocked_id);
+CREATE INDEX IF NOT EXISTS idx_user_blocks_blocked_id ON user_blocks(blocked_id);
+
+ALTER TABLE conversation_participants
+    ADD COLUMN IF NOT EXISTS muted BOOLEAN NOT NULL DEFAULT FALSE;
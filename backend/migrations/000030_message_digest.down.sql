@@ -0,0 +1,5 @@
+ALTER TABLE conversation_participants DROP COLUMN IF EXISTS digested_message_id;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS message_digest_sent_at,
+    DROP COLUMN IF EXISTS message_digest;
@@ -0,0 +1,12 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS message_digest VARCHAR(20) NOT NULL DEFAULT 'daily' CHECK (message_digest IN ('immediate', 'daily', 'never')),
+    ADD COLUMN IF NOT EXISTS message_digest_sent_at TIMESTAMPTZ;
+
+-- Messages sent before digests existed are not emailed
+ALTER TABLE conversation_participants
+    ADD COLUMN IF NOT EXISTS digested_message_id BIGINT;
+
+UPDATE conversation_participants cp
+SET digested_message_id = c.last_message_id
+FROM conversations c
+WHERE c.id = cp.conversation_id;
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'features.manage';
+
+DROP TABLE IF EXISTS feature_flags;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS feature_flags (
+    name VARCHAR(50) PRIMARY KEY,
+    enabled BOOLEAN NOT NULL,
+    updated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('features.manage', 'Turn features on and off')
+ON CONFLICT (name) DO NOTHING;
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS trg_opportunities_delete_favorites ON opportunities;
+DROP TRIGGER IF EXISTS trg_events_delete_favorites ON events;
+DROP TRIGGER IF EXISTS trg_startups_delete_favorites ON startups;
+DROP FUNCTION IF EXISTS delete_favorites_of_target();
+
+DROP TABLE IF EXISTS favorites;
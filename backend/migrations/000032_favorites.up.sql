@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS favorites (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('startup', 'event', 'opportunity')),
+    target_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_favorites_user_target ON favorites(user_id, target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_favorites_target ON favorites(target_type, target_id);
+
+-- The targets live in different tables, so dangling favorites are removed by triggers
+CREATE OR REPLACE FUNCTION delete_favorites_of_target() RETURNS TRIGGER AS $$
+BEGIN
+    DELETE FROM favorites WHERE target_type = TG_ARGV[0] AND target_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_startups_delete_favorites ON startups;
+CREATE TRIGGER trg_startups_delete_favorites AFTER DELETE ON startups
+    FOR EACH ROW EXECUTE FUNCTION delete_favorites_of_target('startup');
+
+DROP TRIGGER IF EXISTS trg_events_delete_favorites ON events;
+CREATE TRIGGER trg_events_delete_favorites AFTER DELETE ON events
+    FOR EACH ROW EXECUTE FUNCTION delete_favorites_of_target('event');
+
+DROP TRIGGER IF EXISTS trg_opportunities_delete_favorites ON opportunities;
+CREATE TRIGGER trg_opportunities_delete_favorites AFTER DELETE ON opportunities
+    FOR EACH ROW EXECUTE FUNCTION delete_favorites_of_target('opportunity');
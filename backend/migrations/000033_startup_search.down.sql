@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_startups_name_trgm;
+DROP INDEX IF EXISTS idx_startups_search_vector;
+ALTER TABLE startups DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,15 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Startup data mixes French and English, so it is indexed without stemming; names weigh most, then descriptions, then needs
+ALTER TABLE startups
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+        GENERATED ALWAYS AS (
+            setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+            setweight(to_tsvector('simple', coalesce(description, '')), 'B') ||
+            setweight(to_tsvector('simple', coalesce(needs, '')), 'C')
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_startups_search_vector ON startups USING GIN (search_vector);
+
+-- Trigram index backing the typo-tolerant name matching
+CREATE INDEX IF NOT EXISTS idx_startups_name_trgm ON startups USING GIN (name gin_trgm_ops);
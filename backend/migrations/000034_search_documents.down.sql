@@ -0,0 +1,23 @@
+DROP TRIGGER IF EXISTS trg_opportunities_search_documents_delete ON opportunities;
+DROP TRIGGER IF EXISTS trg_opportunities_search_documents ON opportunities;
+DROP TRIGGER IF EXISTS trg_events_search_documents_delete ON events;
+DROP TRIGGER IF EXISTS trg_events_search_documents ON events;
+DROP TRIGGER IF EXISTS trg_news_search_documents_delete ON news;
+DROP TRIGGER IF EXISTS trg_news_search_documents ON news;
+DROP TRIGGER IF EXISTS trg_partners_search_documents_delete ON partners;
+DROP TRIGGER IF EXISTS trg_partners_search_documents ON partners;
+DROP TRIGGER IF EXISTS trg_investors_search_documents_delete ON investors;
+DROP TRIGGER IF EXISTS trg_investors_search_documents ON investors;
+DROP TRIGGER IF EXISTS trg_startups_search_documents_delete ON startups;
+DROP TRIGGER IF EXISTS trg_startups_search_documents ON startups;
+
+DROP FUNCTION IF EXISTS search_documents_opportunities();
+DROP FUNCTION IF EXISTS search_documents_events();
+DROP FUNCTION IF EXISTS search_documents_news();
+DROP FUNCTION IF EXISTS search_documents_partners();
+DROP FUNCTION IF EXISTS search_documents_investors();
+DROP FUNCTION IF EXISTS search_documents_startups();
+DROP FUNCTION IF EXISTS search_documents_delete();
+DROP FUNCTION IF EXISTS search_documents_upsert(TEXT, BIGINT, TEXT, TEXT);
+
+DROP TABLE IF EXISTS search_documents;
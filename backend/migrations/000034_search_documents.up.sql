@@ -0,0 +1,134 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Shared index behind the global search: one document per public entity
+CREATE TABLE IF NOT EXISTS search_documents (
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('startup', 'investor', 'partner', 'news', 'event', 'opportunity')),
+    entity_id BIGINT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', title), 'A') ||
+        setweight(to_tsvector('simple', body), 'B')
+    ) STORED,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (entity_type, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_documents_search_vector ON search_documents USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_search_documents_title_trgm ON search_documents USING GIN (title gin_trgm_ops);
+
+-- The documents are maintained by triggers so that every writer, handlers and sync repos alike, keeps them current
+CREATE OR REPLACE FUNCTION search_documents_upsert(doc_type TEXT, doc_id BIGINT, doc_title TEXT, doc_body TEXT) RETURNS VOID AS $$
+BEGIN
+    INSERT INTO search_documents (entity_type, entity_id, title, body, updated_at)
+    VALUES (doc_type, doc_id, coalesce(doc_title, ''), coalesce(doc_body, ''), NOW())
+    ON CONFLICT (entity_type, entity_id) DO UPDATE
+        SET title = EXCLUDED.title, body = EXCLUDED.body, updated_at = EXCLUDED.updated_at;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION search_documents_delete() RETURNS TRIGGER AS $$
+BEGIN
+    DELETE FROM search_documents WHERE entity_type = TG_ARGV[0] AND entity_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION search_documents_startups() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM search_documents_upsert('startup', NEW.id, NEW.name, concat_ws(' ', NEW.description, NEW.needs, NEW.sector));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION search_documents_investors() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM search_documents_upsert('investor', NEW.id, NEW.name, concat_ws(' ', NEW.description, NEW.investor_type, NEW.investment_focus));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION search_documents_partners() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM search_documents_upsert('partner', NEW.id, NEW.name, concat_ws(' ', NEW.description, NEW.partnership_type));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION search_documents_news() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM search_documents_upsert('news', NEW.id, NEW.title, concat_ws(' ', NEW.description, NEW.category, NEW.location));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION search_documents_events() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM search_documents_upsert('event', NEW.id, NEW.name, concat_ws(' ', NEW.description, NEW.event_type, NEW.location, NEW.target_audience));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION search_documents_opportunities() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM search_documents_upsert('opportunity', NEW.id, NEW.title, concat_ws(' ', NEW.organism, NEW.type, NEW.description, NEW.criteria));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_startups_search_documents ON startups;
+CREATE TRIGGER trg_startups_search_documents AFTER INSERT OR UPDATE OF name, description, needs, sector ON startups
+    FOR EACH ROW EXECUTE FUNCTION search_documents_startups();
+DROP TRIGGER IF EXISTS trg_startups_search_documents_delete ON startups;
+CREATE TRIGGER trg_startups_search_documents_delete AFTER DELETE ON startups
+    FOR EACH ROW EXECUTE FUNCTION search_documents_delete('startup');
+
+DROP TRIGGER IF EXISTS trg_investors_search_documents ON investors;
+CREATE TRIGGER trg_investors_search_documents AFTER INSERT OR UPDATE OF name, description, investor_type, investment_focus ON investors
+    FOR EACH ROW EXECUTE FUNCTION search_documents_investors();
+DROP TRIGGER IF EXISTS trg_investors_search_documents_delete ON investors;
+CREATE TRIGGER trg_investors_search_documents_delete AFTER DELETE ON investors
+    FOR EACH ROW EXECUTE FUNCTION search_documents_delete('investor');
+
+DROP TRIGGER IF EXISTS trg_partners_search_documents ON partners;
+CREATE TRIGGER trg_partners_search_documents AFTER INSERT OR UPDATE OF name, description, partnership_type ON partners
+    FOR EACH ROW EXECUTE FUNCTION search_documents_partners();
+DROP TRIGGER IF EXISTS trg_partners_search_documents_delete ON partners;
+CREATE TRIGGER trg_partners_search_documents_delete AFTER DELETE ON partners
+    FOR EACH ROW EXECUTE FUNCTION search_documents_delete('partner');
+
+DROP TRIGGER IF EXISTS trg_news_search_documents ON news;
+CREATE TRIGGER trg_news_search_documents AFTER INSERT OR UPDATE OF title, description, category, location ON news
+    FOR EACH ROW EXECUTE FUNCTION search_documents_news();
+DROP TRIGGER IF EXISTS trg_news_search_documents_delete ON news;
+CREATE TRIGGER trg_news_search_documents_delete AFTER DELETE ON news
+    FOR EACH ROW EXECUTE FUNCTION search_documents_delete('news');
+
+DROP TRIGGER IF EXISTS trg_events_search_documents ON events;
+CREATE TRIGGER trg_events_search_documents AFTER INSERT OR UPDATE OF name, description, event_type, location, target_audience ON events
+    FOR EACH ROW EXECUTE FUNCTION search_documents_events();
+DROP TRIGGER IF EXISTS trg_events_search_documents_delete ON events;
+CREATE TRIGGER trg_events_search_documents_delete AFTER DELETE ON events
+    FOR EACH ROW EXECUTE FUNCTION search_documents_delete('event');
+
+DROP TRIGGER IF EXISTS trg_opportunities_search_documents ON opportunities;
+CREATE TRIGGER trg_opportunities_search_documents AFTER INSERT OR UPDATE OF title, organism, type, description, criteria ON opportunities
+    FOR EACH ROW EXECUTE FUNCTION search_documents_opportunities();
+DROP TRIGGER IF EXISTS trg_opportunities_search_documents_delete ON opportunities;
+CREATE TRIGGER trg_opportunities_search_documents_delete AFTER DELETE ON opportunities
+    FOR EACH ROW EXECUTE FUNCTION search_documents_delete('opportunity');
+
+-- Index the existing data
+INSERT INTO search_documents (entity_type, entity_id, title, body)
+SELECT 'startup', id, name, concat_ws(' ', description, needs, sector) FROM startups
+UNION ALL
+SELECT 'investor', id, name, concat_ws(' ', description, investor_type, investment_focus) FROM investors
+UNION ALL
+SELECT 'partner', id, name, concat_ws(' ', description, partnership_type) FROM partners
+UNION ALL
+SELECT 'news', id, title, concat_ws(' ', description, category, location) FROM news
+UNION ALL
+SELECT 'event', id, name, concat_ws(' ', description, event_type, location, target_audience) FROM events
+UNION ALL
+SELECT 'opportunity', id, title, concat_ws(' ', organism, type, description, criteria) FROM opportunities
+ON CONFLICT (entity_type, entity_id) DO NOTHING;